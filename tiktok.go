@@ -60,80 +60,43 @@ func main() {
 
 	}
 
-	// initate Slack RTM and get started
+	tiktokmod.SetCronState(CronState)
+
+	// initate Slack and get started on whichever transport is configured
 	api := slack.New(tiktok.Config.SlackToken)
-	rtm := api.NewRTM()
-	go rtm.ManageConnection()
+	transport, err := tiktokmod.NewTransport(tiktokOpts, api)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// BOT Listen Loop
-	for msg := range rtm.IncomingEvents {
-		switch ev := msg.Data.(type) {
-		case *slack.HelloEvent:
-
-		case *slack.ConnectedEvent:
-			tiktok.Config.BotID = ev.Info.User.ID
-			tiktok.Config.BotName = strings.ToUpper(ev.Info.User.Name)
-			tiktok.Config.TeamID = ev.Info.Team.ID
-			tiktok.Config.TeamName = ev.Info.Team.Name
-
-			//update this "C7XVAJVRS " to a channel ID that matches what he joined (how do i grab that from the connect)
-			rtm.SendMessage(rtm.NewOutgoingMessage("Hello! I rebooted, if you care.  :unicorn_face:", "C7XVAJVRS"))
-
-		case *slack.MessageEvent:
-			if tiktok.Config.DEBUG {
-				fmt.Printf("Message: %v\n", ev)
-			}
-
-			// Check stream if someone says my name or is DM'ing me
-			if strings.Contains(ev.Msg.Text, "@"+tiktok.Config.BotID) || string(ev.Msg.Channel[0]) == "D" {
-				// Ignore things that I post so i don't loop myself
-				if ev.Msg.User != tiktok.Config.BotID {
-					// some bot responses are case sensitive due to Trello being case sensitive, so removing the lower case function
-					//   until i think of a better way to handle
-					// saidWhat := strings.ToLower(ev.Msg.Text)
-					saidWhat := ev.Msg.Text
-
-					// BOT Responses
-					tiktokmod.Responder(saidWhat, tiktokOpts, ev, rtm)
-
-					// BOT Actions
-					c, cronjobs, CronState = tiktokmod.BotActions(saidWhat, tiktokOpts, ev, rtm, api, c, cronjobs, CronState)
-
-					// HELP INFO
-					if strings.Contains(saidWhat, "help") || strings.Contains(saidWhat, "what do you do") || strings.Contains(saidWhat, "what can you do") || strings.Contains(saidWhat, "who are you") {
-						rtm.SendMessage(rtm.NewOutgoingMessage("I have DM'd you some help information!", ev.Msg.Channel))
-						tiktokmod.Help(tiktokOpts, ev.Msg.User, api)
-					}
-				}
+	err = transport.Run(func(ev *slack.MessageEvent, out tiktokmod.Messenger) {
+		// Check stream if someone says my name or is DM'ing me
+		if strings.Contains(ev.Msg.Text, "@"+tiktok.Config.BotID) || strings.HasPrefix(ev.Msg.Channel, "D") {
+			// Ignore things that I post so i don't loop myself
+			if ev.Msg.User != tiktok.Config.BotID {
+				// some bot responses are case sensitive due to Trello being case sensitive, so removing the lower case function
+				//   until i think of a better way to handle
+				// saidWhat := strings.ToLower(ev.Msg.Text)
+				saidWhat := ev.Msg.Text
+
+				// BOT Responses
+				tiktokmod.Responder(saidWhat, tiktokOpts, ev, out)
+
+				// BOT Actions
+				c, cronjobs, CronState = tiktokmod.BotActions(saidWhat, tiktokOpts, ev, out, api, c, cronjobs, CronState)
+				tiktokmod.SetCronState(CronState)
 			}
-
-		case *slack.LatencyReport:
-			if tiktok.Config.DEBUG {
-				fmt.Printf("Current latency: %v\n", ev.Value)
-			}
-
-		case *slack.RTMError:
-			if tiktok.Config.DEBUG {
-				fmt.Printf("Error: %s\n", ev.Error())
-			}
-			if tiktok.Config.LogToSlack {
-				tiktokmod.LogToSlack("`ERROR`: RTMError, See Console DEBUG", tiktok, attachments)
-			}
-
-		case *slack.InvalidAuthEvent:
-			if tiktok.Config.DEBUG {
-				fmt.Printf("Invalid credentials")
-			}
-			if tiktok.Config.LogToSlack {
-				tiktokmod.LogToSlack("`ERROR`: Invalid Slack API Credentials", tiktok, attachments)
-			}
-			return
-
-		default:
-
 		}
+	})
+	if err != nil {
+		if tiktok.Config.LogToSlack {
+			tiktokmod.LogToSlack("`ERROR`: Slack transport stopped: "+err.Error(), tiktok, attachments)
+		}
+		fmt.Println(err)
 	}
 	if tiktok.Config.DEBUG {
-		fmt.Println("Dumped out of RTM Loop!")
+		fmt.Println("Dumped out of Slack Listen Loop!")
 	}
 }
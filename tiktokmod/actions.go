@@ -2,7 +2,6 @@ package tiktokmod
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -98,8 +97,8 @@ func SliceExists(tiktok *TikTokConf, slice interface{}, item interface{}) bool {
 	return false
 }
 
-// PointCleanup - module to syncronize points between Plugins and Customfields
-func PointCleanup(opts Config, tiktok *TikTokConf, teamID string) (rtnMessage string) {
+// PointCleanup - module to syncronize points between the team's points source and Customfields
+func PointCleanup(opts Config, tiktok *TikTokConf, trello TrelloAPI, teamID string) (rtnMessage string) {
 	var attachments Attachment
 	var listList []lists
 	var apMessage string
@@ -131,7 +130,7 @@ func PointCleanup(opts Config, tiktok *TikTokConf, teamID string) (rtnMessage st
 				LogToSlack("I'm trolling the `"+listList[l].channelName+"` list cards in the `"+opts.General.TeamName+"` board for Point Changes.", tiktok, attachments)
 			}
 		}
-		rtnMessage, tMessage, err = SyncPoints(teamID, listList[l].channelID, opts, tiktok)
+		rtnMessage, tMessage, err = SyncPoints(teamID, listList[l].channelID, opts, tiktok, trello)
 		if err != nil {
 			return "Errors, returning `from action.go`"
 		}
@@ -143,14 +142,14 @@ func PointCleanup(opts Config, tiktok *TikTokConf, teamID string) (rtnMessage st
 	if apMessage != "" {
 		attachments.Text = apMessage
 		attachments.Color = "#ff0000"
-		Wrangler(tiktok.Config.SlackHook, "<!here> Points have been changed on these cards that are in the *current sprint*.", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> Points have been changed on these cards that are in the *current sprint*.", opts.General.ComplaintChannel, attachments)
 	}
 
 	return rtnMessage
 }
 
 // CleanBackLog - Clean-up BackLog
-func CleanBackLog(opts Config, tiktok *TikTokConf) error {
+func CleanBackLog(opts Config, tiktok *TikTokConf, trello TrelloAPI) error {
 	var attachments Attachment
 	var nmessage string
 	var faceCount int
@@ -163,18 +162,20 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 		LogToSlack("I'm checking the BackLog in the `"+opts.General.TeamName+"` and cleaning up those cards.", tiktok, attachments)
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return err
 	}
 
+	cal := TeamCalendar(tiktok, opts)
+
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.BacklogID {
 			numCards++
 
 			//remove squad labels
-			allSquads, err := GetDBSquads(tiktok, opts.General.BoardID)
+			allSquads, err := tiktok.DB.GetSquads(opts.General.BoardID)
 			if err != nil {
 				errTrap(tiktok, "Failed DB Call to get squad information in trello.go func `SquadPoints`", err)
 				return err
@@ -182,7 +183,7 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 			for _, L := range allSquads {
 				for _, lab := range aTt.Labels {
 					if lab.ID == L.LabelID {
-						err := removeLabel(aTt.ID, L.LabelID, tiktok)
+						err := trello.RemoveLabel(aTt.ID, L.LabelID)
 						if err != nil {
 							errTrap(tiktok, "Error from `removeLabel in `CleanBackLog` in `actions.go`", err)
 						}
@@ -195,7 +196,7 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 			//remove faces
 			if len(aTt.IDMembers) > 0 {
 				for _, h := range aTt.IDMembers {
-					err := RemoveHead(tiktok, aTt.ID, h)
+					err := trello.RemoveHead(aTt.ID, h)
 					if err != nil {
 						errTrap(tiktok, "Error in `RemoveHeads` called from `CleanBackLog` in `actions.go`", err)
 					}
@@ -203,11 +204,11 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 				}
 			}
 
-			//clear custom fields
+			//clear custom fields, unless the points field is where the board keeps its estimates
 			for _, c := range aTt.CustomFieldItems {
-				if c.IDCustomField == opts.General.CfpointsID {
+				if c.IDCustomField == opts.General.CfpointsID && !PointsFromField(opts) {
 					if c.Value.Number != "0" {
-						err = PutCustomField(aTt.ID, opts.General.CfpointsID, tiktok, "number", "0")
+						err = trello.PutCustomField(aTt.ID, opts.General.CfpointsID, "number", "0")
 						if err != nil {
 							errTrap(tiktok, "Error from `PutCustomField` for *CFPOINTSID* in `CleanBackLog` in `actions.go`", err)
 						}
@@ -216,7 +217,7 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 				}
 				if c.IDCustomField == opts.General.CfsprintID {
 					if c.Value.Text != "" {
-						err = PutCustomField(aTt.ID, opts.General.CfsprintID, tiktok, "text", "")
+						err = trello.PutCustomField(aTt.ID, opts.General.CfsprintID, "text", "")
 						if err != nil {
 							errTrap(tiktok, "Error from `PutCustomField` for *CFSPRINTID* in `CleanBackLog` in `actions.go`", err)
 						}
@@ -227,19 +228,13 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 
 			//remove points
 			// AS OF 8/14/2018 the Trello REST API does not support PUT/POST/DELETE methods against Trello Power-Up data.  You can only GET
-			//   This means we can't clear/zero Story Points.
+			//   This means we can't clear/zero Story Points on a "powerup" board.  "customfield" and "title" estimates are left alone on purpose.
 
 			//check card age
-			value, cardListTime := GetTimePutList(opts.General.BacklogID, aTt.ID, opts, tiktok)
+			value, cardListTime := TimePutList(trello, opts.General.BacklogID, aTt.ID)
 
 			if value {
-				format := "2006-01-02 15:04:05"
-				fmtTime := cardListTime.Format("2006-01-02 15:04:05")
-				then, _ := time.Parse(format, fmtTime)
-
-				date := time.Now()
-				diff := date.Sub(then)
-				days := int(diff.Hours() / 24)
+				days := cal.Days(cardListTime, time.Now())
 
 				if days > opts.General.BackLogDays {
 					// Currently just logs to logging that card is old.
@@ -278,20 +273,20 @@ func CleanBackLog(opts Config, tiktok *TikTokConf) error {
 	nmessage = nmessage + "There is a total of " + strconv.Itoa(numCards) + " cards in the backlog currently.\n"
 	attachments.Color = "#00ff00"
 	attachments.Text = nmessage
-	Wrangler(tiktok.Config.SlackHook, "Team, I just troll'd the backlog for clean up. :sweep:", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+	TeamWrangler(tiktok, opts, "Team, I just troll'd the backlog for clean up. :sweep:", opts.General.ComplaintChannel, attachments)
 
 	return nil
 }
 
 // ArchiveBacklog - Archive old cards in the backlog
-func ArchiveBacklog(tiktok *TikTokConf, opts Config) (err error) {
+func ArchiveBacklog(tiktok *TikTokConf, trello TrelloAPI, opts Config) (err error) {
 
 	var message string
 	var attachments Attachment
 	var cardCount int
 	var hushed bool
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return err
@@ -300,6 +295,8 @@ func ArchiveBacklog(tiktok *TikTokConf, opts Config) (err error) {
 	message = ""
 	cardCount = 0
 
+	cal := TeamCalendar(tiktok, opts)
+
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.BacklogID {
 
@@ -317,30 +314,15 @@ func ArchiveBacklog(tiktok *TikTokConf, opts Config) (err error) {
 					errTrap(tiktok, "Skipping card <"+aTt.URL+"|"+aTt.Name+"> due to error retrieve creation date in `ArchiveBackLog` `actions.go`", err)
 				}
 
-				format := "2006-01-02 15:04:05"
-				fmtTime := createDate.Format("2006-01-02 15:04:05")
-				then, _ := time.Parse(format, fmtTime)
-
-				date := time.Now()
-				diff := date.Sub(then)
-				days := int(diff.Hours() / 24)
+				days := cal.Days(createDate, time.Now())
 
 				if days > opts.General.BackLogDays {
 					//archive it
-					message = message + "<" + aTt.URL + "|" + aTt.Name + "> is " + strconv.Itoa(days) + " days old.\n"
-
-					url := "https://api.trello.com/1/cards/" + aTt.ID + "?closed=true&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
-
-					req, err := http.NewRequest("PUT", url, nil)
-					if err != nil {
-						errTrap(tiktok, "Error archiving card "+aTt.URL+" during http.NewRequest in `ArchiveBacklog` `actions.go`", err)
-					}
-					client := &http.Client{}
-					resp, err := client.Do(req)
-					if err != nil {
-						errTrap(tiktok, "Error archiving card "+aTt.URL+" during client.Do API PUT in `ArchiveBacklog` `actions.go`", err)
+					if err := trello.ArchiveCard(aTt.ID); err != nil {
+						errTrap(tiktok, "Error archiving card "+aTt.URL+" in `ArchiveBacklog` `actions.go`", err)
+						continue
 					}
-					defer resp.Body.Close()
+					message = message + "<" + aTt.URL + "|" + aTt.Name + "> is " + strconv.Itoa(days) + " days old.\n"
 
 					cardCount = cardCount + 1
 
@@ -357,7 +339,7 @@ func ArchiveBacklog(tiktok *TikTokConf, opts Config) (err error) {
 
 	attachments.Color = "#00ff00"
 	attachments.Text = message
-	Wrangler(tiktok.Config.SlackHook, "I archived "+strconv.Itoa(cardCount)+" card(s) in the `BackLog` that were greater then "+strconv.Itoa(opts.General.BackLogDays)+" old.  Here's the list:\n", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+	TeamWrangler(tiktok, opts, "I archived "+strconv.Itoa(cardCount)+" card(s) in the `BackLog` that were greater then "+strconv.Itoa(opts.General.BackLogDays)+" old.  Here's the list:\n", opts.General.ComplaintChannel, attachments)
 
 	if tiktok.Config.LogToSlack {
 		attachments.Color = ""
@@ -369,7 +351,7 @@ func ArchiveBacklog(tiktok *TikTokConf, opts Config) (err error) {
 }
 
 // CleanDone - Clean Done column of old cards
-func CleanDone(opts Config, tiktok *TikTokConf) (string, error) {
+func CleanDone(opts Config, tiktok *TikTokConf, trello TrelloAPI) (string, error) {
 
 	var attachments Attachment
 	var cardCount int
@@ -379,7 +361,7 @@ func CleanDone(opts Config, tiktok *TikTokConf) (string, error) {
 		LogToSlack("I'm searching the Done List in the `"+opts.General.TeamName+"` board for cards that are older than "+strconv.Itoa(opts.General.ArchiveDoneDays)+" days and archiving them. ", tiktok, attachments)
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return "Trello error in RetrieveAll `actions.go` for `" + opts.General.TeamName + "` board", err
@@ -387,35 +369,22 @@ func CleanDone(opts Config, tiktok *TikTokConf) (string, error) {
 
 	cardCount = 0
 
+	cal := TeamCalendar(tiktok, opts)
+
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.Done {
-			value, cardListTime := GetTimePutList(opts.General.Done, aTt.ID, opts, tiktok)
+			value, cardListTime := TimePutList(trello, opts.General.Done, aTt.ID)
 
 			if value {
-				format := "2006-01-02 15:04:05"
-				fmtTime := cardListTime.Format("2006-01-02 15:04:05")
-				then, _ := time.Parse(format, fmtTime)
-
-				date := time.Now()
-				diff := date.Sub(then)
-				days := int(diff.Hours() / 24)
+				days := cal.Days(cardListTime, time.Now())
 
 				if days > opts.General.ArchiveDoneDays {
 
-					url := "https://api.trello.com/1/cards/" + aTt.ID + "?closed=true&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
-
-					req, err := http.NewRequest("PUT", url, nil)
-					if err != nil {
-						errTrap(tiktok, "", err)
-						return "NewRequest Put", err
-					}
-					client := &http.Client{}
-					resp, err := client.Do(req)
+					err := trello.ArchiveCard(aTt.ID)
 					if err != nil {
-						errTrap(tiktok, "Error client.DO API Post `actions.go`", err)
-						return "client.Do", err
+						errTrap(tiktok, "Error archiving card in `CleanDone` in `actions.go`", err)
+						return "ArchiveCard", err
 					}
-					defer resp.Body.Close()
 
 					cardCount = cardCount + 1
 
@@ -450,7 +419,7 @@ func CleanDone(opts Config, tiktok *TikTokConf) (string, error) {
 	}
 	attachments.Color = ""
 	attachments.Text = ""
-	Wrangler(tiktok.Config.SlackHook, message, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+	TeamWrangler(tiktok, opts, message, opts.General.ComplaintChannel, attachments)
 
 	return "", nil
 }
@@ -501,7 +470,7 @@ func Permissions(tiktok *TikTokConf, slackID string, action string, api *slack.C
 }
 
 // PRSummary - Summarize PR Column
-func PRSummary(opts Config, tiktok *TikTokConf) (output string, err error) {
+func PRSummary(opts Config, tiktok *TikTokConf, trello TrelloAPI) (output string, err error) {
 
 	var attachments Attachment
 	var message string
@@ -512,7 +481,7 @@ func PRSummary(opts Config, tiktok *TikTokConf) (output string, err error) {
 		LogToSlack("Checking for PR cards to return a list of active ones on `"+opts.General.TeamName+"` board", tiktok, attachments)
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in PRSummary in `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return "Trello error in PRSummary in `trello.go` for `" + opts.General.TeamName + "` board", err
@@ -520,7 +489,7 @@ func PRSummary(opts Config, tiktok *TikTokConf) (output string, err error) {
 
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.ReadyForReview {
-			message = message + "<https://trello.com/c/" + aTt.ID + "|" + aTt.Name + ">\n"
+			message = message + "<https://trello.com/c/" + aTt.ID + "|" + aTt.Name + ">" + ChecklistNote(aTt) + "\n"
 		}
 	}
 
@@ -528,7 +497,7 @@ func PRSummary(opts Config, tiktok *TikTokConf) (output string, err error) {
 		hmessage := "Reminder, here are the current PR's for discussion at Stand-up today:\n"
 		attachments.Color = "#006400"
 		attachments.Text = message
-		Wrangler(tiktok.Config.SlackHook, hmessage, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, hmessage, opts.General.ComplaintChannel, attachments)
 		return "", nil
 	}
 
@@ -536,26 +505,22 @@ func PRSummary(opts Config, tiktok *TikTokConf) (output string, err error) {
 }
 
 // CountCards - function to count # of cards per theme in pre-sprint columns for reporting
-func CountCards(opts Config, tiktok *TikTokConf, teamID string) (allThemes Themes, err error) {
-
-	sOpts, err := GetDBSprint(tiktok, teamID)
-	if err != nil {
-		return allThemes, err
-	}
+func CountCards(opts Config, tiktok *TikTokConf, trello TrelloAPI, teamID string) (allThemes Themes, err error) {
 
-	// Load label information from board
-	allThemes, err = GetLabel(tiktok, opts.General.BoardID)
+	sOpts, err := tiktok.DB.GetSprint(teamID)
 	if err != nil {
-		errTrap(tiktok, "Failed trello call to get label information in actions.go func `CountCards`", err)
 		return allThemes, err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error from RetrieveAll in `CountCards` `trello.go` for `"+opts.General.TeamName+"` board", err)
 		return allThemes, err
 	}
 
+	// label information comes back with the board
+	allThemes = allTheThings.Labels
+
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.Upcoming || aTt.IDList == opts.General.Scoped {
 			for _, labels := range aTt.Labels {
@@ -570,7 +535,7 @@ func CountCards(opts Config, tiktok *TikTokConf, teamID string) (allThemes Theme
 	}
 
 	// write to db and output
-	err = PutThemeCount(tiktok, allThemes, sOpts, teamID)
+	err = tiktok.DB.PutThemeCount(allThemes, sOpts, teamID)
 	if err != nil {
 		return allThemes, err
 	}
@@ -578,21 +543,16 @@ func CountCards(opts Config, tiktok *TikTokConf, teamID string) (allThemes Theme
 	return allThemes, nil
 }
 
-// SyncPoints - sync points between Agile power-up and custom field in the provided column
-func SyncPoints(teamID string, listID string, opts Config, tiktok *TikTokConf) (messasge string, apMessage string, err error) {
-
-	var attachments Attachment
-	var existPoints string
-	var foundField bool
-	var sprintField bool
+// SyncPoints - sync points between the team's points source and custom field in the provided column
+func SyncPoints(teamID string, listID string, opts Config, tiktok *TikTokConf, trello TrelloAPI) (messasge string, apMessage string, err error) {
 
-	sOpts, err := GetDBSprint(tiktok, teamID)
+	sOpts, err := tiktok.DB.GetSprint(teamID)
 	if err != nil {
 		errTrap(tiktok, "Failed DB query, bailing out of syncpoints function in `trello.go`", err)
 		return "", "", err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "all")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "all")
 
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll `trello.go` for `"+opts.General.TeamName+"` board", err)
@@ -601,117 +561,101 @@ func SyncPoints(teamID string, listID string, opts Config, tiktok *TikTokConf) (
 
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == listID {
-			var points int
-
-			pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-
-			foundField = false
-			sprintField = false
-
-			for _, p := range pluginCard {
-
-				if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-					var plugins PointsHistory
-
-					pluginJSON := []byte(p.Value)
-					json.Unmarshal(pluginJSON, &plugins)
-					points = plugins.Points
-				}
+			apMessage = apMessage + SyncCardPoints(tiktok, trello, opts, sOpts, aTt)
+		}
+	}
+	return "", apMessage, nil
+}
 
-			}
+// SyncCardPoints - copy a card's estimated points into the burndown custom field and set its sprint name if it's in the
+// sprint.  Returns an alert line if the points changed on a card already in the sprint.  Boards estimating in the
+// burndown field itself only get the sprint name synced
+func SyncCardPoints(tiktok *TikTokConf, trello TrelloAPI, opts Config, sOpts SprintData, aTt BoardCard) (apMessage string) {
+	var attachments Attachment
+	var existPoints string
+	var foundField bool
+	var sprintField bool
 
-			for _, cusval := range aTt.CustomFieldItems {
-				// get points in burndown custom field
-				if cusval.IDCustomField == opts.General.CfpointsID {
-					existPoints = cusval.Value.Number
-					foundField = true
-				}
+	points := CardPoints(tiktok, opts, aTt)
 
-				// sync sprintname to custom field in specific lists
-				if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
-					if cusval.IDCustomField == opts.General.CfsprintID {
-						sprintField = true
-						if cusval.Value.Text == "" || cusval.Value.Text != sOpts.SprintName {
-							// Put custom field
-							err := PutCustomField(aTt.ID, opts.General.CfsprintID, tiktok, "text", sOpts.SprintName)
-							if err != nil {
-								errTrap(tiktok, "Error in PutCustomField in trello.go, updating sprintname field", err)
-							}
-						}
-					}
-				}
-			}
+	for _, cusval := range aTt.CustomFieldItems {
+		// get points in burndown custom field
+		if cusval.IDCustomField == opts.General.CfpointsID {
+			existPoints = cusval.Value.Number
+			foundField = true
+		}
 
-			// handle cards that have never had customfield SprintName created
-			if !sprintField {
-				if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
-					err := PutCustomField(aTt.ID, opts.General.CfsprintID, tiktok, "text", sOpts.SprintName)
+		// sync sprintname to custom field in specific lists
+		if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
+			if cusval.IDCustomField == opts.General.CfsprintID {
+				sprintField = true
+				if cusval.Value.Text == "" || cusval.Value.Text != sOpts.SprintName {
+					// Put custom field
+					err := trello.PutCustomField(aTt.ID, opts.General.CfsprintID, "text", sOpts.SprintName)
 					if err != nil {
 						errTrap(tiktok, "Error in PutCustomField in trello.go, updating sprintname field", err)
 					}
 				}
 			}
+		}
+	}
 
-			// Check specific lists to see if points have been changed and add to alert if they have
-			if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
-				if existPoints != strconv.Itoa(points) {
-					if existPoints != "" && foundField && existPoints != "0" {
-						apMessage = apMessage + "Points on card <https://trello.com/c/" + aTt.ID + "|" + aTt.Name + "> have changed from " + existPoints + " to " + strconv.Itoa(points) + "\n"
-						if tiktok.Config.LogToSlack {
-							LogToSlack("Points on card <https://trello.com/c/"+aTt.ID+"|"+aTt.Name+"> have changed from "+existPoints+" to "+strconv.Itoa(points), tiktok, attachments)
-						}
-					}
-				}
+	// handle cards that have never had customfield SprintName created
+	if !sprintField {
+		if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
+			err := trello.PutCustomField(aTt.ID, opts.General.CfsprintID, "text", sOpts.SprintName)
+			if err != nil {
+				errTrap(tiktok, "Error in PutCustomField in trello.go, updating sprintname field", err)
 			}
+		}
+	}
 
-			// Sync points fields
-			if existPoints != strconv.Itoa(points) {
-				err = PutCustomField(aTt.ID, opts.General.CfpointsID, tiktok, "number", strconv.Itoa(points))
-				if err != nil {
-					errTrap(tiktok, "Error PutCustomField for Sync Fields `actions.go`", err)
+	if PointsFromField(opts) {
+		return apMessage
+	}
+
+	// Check specific lists to see if points have been changed and add to alert if they have
+	if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
+		if existPoints != strconv.Itoa(points) {
+			if existPoints != "" && foundField && existPoints != "0" {
+				apMessage = apMessage + "Points on card <https://trello.com/c/" + aTt.ID + "|" + aTt.Name + "> have changed from " + existPoints + " to " + strconv.Itoa(points) + "\n"
+				if tiktok.Config.LogToSlack {
+					LogToSlack("Points on card <https://trello.com/c/"+aTt.ID+"|"+aTt.Name+"> have changed from "+existPoints+" to "+strconv.Itoa(points), tiktok, attachments)
 				}
 			}
 		}
 	}
-	return "", apMessage, nil
+
+	// Sync points fields
+	if existPoints != strconv.Itoa(points) {
+		err := trello.PutCustomField(aTt.ID, opts.General.CfpointsID, "number", strconv.Itoa(points))
+		if err != nil {
+			errTrap(tiktok, "Error PutCustomField for Sync Fields `actions.go`", err)
+		}
+	}
+
+	return apMessage
 }
 
 // ThemePoints - retrieve all the theme points in a given trello colum (list)
-func ThemePoints(opts Config, tiktok *TikTokConf, columnID string) (allThemes Themes, err error) {
+func ThemePoints(opts Config, tiktok *TikTokConf, trello TrelloAPI, columnID string) (allThemes Themes, err error) {
 
 	var points int
 
-	// Load label information from board
-	allThemes, err = GetLabel(tiktok, opts.General.BoardID)
-	if err != nil {
-		errTrap(tiktok, "Failed trello call to get label information in trello.go func `ThemePoints`", err)
-
-		return allThemes, err
-	}
-
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll function `ThemePoints` in `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return allThemes, err
 	}
 
+	// label information comes back with the board
+	allThemes = allTheThings.Labels
+
 	for _, aTt := range allTheThings.Cards {
 		if columnID == aTt.IDList {
 
-			// get power-up for story points
-			pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-
-			for _, p := range pluginCard {
-
-				if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-
-					var plugins PointsHistory
-
-					pluginJSON := []byte(p.Value)
-					json.Unmarshal(pluginJSON, &plugins)
-					points = plugins.Points
-				}
-			}
+			// get story points
+			points = CardPoints(tiktok, opts, aTt)
 
 			for _, labels := range aTt.Labels {
 				for s, label := range allThemes {
@@ -728,7 +672,7 @@ func ThemePoints(opts Config, tiktok *TikTokConf, columnID string) (allThemes Th
 }
 
 // SquadPoints - retrieve all the squad points on a board
-func SquadPoints(columnID string, opts Config, tiktok *TikTokConf) (allSquads Squads, nonPoints int, err error) {
+func SquadPoints(columnID string, opts Config, tiktok *TikTokConf, trello TrelloAPI) (allSquads Squads, nonPoints int, err error) {
 
 	var points int
 	var checker bool
@@ -736,13 +680,13 @@ func SquadPoints(columnID string, opts Config, tiktok *TikTokConf) (allSquads Sq
 	nonPoints = 0
 
 	// Load Squad Information
-	allSquads, err = GetDBSquads(tiktok, opts.General.BoardID)
+	allSquads, err = tiktok.DB.GetSquads(opts.General.BoardID)
 	if err != nil {
 		errTrap(tiktok, "Failed DB Call to get squad information in `actions.go` func `SquadPoints`", err)
 		return allSquads, nonPoints, err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll function `SquadPoints` in `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return allSquads, nonPoints, err
@@ -752,20 +696,8 @@ func SquadPoints(columnID string, opts Config, tiktok *TikTokConf) (allSquads Sq
 		if !aTt.Closed {
 			if aTt.IDList == columnID {
 
-				// get power-up for story points
-				pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-
-				for _, p := range pluginCard {
-
-					if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-
-						var plugins PointsHistory
-
-						pluginJSON := []byte(p.Value)
-						json.Unmarshal(pluginJSON, &plugins)
-						points = plugins.Points
-					}
-				}
+				// get story points
+				points = CardPoints(tiktok, opts, aTt)
 
 				checker = false
 
@@ -792,14 +724,14 @@ func SquadPoints(columnID string, opts Config, tiktok *TikTokConf) (allSquads Sq
 }
 
 // EpicLink - Verify feature cards are linked to Epics
-func EpicLink(tiktok *TikTokConf, opts Config) {
+func EpicLink(tiktok *TikTokConf, trello TrelloAPI, opts Config) {
 	var attachments Attachment
 	var featureCard bool
 	var linkedCard bool
 	var amessage string
 	var hush bool
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in EpicLink `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return
@@ -829,7 +761,7 @@ func EpicLink(tiktok *TikTokConf, opts Config) {
 
 					if featureCard {
 						// check cards for any attachment back to Epic BoardID
-						cardAttachment, err := GetAttachments(tiktok, aTt.ID)
+						cardAttachment, err := trello.GetAttachments(aTt.ID)
 						if err != nil {
 							errTrap(tiktok, "Trello error in EpicLink `actions.go` for cardID `"+aTt.ID+"` board", err)
 
@@ -857,16 +789,16 @@ func EpicLink(tiktok *TikTokConf, opts Config) {
 	if amessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = amessage
-		Wrangler(tiktok.Config.SlackHook, "The following `Feature` cards do not have Epic links!", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "The following `Feature` cards do not have Epic links!", opts.General.ComplaintChannel, attachments)
 	}
 
 	return
 }
 
 // CheckThemes - Check that cards in a specific list have Theme Labels, returns formatted output
-func CheckThemes(tiktok *TikTokConf, opts Config, listID string) (amessage string, err error) {
+func CheckThemes(tiktok *TikTokConf, trello TrelloAPI, opts Config, listID string) (amessage string, err error) {
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in CheckThemes `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return "", err
@@ -887,7 +819,7 @@ func CheckThemes(tiktok *TikTokConf, opts Config, listID string) (amessage strin
 }
 
 // CardPlay - Pull card timing data and dump to CSV
-func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID string, csv bool) {
+func CardPlay(tiktok *TikTokConf, trello TrelloAPI, opts Config, channelResponse string, teamID string, csv bool) {
 	var message string
 	var wdays string
 	var prdays string
@@ -896,11 +828,11 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 	var points int
 	var WorkingDays int
 	var attachments Attachment
-	var diff time.Duration
 	var today time.Time
 	var allCardData CardReportData
 
 	format := "2006-01-02 15:04:05"
+	cal := TeamCalendar(tiktok, opts)
 
 	if csv {
 		Wrangler(tiktok.Config.SlackHook, "Running card movement routine on `"+teamID+"`, this may take some time", channelResponse, tiktok.Config.SlackEmoji, attachments)
@@ -915,13 +847,13 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 	m["fields"] = "name"
 	m["customFieldItems"] = "true"
 
-	sOpts, err := GetDBSprint(tiktok, teamID)
+	sOpts, err := tiktok.DB.GetSprint(teamID)
 	if err != nil {
 		errTrap(tiktok, "DB Error on `GetDBSprint` in `actions.go` for `CardPlay` func", err)
 		return
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll `cardplay.go` for `"+opts.General.TeamName+"` board", err)
 		return
@@ -933,7 +865,7 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 		if tiktok.Config.LogToSlack {
 			LogToSlack("Truncating tiktok_cardtracker to prepare for new data", tiktok, attachments)
 		}
-		err := zeroCardDataDB(tiktok)
+		err := tiktok.DB.ZeroCardData()
 		if err != nil {
 			return
 		}
@@ -946,29 +878,17 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 					if cusval.IDCustomField == opts.General.CfsprintID {
 						if cusval.Value.Text == sOpts.SprintName {
 
-							powerUp, _ := GetPowerUpField(aTt.ID, tiktok)
-							for p := range powerUp {
-
-								var plugins PointsHistory
-
-								pluginJSON := []byte(powerUp[p].Value)
-								json.Unmarshal(pluginJSON, &plugins)
-								points = plugins.Points
-							}
+							points = CardPoints(tiktok, opts, aTt)
 
 							header = ""
 							for _, head := range aTt.IDMembers {
-								fullname, _, _ := GetMemberInfo(head, tiktok)
+								fullname, _, _ := BoardMember(trello, allTheThings, head)
 								header = header + fullname + "|"
 							}
 
 							// Get Date for each list
-							tz, err := time.LoadLocation("America/Los_Angeles")
-							if err != nil {
-								errTrap(tiktok, "TZ Error", err)
-								return
-							}
-							_, cardListTime := GetTimePutList(opts.General.Working, aTt.ID, opts, tiktok)
+							tz := cal.Location
+							_, cardListTime := TimePutList(trello, opts.General.Working, aTt.ID)
 
 							cardTimeW := cardListTime.In(tz)
 							workingTime := cardTimeW.Format("2006-01-02 15:04:05")
@@ -977,7 +897,7 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 								cardTimeW = time.Date(2000, 01, 01, 00, 00, 0, 0, time.UTC)
 							}
 
-							_, cardListTime = GetTimePutList(opts.General.ReadyForReview, aTt.ID, opts, tiktok)
+							_, cardListTime = TimePutList(trello, opts.General.ReadyForReview, aTt.ID)
 							cardTimePR := cardListTime.In(tz)
 							PRTime := cardTimePR.Format("2006-01-02 15:04:05")
 							if strings.Contains(PRTime, "0000-12-31 ") {
@@ -985,7 +905,7 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 								cardTimePR = time.Date(2000, 01, 01, 00, 00, 0, 0, time.UTC)
 
 							}
-							_, cardListTime = GetTimePutList(opts.General.Done, aTt.ID, opts, tiktok)
+							_, cardListTime = TimePutList(trello, opts.General.Done, aTt.ID)
 							cardTimeD := cardListTime.In(tz)
 							DoneTime := cardTimeD.Format("2006-01-02 15:04:05")
 							if strings.Contains(DoneTime, "0000-12-31 ") {
@@ -997,34 +917,35 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 							// Calc days in lists
 							today = time.Now()
 
-							then, _ := time.Parse(format, workingTime)
-							if PRTime == "" {
-								diff = today.Sub(then)
-							} else {
-								diff = cardTimePR.Sub(then)
-							}
-							WorkingDays = int(diff.Hours() / 24)
-							if WorkingDays > 30 {
-								wdays = ""
-							} else {
-								wdays = strconv.Itoa(WorkingDays)
-							}
-
-							then, _ = time.Parse(format, PRTime)
-							if DoneTime == "" {
-								diff = today.Sub(then)
-							} else {
-								diff = cardTimeD.Sub(then)
+							// cards that never hit a list get no days rather than walking the calendar back to year zero
+							wdays = ""
+							if workingTime != "" {
+								then, _ := time.ParseInLocation(format, workingTime, tz)
+								if PRTime == "" {
+									WorkingDays = cal.Days(then, today)
+								} else {
+									WorkingDays = cal.Days(then, cardTimePR)
+								}
+								if WorkingDays <= 30 {
+									wdays = strconv.Itoa(WorkingDays)
+								}
 							}
-							UATDays := int(diff.Hours() / 24)
 
-							if UATDays > 30 {
-								prdays = ""
-							} else {
-								prdays = strconv.Itoa(UATDays)
+							prdays = ""
+							if PRTime != "" {
+								then, _ := time.ParseInLocation(format, PRTime, tz)
+								var UATDays int
+								if DoneTime == "" {
+									UATDays = cal.Days(then, today)
+								} else {
+									UATDays = cal.Days(then, cardTimeD)
+								}
+								if UATDays <= 30 {
+									prdays = strconv.Itoa(UATDays)
+								}
 							}
 
-							list, _ := GetLists(tiktok, opts.General.BoardID)
+							list, _ := trello.GetLists(opts.General.BoardID)
 							for _, listName := range list {
 								if listName.ID == aTt.IDList {
 									realName = listName.Name
@@ -1046,7 +967,7 @@ func CardPlay(tiktok *TikTokConf, opts Config, channelResponse string, teamID st
 								allCardData.StartedInPR = cardTimePR
 								allCardData.StartedInWorking = cardTimeW
 
-								err = PutCardData(tiktok, allCardData, teamID)
+								err = tiktok.DB.PutCardData(allCardData, teamID)
 								if err != nil {
 									errTrap(tiktok, "PutCardData error in `Cardplay` in `actions.go`", err)
 									return
@@ -1136,7 +1057,7 @@ func GetColumn(opts Config, someString string) (columnID string, colName string)
 }
 
 // RecordChapters - Record Chapter card info to SQL DB per specified column/list
-func RecordChapters(tiktok *TikTokConf, teamID string, listName string) error {
+func RecordChapters(tiktok *TikTokConf, trello TrelloAPI, teamID string, listName string) error {
 	var columnID string
 	var colName string
 
@@ -1148,42 +1069,44 @@ func RecordChapters(tiktok *TikTokConf, teamID string, listName string) error {
 
 	columnID, colName = GetColumn(opts, listName)
 
-	allChapters, _, err := ChapterCount(tiktok, opts, columnID)
+	allChapters, _, err := ChapterCount(tiktok, trello, opts, columnID)
 	if err != nil {
 		return err
 	}
 
 	for _, chapter := range allChapters {
-		_ = RecordChapterCount(tiktok, chapter.ChapterName, colName, chapter.ChapterCount, teamID)
+		_ = tiktok.DB.RecordChapterCount(chapter.ChapterName, colName, chapter.ChapterCount, teamID)
 	}
 
 	return nil
 }
 
 //RetroCheck - Check a specified Retro board for un-finished action cards
-func RetroCheck(tiktok *TikTokConf, opts Config, boardID string) (err error) {
+func RetroCheck(tiktok *TikTokConf, trello TrelloAPI, opts Config, boardID string) (err error) {
 	var attachments Attachment
 	var listID string
 	var testPayload BotDMPayload
 
-	users, err := GetDBUsers(tiktok)
+	users, err := tiktok.DB.GetUsers()
 	if err != nil {
 		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `RetroCheck` in `actions.go`", err)
 		return
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, boardID, "visible")
+	allTheThings, err := trello.RetrieveAll(boardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetroCheck in `trello.go` for `"+allTheThings.Name+"` ("+boardID+") retro board", err)
 		return
 	}
 
+	cal := TeamCalendar(tiktok, opts)
+
 	if tiktok.Config.LogToSlack {
 		LogToSlack("Scanning Retro Board `"+allTheThings.Name+"` for open action items.", tiktok, attachments)
 	}
 
 	// Get Actions column ListID from its name
-	listData, err := GetLists(tiktok, boardID)
+	listData, err := trello.GetLists(boardID)
 	if err != nil {
 		return err
 	}
@@ -1202,18 +1125,12 @@ func RetroCheck(tiktok *TikTokConf, opts Config, boardID string) (err error) {
 			if aTt.IDList == listID {
 				if !aTt.Closed {
 					// check date of last activity
-					format := "2006-01-02 15:04:05"
-					fmtTime := aTt.DateLastActivity.Format("2006-01-02 15:04:05")
-					then, _ := time.Parse(format, fmtTime)
-
-					date := time.Now()
-					diff := date.Sub(then)
-					days := int(diff.Hours() / 24)
+					days := cal.Days(aTt.DateLastActivity, time.Now())
 
 					if days >= opts.General.RetroActionDays {
 						if len(aTt.IDMembers) > 0 {
 							for _, tu := range aTt.IDMembers {
-								_, _, userName := GetMemberInfo(tu, tiktok)
+								_, _, userName := BoardMember(trello, allTheThings, tu)
 								for _, u := range users {
 									if userName == u.Trello {
 										if tiktok.Config.LogToSlack {
@@ -1241,12 +1158,12 @@ func RetroCheck(tiktok *TikTokConf, opts Config, boardID string) (err error) {
 }
 
 // CheckActionCards - Loop through retro boards and verify all retro cards are checked for in-action
-func CheckActionCards(tiktok *TikTokConf, opts Config, teamID string) {
+func CheckActionCards(tiktok *TikTokConf, trello TrelloAPI, opts Config, teamID string) {
 
 	var retroAll []RetroStruct
 
 	// get sprint retros
-	retroStruct, err := GetRetroID(tiktok, teamID)
+	retroStruct, err := tiktok.DB.GetRetroIDs(teamID)
 	if err != nil {
 		return
 	}
@@ -1257,7 +1174,7 @@ func CheckActionCards(tiktok *TikTokConf, opts Config, teamID string) {
 	retroAll = append(retroStruct, retroAdds...)
 
 	for _, r := range retroAll {
-		err := RetroCheck(tiktok, opts, r.RetroID)
+		err := RetroCheck(tiktok, trello, opts, r.RetroID)
 		if err != nil {
 			return
 		}
@@ -1265,13 +1182,13 @@ func CheckActionCards(tiktok *TikTokConf, opts Config, teamID string) {
 }
 
 //TemplateCard - Check for template cards and move them to top of backlog
-func TemplateCard(tiktok *TikTokConf, opts Config) {
+func TemplateCard(tiktok *TikTokConf, trello TrelloAPI, opts Config) {
 
 	var attachments Attachment
 
 	LogToSlack("Scanning board "+opts.General.TeamName+" for template cards to ensure they are in the right spot.", tiktok, attachments)
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in TemplateCard `actions.go` for `"+opts.General.TeamName+"` board", err)
 		return
@@ -1284,7 +1201,7 @@ func TemplateCard(tiktok *TikTokConf, opts Config) {
 				if l.ID == opts.General.TemplateLabelID {
 
 					if aTt.IDList != opts.General.BacklogID {
-						err := MoveCardList(tiktok, aTt.ID, opts.General.BacklogID)
+						err := trello.MoveCardList(aTt.ID, opts.General.BacklogID)
 						if err != nil {
 							return
 						}
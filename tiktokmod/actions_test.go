@@ -0,0 +1,188 @@
+package tiktokmod
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+// moveTo - put a fixture card in a list as if it was moved there at when
+func (tt *testTeam) moveTo(t *testing.T, cardID string, listID string, when time.Time) {
+	t.Helper()
+
+	if err := tt.trello.MoveCardList(cardID, listID); err != nil {
+		t.Fatal(err)
+	}
+	tt.trello.Fixture.History[cardID][0].Date = when
+	tt.trello.Calls = nil
+}
+
+func TestCleanBackLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, tt *testTeam)
+		wantCalls []string
+		noCalls   []string
+		wantSlack []string
+		wantLog   []string
+	}{
+		{
+			name: "nothing in the backlog",
+			wantSlack: []string{
+				"I didn't find any faces on cards to remove though!",
+				"There is a total of 0 cards in the backlog currently.",
+			},
+			noCalls: []string{"RemoveHead", "RemoveLabel", "PutCustomField"},
+		},
+		{
+			name: "faces and sprint field cleared",
+			setup: func(t *testing.T, tt *testTeam) {
+				tt.moveTo(t, rollCard, tt.opts.General.BacklogID, time.Now())
+			},
+			wantCalls: []string{
+				"RemoveHead " + rollCard + " 5c8a0e1f2b3c4d5e6f70f001",
+				"PutCustomField " + rollCard + " 5c8a0e1f2b3c4d5e6f70c001 text ",
+			},
+			noCalls: []string{"RemoveLabel"},
+			wantSlack: []string{
+				"I removed 1 faces of off cards.",
+				"Cleaned up 1 custom card fields.",
+				"I did not find any cards older then 180 days old",
+			},
+		},
+		{
+			name: "squad labels removed",
+			setup: func(t *testing.T, tt *testTeam) {
+				tt.db.on("FROM tiktok_squads", []string{"id", "boardid", "squadname", "labelid"},
+					[]driver.Value{int64(1), tt.opts.General.BoardID, "Features", "5c8a0e1f2b3c4d5e6f70b002"})
+				tt.moveTo(t, nextCard, tt.opts.General.BacklogID, time.Now())
+			},
+			wantCalls: []string{"RemoveLabel " + nextCard + " 5c8a0e1f2b3c4d5e6f70b002"},
+			noCalls:   []string{"RemoveHead"},
+			wantSlack: []string{"I removed 1 squad labels from cards."},
+		},
+		{
+			name: "ancient card logged",
+			setup: func(t *testing.T, tt *testTeam) {
+				tt.moveTo(t, nextCard, tt.opts.General.BacklogID, time.Now().AddDate(-2, 0, 0))
+			},
+			wantSlack: []string{"I found 1 ancient old cards and logged them."},
+			wantLog:   []string{"Card in BackLog is older then 180 days old"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.tiktok.Config.LogChannel = "#log"
+			if tc.setup != nil {
+				tc.setup(t, tt)
+			}
+
+			if err := CleanBackLog(tt.opts, tt.tiktok, tt.trello); err != nil {
+				t.Fatal(err)
+			}
+
+			calls := strings.Join(tt.trello.Calls, "\n")
+			for _, c := range tc.wantCalls {
+				if !strings.Contains(calls, c) {
+					t.Errorf("trello calls %q don't contain %q", calls, c)
+				}
+			}
+			for _, c := range tc.noCalls {
+				if len(tt.calls(c)) != 0 {
+					t.Errorf("unexpected %s calls %v", c, tt.calls(c))
+				}
+			}
+
+			sent := strings.Join(tt.slack.sent("#complaints"), "\n")
+			for _, s := range tc.wantSlack {
+				if !strings.Contains(sent, s) {
+					t.Errorf("#complaints messages %q don't contain %q", sent, s)
+				}
+			}
+			logged := strings.Join(tt.slack.sent("#log"), "\n")
+			for _, s := range tc.wantLog {
+				if !strings.Contains(logged, s) {
+					t.Errorf("#log messages %q don't contain %q", logged, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRetroCheck(t *testing.T) {
+	const retroBoard = "5c8a0e1f2b3c4d5e6f709001"
+	const actionList = "5c8a0e1f2b3c4d5e6f709a01"
+
+	stale := time.Now().AddDate(0, 0, -30)
+
+	tests := []struct {
+		name      string
+		listName  string
+		card      BoardCard
+		users     [][]driver.Value
+		wantDM    bool
+		wantError bool
+	}{
+		{
+			name:     "stale action item DMs its owner",
+			listName: "Action Items",
+			card:     BoardCard{DateLastActivity: stale, IDMembers: []string{"5c8a0e1f2b3c4d5e6f70f001"}},
+			users:    [][]driver.Value{userRow("Example Person", "U0001", "exampleperson")},
+			wantDM:   true,
+		},
+		{
+			name:     "recent action item is left alone",
+			listName: "Action Items",
+			card:     BoardCard{DateLastActivity: time.Now(), IDMembers: []string{"5c8a0e1f2b3c4d5e6f70f001"}},
+			users:    [][]driver.Value{userRow("Example Person", "U0001", "exampleperson")},
+		},
+		{
+			name:     "closed action item is left alone",
+			listName: "Action Items",
+			card:     BoardCard{DateLastActivity: stale, Closed: true, IDMembers: []string{"5c8a0e1f2b3c4d5e6f70f001"}},
+			users:    [][]driver.Value{userRow("Example Person", "U0001", "exampleperson")},
+		},
+		{
+			name:     "owner tiktok doesn't know",
+			listName: "Action Items",
+			card:     BoardCard{DateLastActivity: stale, IDMembers: []string{"5c8a0e1f2b3c4d5e6f70f001"}},
+		},
+		{
+			name:     "board without an action items list",
+			listName: "Ideas",
+			card:     BoardCard{DateLastActivity: stale, IDMembers: []string{"5c8a0e1f2b3c4d5e6f70f001"}},
+			users:    [][]driver.Value{userRow("Example Person", "U0001", "exampleperson")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.db.on("FROM tiktok_users", userCols, tc.users...)
+
+			card := tc.card
+			card.ID = "5c8a0e2a2b3c4d5e6f709c01"
+			card.Name = "Fix the build lights"
+			card.IDBoard = retroBoard
+			card.IDList = actionList
+			tt.trello.Fixture.Boards = append(tt.trello.Fixture.Boards, BoardData{ID: retroBoard, Name: "Retro: Example", Cards: []BoardCard{card}})
+			tt.trello.Fixture.Lists = append(tt.trello.Fixture.Lists, ListData{{ID: actionList, Name: tc.listName, IDBoard: retroBoard}}...)
+
+			err := RetroCheck(tt.tiktok, tt.trello, tt.opts, retroBoard)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("RetroCheck() error = %v, wantError %v", err, tc.wantError)
+			}
+
+			dms := tt.slack.sent("U0001")
+			if got := len(dms) == 1; got != tc.wantDM {
+				t.Fatalf("DMs sent = %q, want a DM %v", dms, tc.wantDM)
+			}
+			if tc.wantDM && !strings.Contains(dms[0], "Fix the build lights") {
+				t.Errorf("DM %q doesn't name the card", dms[0])
+			}
+		})
+	}
+}
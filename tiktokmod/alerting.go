@@ -1,7 +1,6 @@
 package tiktokmod
 
 import (
-	"encoding/json"
 	"math/rand"
 	"net/url"
 	"strconv"
@@ -15,7 +14,7 @@ type lists struct {
 }
 
 // AlertRunner - Run the alerts in Planning / Next Sprint / Ready for Work / Working
-func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
+func AlertRunner(opts Config, tiktok *TikTokConf, trello TrelloAPI) (string, error) {
 
 	var attachments Attachment
 	var messageAlertOut string
@@ -23,10 +22,9 @@ func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
 	var mHmessage string
 	var tMessage string
 	var temp string
-	var hush bool
 	var weHaveSpike bool
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Error retrieving all cards on board "+opts.General.BoardID+" in `alerting.go` func `AlertRunner`", err)
 		return "", err
@@ -41,14 +39,7 @@ func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
 	for _, aTt := range allTheThings.Cards {
 		if aTt.IDList == opts.General.NextsprintID || aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working {
 
-			hush = false
-			for _, l := range aTt.Labels {
-				if l.ID == opts.General.SilenceCardLabel {
-					hush = true
-				}
-			}
-
-			if !hush {
+			if !CardHushed(opts, aTt) {
 				// verify if we have a {SPIKE} card or not
 				spikeText := Between(aTt.Name, "{", "}")
 				if strings.ToLower(spikeText) == "spike" {
@@ -57,7 +48,7 @@ func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
 					weHaveSpike = false
 				}
 
-				points := GetCardPoints(opts, tiktok, aTt.ID)
+				points := CardPoints(tiktok, opts, aTt)
 				spoints := strconv.Itoa(points)
 
 				if points > opts.General.MaxPoints {
@@ -71,15 +62,7 @@ func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
 				// Check if card should NOT have an owner head on it and remove
 				if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.NextsprintID {
 
-					if len(aTt.IDMembers) > 0 {
-
-						for _, head := range aTt.IDMembers {
-							err := RemoveHead(tiktok, aTt.ID, head)
-							if err != nil {
-								errTrap(tiktok, "Error attempting to remove head from card <"+aTt.ShortURL+"|"+aTt.Name+"> in `AlertRunner` in `alerting.go`", err)
-							}
-						}
-
+					if RemoveHeads(tiktok, trello, aTt) {
 						rHmessage = rHmessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
 					}
 				}
@@ -97,58 +80,65 @@ func AlertRunner(opts Config, tiktok *TikTokConf) (string, error) {
 	if rHmessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = "These cards should not be assigned yet!\n" + rHmessage
-		Wrangler(tiktok.Config.SlackHook, "<!here> NOTICE!  I have *removed* people from these cards", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> NOTICE!  I have *removed* people from these cards", opts.General.ComplaintChannel, attachments)
 	}
 
 	if mHmessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = "I'm sad! These cards are in the working column but have nobody assigned to them!\n" + mHmessage
-		Wrangler(tiktok.Config.SlackHook, "<!here> Warning Un-Assigned Work!!", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> Warning Un-Assigned Work!!", opts.General.ComplaintChannel, attachments)
 	}
 
 	if messageAlertOut != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = "These cards have too many or not enough points!\n" + messageAlertOut
-		Wrangler(tiktok.Config.SlackHook, "<!here> Warning cards with Point issues!!", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> Warning cards with Point issues!!", opts.General.ComplaintChannel, attachments)
 	}
 
-	temp, _ = CheckThemes(tiktok, opts, opts.General.Upcoming)
+	temp, _ = CheckThemes(tiktok, trello, opts, opts.General.Upcoming)
 	tMessage = tMessage + temp
-	temp, _ = CheckThemes(tiktok, opts, opts.General.Scoped)
+	temp, _ = CheckThemes(tiktok, trello, opts, opts.General.Scoped)
 	tMessage = tMessage + temp
-	temp, _ = CheckThemes(tiktok, opts, opts.General.ReadyForWork)
+	temp, _ = CheckThemes(tiktok, trello, opts, opts.General.ReadyForWork)
 	tMessage = tMessage + temp
 
 	if tMessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = tMessage
-		Wrangler(tiktok.Config.SlackHook, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, attachments)
 	}
 
 	return "", nil
 }
 
-// GetCardPoints - Get the points on a card from the power-up
-func GetCardPoints(opts Config, tiktok *TikTokConf, cardID string) (points int) {
-	pluginCard, _ := GetPowerUpField(cardID, tiktok)
-
-	for _, p := range pluginCard {
-
-		if p.IDPlugin == tiktok.Config.PointsPowerUpID {
+// CardHushed - true if the card carries the team's silence label
+func CardHushed(opts Config, aTt BoardCard) bool {
+	for _, l := range aTt.Labels {
+		if l.ID == opts.General.SilenceCardLabel {
+			return true
+		}
+	}
+	return false
+}
 
-			var plugins PointsHistory
+// RemoveHeads - take everyone off a card that shouldn't be assigned yet, true if anybody was on it
+func RemoveHeads(tiktok *TikTokConf, trello TrelloAPI, aTt BoardCard) bool {
+	if len(aTt.IDMembers) == 0 {
+		return false
+	}
 
-			pluginJSON := []byte(p.Value)
-			json.Unmarshal(pluginJSON, &plugins)
-			points = plugins.Points
+	for _, head := range aTt.IDMembers {
+		err := trello.RemoveHead(aTt.ID, head)
+		if err != nil {
+			errTrap(tiktok, "Error attempting to remove head from card <"+aTt.ShortURL+"|"+aTt.Name+"> in `RemoveHeads` in `alerting.go`", err)
 		}
 	}
 
-	return points
+	return true
 }
 
 // StalePRcards - Check for cards that are aged out in the PR column
-func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
+func StalePRcards(opts Config, tiktok *TikTokConf, trello TrelloAPI) (message string, err error) {
 
 	var attachments Attachment
 	var smessage string
@@ -158,38 +148,26 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 
 	LogToSlack("I'm trolling the PR Column cards in the `"+opts.General.TeamName+"` board.", tiktok, attachments)
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Error retrieving all cards from func `RetrieveAll` in `StalePRCards` in `alerting.go` with board "+opts.General.TeamName, err)
 	}
 
+	cal := TeamCalendar(tiktok, opts)
+
 	for _, aTt := range allTheThings.Cards {
 
 		if aTt.IDList == opts.General.ReadyForReview {
-			cardAction, err := GetCardAction(tiktok, aTt.ID, 1)
+			cardAction, err := trello.GetCardAction(aTt.ID, 1)
 			if err != nil {
 				errTrap(tiktok, "Error from `GetCardAction` in `StalePRCards` in `alerting.go`", err)
 			}
 
 			for _, actions := range cardAction {
-				createdAt := actions.Date
-				expiresAt := time.Now()
-				diff := expiresAt.Sub(createdAt)
+				// only working time counts, weekends and holidays off the team calendar don't
+				diff := cal.Elapsed(actions.Date, time.Now())
 				staleTimer := time.Duration(opts.General.StaleTime) * time.Hour
 
-				// compenstate for weekends
-				if opts.General.IgnoreWeekends {
-					if int(time.Now().Weekday()) == 1 {
-						diff = diff - time.Duration(48)*time.Hour
-					}
-				}
-
-				// compenstate if yesterday was a holiday
-				isHoliday, _ := IsHoliday(tiktok, time.Now().AddDate(0, 0, -1))
-				if isHoliday {
-					diff = diff - time.Duration(24)*time.Hour
-				}
-
 				if tiktok.Config.LogToSlack {
 					LogToSlack("Time in list for card <"+aTt.ShortURL+"|"+aTt.Name+"> is "+diff.String(), tiktok, attachments)
 				}
@@ -197,7 +175,7 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 				if diff > staleTimer {
 					// retrieve github PR from trello attachments if it exists
 					if aTt.Badges.Attachments > 0 {
-						attached, _ := GetAttachments(tiktok, aTt.ID)
+						attached, _ := trello.GetAttachments(aTt.ID)
 						prFound = false
 						for _, a := range attached {
 							if !a.IsUpload && strings.Contains(a.URL, "github.com") && strings.Contains(a.URL, "/pull/") {
@@ -221,14 +199,14 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 									if err == nil {
 										// look in github to see if PR is closed/merged
 										if *prDetail.Merged {
-											loc, _ := time.LoadLocation("America/Los_Angeles")
+											loc := cal.Location
 											prMergeTime := *prDetail.MergedAt
 											lastUpdate := prMergeTime.In(loc).Format("2006-01-02 15:04:05")
 											uMessage = "*PLEASE NOTE* : The Github Pull Request for this card was merged on `" + lastUpdate + "`, does this card need to be closed in Trello? <" + aTt.URL + "|" + aTt.Name + ">\n"
 											tMessage = ""
 											if len(aTt.IDMembers) > 0 {
 												for _, u := range aTt.IDMembers {
-													user, err := GetUser(tiktok, "trello", u)
+													user, err := tiktok.DB.GetUser("trello", u)
 													if err == nil {
 														if user.SlackID != "" {
 															tMessage = tMessage + "@" + user.SlackID + " "
@@ -241,32 +219,17 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 													LogToSlack("PR <"+*prDetail.HTMLURL+"|"+*prDetail.Title+"> is merged but card still open, alerting owners ("+tMessage+") and channel", tiktok, attachments)
 												}
 											}
-											Wrangler(tiktok.Config.SlackHook, uMessage, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+											TeamWrangler(tiktok, opts, uMessage, opts.General.ComplaintChannel, attachments)
 										} else {
 											// look in github to see if PR has been commented on in past 24 hours
-											upAt := *prDetail.UpdatedAt
-											expiresAt := time.Now()
-											diff := expiresAt.Sub(upAt)
-
-											// compenstate for weekends
-											if opts.General.IgnoreWeekends {
-												if int(time.Now().Weekday()) == 1 {
-													diff = diff - time.Duration(48)*time.Hour
-												}
-											}
-
-											// compenstate if yesterday was a holiday
-											isHoliday, _ := IsHoliday(tiktok, time.Now().AddDate(0, 0, -1))
-											if isHoliday {
-												diff = diff - time.Duration(24)*time.Hour
-											}
+											diff := cal.Elapsed(*prDetail.UpdatedAt, time.Now())
 
 											if tiktok.Config.LogToSlack {
 												LogToSlack("PR <"+*prDetail.HTMLURL+"|"+*prDetail.Title+"> was last modifed/updated "+diff.String()+" ago", tiktok, attachments)
 											}
 
 											if diff > staleTimer {
-												smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
+												smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">" + ChecklistNote(aTt) + "\n"
 											} else {
 												if tiktok.Config.LogToSlack {
 													LogToSlack("<"+aTt.URL+"|"+aTt.Name+"> is lagging in trello but has current updates in Github, no alerting.  PR Is here <"+*prDetail.HTMLURL+"|"+*prDetail.Title+">", tiktok, attachments)
@@ -281,14 +244,14 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 							if tiktok.Config.LogToSlack {
 								LogToSlack("No github PR's found attached to <"+aTt.ShortURL+"|"+aTt.Name+">", tiktok, attachments)
 							}
-							smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
+							smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">" + ChecklistNote(aTt) + "\n"
 						}
 					} else {
 						// no PR attached so assuming the worst
 						if tiktok.Config.LogToSlack {
 							LogToSlack("No PR attached to <"+aTt.ShortURL+"|"+aTt.Name+"> and its over time so sending warning message.", tiktok, attachments)
 						}
-						smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
+						smessage = smessage + "<" + aTt.ShortURL + "|" + aTt.Name + ">" + ChecklistNote(aTt) + "\n"
 					}
 				} else {
 					if tiktok.Config.LogToSlack {
@@ -304,26 +267,24 @@ func StalePRcards(opts Config, tiktok *TikTokConf) (message string, err error) {
 	if smessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = "These are " + strconv.Itoa(opts.General.StaleTime) + " hours or older\n" + smessage
-		Wrangler(tiktok.Config.SlackHook, "<!here> WARNING!! Lagging PR Card(s)!!", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> WARNING!! Lagging PR Card(s)!!", opts.General.ComplaintChannel, attachments)
 	}
 
 	return "", nil
 }
 
 // SkippedPR - Alert if cards have skipped PR column
-func SkippedPR(tiktok *TikTokConf, opts Config) {
+func SkippedPR(tiktok *TikTokConf, trello TrelloAPI, opts Config) {
 	var message string
 	var attachments Attachment
-	var commentMsg string
-	var checkThisCard bool
 
-	users, err := GetDBUsers(tiktok)
+	users, err := tiktok.DB.GetUsers()
 	if err != nil {
 		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `SkippedPR` in `alerting.go`", err)
 		return
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll in `SkippedPR` in `alerting.go` for `"+opts.General.TeamName+"` board", err)
 		return
@@ -333,54 +294,103 @@ func SkippedPR(tiktok *TikTokConf, opts Config) {
 	for _, aTt := range allTheThings.Cards {
 		if !aTt.Closed {
 			if aTt.IDList == opts.General.Done {
-
-				checkThisCard = true
-
-				// check if card has already been commented on by TikTok and skip it if so
-				cardComments, err := GetCardComments(aTt.ID, tiktok)
+				line, err := CheckSkippedReview(tiktok, trello, opts, users, allTheThings, aTt)
 				if err != nil {
-					errTrap(tiktok, "Error on return from `GetCardComments` in `SkippedPR` in `Trello.go`", err)
 					return
 				}
-				for _, c := range cardComments {
-					if c.MemberCreator.Username == tiktok.Config.BotTrelloID {
-						if strings.Contains(c.Data.Text, tiktok.Config.BotName+" PR Message:") {
-							checkThisCard = false
+				message = message + line
+			}
+		}
+	}
+
+	if message != "" {
+		attachments.Color = "#ff0000"
+		attachments.Text = message
+		TeamWrangler(tiktok, opts, SkippedReviewHeader, opts.General.ComplaintChannel, attachments)
+	}
+}
+
+// SkippedReviewHeader - channel alert that goes above the cards CheckSkippedReview finds
+const SkippedReviewHeader = "*Warning* The following cards appear to have skipped the review column in trello.  If you are an owner of one of these cards I will slack you directly about putting a note in it regarding why it skipped `Ready for Review`!\nPlease review these!"
+
+// CheckSkippedReview - if a Done card went straight past Ready for Review, DM its owner and comment on the card so it's
+// only reported once.  Returns the line for the channel alert, blank if there's nothing to report
+func CheckSkippedReview(tiktok *TikTokConf, trello TrelloAPI, opts Config, users []UserData, board BoardData, aTt BoardCard) (message string, err error) {
+	var attachments Attachment
+	var commentMsg string
+
+	// check if card has already been commented on by TikTok and skip it if so
+	cardComments, err := trello.GetCardComments(aTt.ID)
+	if err != nil {
+		errTrap(tiktok, "Error on return from `GetCardComments` in `CheckSkippedReview` in `alerting.go`", err)
+		return "", err
+	}
+	for _, c := range cardComments {
+		if c.MemberCreator.Username == tiktok.Config.BotTrelloID {
+			if strings.Contains(c.Data.Text, tiktok.Config.BotName+" PR Message:") {
+				return "", nil
+			}
+		}
+	}
+
+	cardListHistory := trello.GetCardListHistory(aTt.ID)
+
+	for _, h := range cardListHistory {
+		if h.Data.ListAfter.ID == opts.General.Done {
+			if h.Data.ListBefore.ID != opts.General.ReadyForReview {
+				message = "<https://trello.com/c/" + aTt.ID + "|" + aTt.Name + ">\n"
+				commentMsg = tiktok.Config.BotName + " PR Message: Couldn't find a card owner on this card that has skipped the Review process so I sent a general alert to the " + opts.General.ComplaintChannel + " slack channel about it."
+
+				for _, u := range users {
+					if len(aTt.IDMembers) > 0 {
+						_, _, userName := BoardMember(trello, board, aTt.IDMembers[0])
+						if userName == u.Trello {
+							commentMsg = tiktok.Config.BotName + " PR Message: Sent warning to @" + u.Trello + " that this card skipped the Review process and they should put an update in it with an explanation."
+							Wrangler(tiktok.Config.SlackHook, "*Warning!* This card with your face on it, appears to have skipped the `Review` column, please resolve this by adding notes as to why this happened. Even spikes should be reviewed! Thank you!\n<https://trello.com/c/"+aTt.ID+"|"+aTt.Name+">", "@"+u.SlackID, tiktok.Config.SlackEmoji, attachments)
 						}
 					}
 				}
 
-				if checkThisCard {
-					cardListHistory := GetCardListHistory(aTt.ID, tiktok)
-
-					for _, h := range cardListHistory {
-						if h.Data.ListAfter.ID == opts.General.Done {
-							if h.Data.ListBefore.ID != opts.General.ReadyForReview {
-								message = message + "<https://trello.com/c/" + aTt.ID + "|" + aTt.Name + ">\n"
-								commentMsg = tiktok.Config.BotName + " PR Message: Couldn't find a card owner on this card that has skipped the Review process so I sent a general alert to the " + opts.General.ComplaintChannel + " slack channel about it."
-
-								for _, u := range users {
-									if len(aTt.IDMembers) > 0 {
-										_, _, userName := GetMemberInfo(aTt.IDMembers[0], tiktok)
-										if userName == u.Trello {
-											commentMsg = tiktok.Config.BotName + " PR Message: Sent warning to @" + u.Trello + " that this card skipped the Review process and they should put an update in it with an explanation."
-											Wrangler(tiktok.Config.SlackHook, "*Warning!* This card with your face on it, appears to have skipped the `Review` column, please resolve this by adding notes as to why this happened. Even spikes should be reviewed! Thank you!\n<https://trello.com/c/"+aTt.ID+"|"+aTt.Name+">", "@"+u.SlackID, tiktok.Config.SlackEmoji, attachments)
-										}
-									}
-								}
+				// put comment on the card so it gets ignored next round
+				err = trello.CommentCard(aTt.ID, commentMsg)
+				if err != nil {
+					errTrap(tiktok, "Error attempting to comment on card "+aTt.ID+" in `CheckSkippedReview` in `alerting.go`", err)
+					return message, err
+				}
 
-								// put comment on the card so it gets ignored next round
-								err = CommentCard(aTt.ID, commentMsg, tiktok)
-								if err != nil {
-									errTrap(tiktok, "Error attempting to comment on card "+aTt.ID+" bailing out of SkippedPR routine", err)
-									return
-								}
+				break
+			}
+		}
+	}
 
-								break
-							}
-						}
-					}
+	return message, nil
+}
+
+// DoneChecklists - Alert if cards reached Done with checklist items still open
+func DoneChecklists(tiktok *TikTokConf, trello TrelloAPI, opts Config) {
+	var message string
+	var attachments Attachment
+
+	users, err := tiktok.DB.GetUsers()
+	if err != nil {
+		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `DoneChecklists` in `alerting.go`", err)
+		return
+	}
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll in `DoneChecklists` in `alerting.go` for `"+opts.General.TeamName+"` board", err)
+		return
+	}
+
+	for _, aTt := range allTheThings.Cards {
+		if !aTt.Closed {
+			if aTt.IDList == opts.General.Done {
+				line, err := CheckDoneChecklist(tiktok, trello, opts, users, allTheThings, aTt)
+				if err != nil {
+					return
 				}
+				message = message + line
 			}
 		}
 	}
@@ -388,19 +398,166 @@ func SkippedPR(tiktok *TikTokConf, opts Config) {
 	if message != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = message
-		headerMsg := "*Warning* The following cards appear to have skipped the review column in trello.  If you are an owner of one of these cards I will slack you directly about putting a note in it regarding why it skipped `Ready for Review`!\nPlease review these!"
-		Wrangler(tiktok.Config.SlackHook, headerMsg, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, DoneChecklistHeader, opts.General.ComplaintChannel, attachments)
+	}
+}
+
+// DoneChecklistHeader - channel alert that goes above the cards CheckDoneChecklist finds
+const DoneChecklistHeader = "*Warning* The following cards are in `Done` but still have unchecked checklist items.  If you are an owner of one of these cards I will slack you directly about finishing or removing them!\nPlease review these!"
+
+// CheckDoneChecklist - if a Done card has open checklist items, DM its owner and comment on the card listing them so
+// it's only reported once.  Returns the line for the channel alert, blank if there's nothing to report
+func CheckDoneChecklist(tiktok *TikTokConf, trello TrelloAPI, opts Config, users []UserData, board BoardData, aTt BoardCard) (message string, err error) {
+	var attachments Attachment
+	var commentMsg string
+
+	done, total, open := ChecklistProgress(aTt)
+	if done == total {
+		return "", nil
+	}
+
+	// check if card has already been commented on by TikTok and skip it if so
+	cardComments, err := trello.GetCardComments(aTt.ID)
+	if err != nil {
+		errTrap(tiktok, "Error on return from `GetCardComments` in `CheckDoneChecklist` in `alerting.go`", err)
+		return "", err
 	}
+	for _, c := range cardComments {
+		if c.MemberCreator.Username == tiktok.Config.BotTrelloID {
+			if strings.Contains(c.Data.Text, tiktok.Config.BotName+" Checklist Message:") {
+				return "", nil
+			}
+		}
+	}
+
+	message = "<https://trello.com/c/" + aTt.ID + "|" + aTt.Name + ">" + ChecklistNote(aTt) + "\n"
+	commentMsg = tiktok.Config.BotName + " Checklist Message: Couldn't find a card owner on this card that is Done with " + strconv.Itoa(total-done) + " unchecked checklist items so I sent a general alert to the " + opts.General.ComplaintChannel + " slack channel about it."
+
+	for _, u := range users {
+		if len(aTt.IDMembers) > 0 {
+			_, _, userName := BoardMember(trello, board, aTt.IDMembers[0])
+			if userName == u.Trello {
+				commentMsg = tiktok.Config.BotName + " Checklist Message: Sent warning to @" + u.Trello + " that this card is Done with " + strconv.Itoa(total-done) + " unchecked checklist items."
+				Wrangler(tiktok.Config.SlackHook, "*Warning!* This card with your face on it is in `Done` but still has unchecked checklist items, please check them off or remove them if they're no longer needed. Thank you!\n<https://trello.com/c/"+aTt.ID+"|"+aTt.Name+">", "@"+u.SlackID, tiktok.Config.SlackEmoji, attachments)
+			}
+		}
+	}
+
+	for _, item := range open {
+		commentMsg = commentMsg + "\n- " + item
+	}
+
+	// put comment on the card so it gets ignored next round
+	err = trello.CommentCard(aTt.ID, commentMsg)
+	if err != nil {
+		errTrap(tiktok, "Error attempting to comment on card "+aTt.ID+" in `CheckDoneChecklist` in `alerting.go`", err)
+		return message, err
+	}
+
+	return message, nil
+}
+
+// DueDates - Alert on overdue cards in the sprint columns, cards due before the sprint ends that haven't been started and,
+// if the board has RequireDueDates set, Working cards with no due date.  Owners get a DM with their cards
+func DueDates(tiktok *TikTokConf, trello TrelloAPI, opts Config, teamID string) (string, error) {
+	var attachments Attachment
+	var overdue string
+	var atRisk string
+	var noDue string
+
+	sOpts, err := tiktok.DB.GetSprint(teamID)
+	if err != nil {
+		errTrap(tiktok, "DB error in `GetSprint` in `DueDates` in `alerting.go`", err)
+		return "", err
+	}
+	sprintEnd := sOpts.SprintStart.AddDate(0, 0, sOpts.Duration)
+
+	users, err := tiktok.DB.GetUsers()
+	if err != nil {
+		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `DueDates` in `alerting.go`", err)
+		return "", err
+	}
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll in `DueDates` in `alerting.go` for `"+opts.General.TeamName+"` board", err)
+		return "", err
+	}
+
+	// cards per owner's slack ID for the DMs
+	owned := make(map[string]string)
+
+	for _, aTt := range allTheThings.Cards {
+		if aTt.Closed || aTt.DueComplete || CardHushed(opts, aTt) {
+			continue
+		}
+		if aTt.IDList != opts.General.ReadyForWork && aTt.IDList != opts.General.Working && aTt.IDList != opts.General.ReadyForReview {
+			continue
+		}
+
+		line := ""
+		due, hasDue := CardDue(aTt)
+
+		switch {
+		case hasDue && due.Before(time.Now()):
+			line = "<" + aTt.ShortURL + "|" + aTt.Name + "> was due *" + due.Local().Format("Mon Jan 2") + "*\n"
+			overdue = overdue + line
+		case hasDue && aTt.IDList == opts.General.ReadyForWork && due.Before(sprintEnd):
+			line = "<" + aTt.ShortURL + "|" + aTt.Name + "> is due *" + due.Local().Format("Mon Jan 2") + "* and hasn't been started\n"
+			atRisk = atRisk + line
+		case !hasDue && aTt.IDList == opts.General.Working && opts.General.RequireDueDates:
+			line = "<" + aTt.ShortURL + "|" + aTt.Name + "> has no due date\n"
+			noDue = noDue + line
+		}
+
+		if line == "" {
+			continue
+		}
+		for _, m := range aTt.IDMembers {
+			_, _, userName := BoardMember(trello, allTheThings, m)
+			for _, u := range users {
+				if userName != "" && userName == u.Trello && u.SlackID != "" {
+					owned[u.SlackID] = owned[u.SlackID] + line
+				}
+			}
+		}
+	}
+
+	for slackID, cards := range owned {
+		attachments.Color = "#ff0000"
+		attachments.Text = cards
+		Wrangler(tiktok.Config.SlackHook, "*Heads up!* These cards with your face on them need attention on their due dates:", "@"+slackID, tiktok.Config.SlackEmoji, attachments)
+	}
+
+	if overdue != "" {
+		attachments.Color = "#ff0000"
+		attachments.Text = overdue
+		TeamWrangler(tiktok, opts, "<!here> Warning Overdue Cards!!", opts.General.ComplaintChannel, attachments)
+	}
+
+	if atRisk != "" {
+		attachments.Color = "#ffa500"
+		attachments.Text = atRisk
+		TeamWrangler(tiktok, opts, "*Warning* These cards are due before the end of the sprint but are still in `Ready for Work`", opts.General.ComplaintChannel, attachments)
+	}
+
+	if noDue != "" {
+		attachments.Color = "#ffa500"
+		attachments.Text = noDue
+		TeamWrangler(tiktok, opts, "*Warning* These cards are being worked but have no due date", opts.General.ComplaintChannel, attachments)
+	}
+
+	return "", nil
 }
 
 // CheckBugs - Check for bugs and alert on them
-func CheckBugs(opts Config, tiktok *TikTokConf) (critBugNum int) {
+func CheckBugs(opts Config, tiktok *TikTokConf, trello TrelloAPI) (critBugNum int) {
 	var message string
 	var amessage string
 	var criticalID string
 	var attachments Attachment
 
-	bugLabels, err := GetBugID(tiktok, opts.General.BoardID)
+	bugLabels, err := tiktok.DB.GetBugIDs(opts.General.BoardID)
 	if err != nil {
 		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `SkippedPR` in `alerting.go`", err)
 		return 0
@@ -411,7 +568,7 @@ func CheckBugs(opts Config, tiktok *TikTokConf) (critBugNum int) {
 		}
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll in `CheckBugs` in `alerting.go` for `"+opts.General.TeamName+"` board", err)
 		return 0
@@ -442,7 +599,7 @@ func CheckBugs(opts Config, tiktok *TikTokConf) (critBugNum int) {
 
 		attachments.Color = "#FF0000"
 		attachments.Text = message
-		Wrangler(tiktok.Config.SlackHook, amessage, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, amessage, opts.General.ComplaintChannel, attachments)
 	} else {
 		if tiktok.Config.LogToSlack {
 			LogToSlack("No Critical Bugs Found", tiktok, attachments)
@@ -461,9 +618,9 @@ func SendAlert(tiktok *TikTokConf, opts Config, alertType string) {
 	var channel string
 	var message string
 
-	// Check for Holiday
-	isHoliday, holiday := IsHoliday(tiktok, time.Now())
-	if isHoliday && opts.General.HolidaySupport {
+	// Check for Holiday, company wide or the team's own
+	holiday, isHoliday := TeamCalendar(tiktok, opts).Holiday(time.Now())
+	if isHoliday && !holiday.HalfDay {
 		if tiktok.Config.LogToSlack {
 			LogToSlack("Today is Holiday, skipping "+alertType+" slack alert. ("+holiday.Name+")", tiktok, attachments)
 		}
@@ -504,7 +661,7 @@ func SendAlert(tiktok *TikTokConf, opts Config, alertType string) {
 	rand.Seed(time.Now().Unix())
 	message = "<!here> " + preMsg[rand.Intn(len(preMsg))] + " - " + location
 
-	Wrangler(tiktok.Config.SlackHook, message, channel, tiktok.Config.SlackEmoji, attachments)
+	TeamWrangler(tiktok, opts, message, channel, attachments)
 
 	return
 }
@@ -0,0 +1,81 @@
+package tiktokmod
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueDates(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).UTC().Format(time.RFC3339)
+	nextWeek := time.Now().AddDate(0, 0, 7).UTC().Format(time.RFC3339)
+	nextYear := time.Now().AddDate(1, 0, 0).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		list        string
+		due         string
+		dueComplete bool
+		requireDue  bool
+		noSprint    bool
+		wantErr     bool
+		want        string
+		wantDM      bool
+	}{
+		{name: "overdue working card", list: "a6", due: yesterday, want: "Warning Overdue Cards", wantDM: true},
+		{name: "overdue card that's done is ignored", list: "a6", due: yesterday, dueComplete: true},
+		{name: "due this sprint but not started", list: "a5", due: nextWeek, want: "still in `Ready for Work`", wantDM: true},
+		{name: "due after the sprint", list: "a5", due: nextYear},
+		{name: "working card with no due date", list: "a6", requireDue: true, want: "have no due date", wantDM: true},
+		{name: "due dates not required", list: "a6"},
+		{name: "cards outside the sprint are ignored", list: "a1", due: yesterday},
+		{name: "no current sprint", list: "a6", due: yesterday, noSprint: true, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.opts.General.RequireDueDates = tc.requireDue
+			if !tc.noSprint {
+				tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now(), "Example-01-01-2019"))
+			}
+			tt.db.on("FROM tiktok_users", userCols, userRow("Example Person", "U0001", "exampleperson"))
+
+			// only the roll over card, which has a member on it, is in the sprint
+			for b := range tt.trello.Fixture.Boards {
+				for c := range tt.trello.Fixture.Boards[b].Cards {
+					card := &tt.trello.Fixture.Boards[b].Cards[c]
+					card.IDList = "5c8a0e1f2b3c4d5e6f7000a8"
+					if card.ID == rollCard {
+						card.IDList = "5c8a0e1f2b3c4d5e6f7000" + tc.list
+						card.DueComplete = tc.dueComplete
+						if tc.due != "" {
+							card.Due = tc.due
+						}
+					}
+				}
+			}
+
+			_, err := DueDates(tt.tiktok, tt.trello, tt.opts, "example")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DueDates() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			sent := strings.Join(tt.slack.sent("#complaints"), "\n")
+			if tc.want == "" && sent != "" {
+				t.Errorf("unexpected alert %q", sent)
+			}
+			if tc.want != "" && !strings.Contains(sent, tc.want) {
+				t.Errorf("alerts %q don't contain %q", sent, tc.want)
+			}
+			if tc.want != "" && !strings.Contains(sent, "Roll me over") {
+				t.Errorf("alerts %q don't name the card", sent)
+			}
+
+			dms := tt.slack.sent("@U0001")
+			if got := len(dms) == 1; got != tc.wantDM {
+				t.Errorf("DMs sent = %q, want a DM %v", dms, tc.wantDM)
+			}
+		})
+	}
+}
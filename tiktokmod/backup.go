@@ -0,0 +1,510 @@
+package tiktokmod
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupVersion - bump if the layout of BoardBackup changes
+const backupVersion = 1
+
+// backupActionPages - most pages of 1000 actions we'll pull for one board
+const backupActionPages = 50
+
+// BoardBackup - a full export of a board as written to BackupDir.  Board is trello's own JSON for the board with its
+// lists, cards, custom fields, checklists, labels and members.  Actions is every action on the board, comments included
+type BoardBackup struct {
+	Version int               `json:"version"`
+	Team    string            `json:"team"`
+	BoardID string            `json:"boardId"`
+	Taken   time.Time         `json:"taken"`
+	Board   json.RawMessage   `json:"board"`
+	Actions []json.RawMessage `json:"actions"`
+}
+
+// backupCard - the parts of a backed up card we diff and restore
+type backupCard struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Desc             string            `json:"desc"`
+	IDList           string            `json:"idList"`
+	IDLabels         []string          `json:"idLabels"`
+	Closed           bool              `json:"closed"`
+	Pos              float64           `json:"pos"`
+	Due              string            `json:"due"`
+	ShortURL         string            `json:"shortUrl"`
+	CustomFieldItems []CustomFieldItem `json:"customFieldItems"`
+}
+
+// backupView - the parts of a backed up board we diff and restore
+type backupView struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards        []backupCard  `json:"cards"`
+	Labels       []Labels      `json:"labels"`
+	CustomFields []backupField `json:"customFields"`
+	Checklists   []Checklist   `json:"checklists"`
+}
+
+// backupField - a backed up custom field definition
+type backupField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// view - decode the parts of the board we work with
+func (b BoardBackup) view() (v backupView, err error) {
+	err = json.Unmarshal(b.Board, &v)
+	return v, err
+}
+
+// backupDir - BackupDir from tiktok.toml, backups/ if it isn't set
+func backupDir(tiktok *TikTokConf) string {
+	if tiktok.Config.BackupDir == "" {
+		return "backups"
+	}
+	return tiktok.Config.BackupDir
+}
+
+// ExportBoard - pull a complete copy of a board and all its actions from trello
+func ExportBoard(tiktok *TikTokConf, team string, boardID string) (backup BoardBackup, err error) {
+	backup = BoardBackup{Version: backupVersion, Team: team, BoardID: boardID, Taken: time.Now().UTC()}
+
+	url := "https://api.trello.com/1/boards/" + boardID + "?fields=all&lists=all&cards=all&card_customFieldItems=true&card_attachments=true&checklists=all&customFields=true&labels=all&labels_limit=1000&members=all&actions=none&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+	err = trelloDo(tiktok, "GET", url, nil, &backup.Board)
+	if err != nil {
+		errTrap(tiktok, "Error calling trello in `ExportBoard` in `backup.go`", err)
+		return backup, err
+	}
+
+	// trello hands actions back newest first, 1000 at a time
+	before := ""
+	for page := 0; page < backupActionPages; page++ {
+		var actions []json.RawMessage
+
+		url := "https://api.trello.com/1/boards/" + boardID + "/actions?filter=all&limit=1000&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+		if before != "" {
+			url = url + "&before=" + before
+		}
+		err = trelloDo(tiktok, "GET", url, nil, &actions)
+		if err != nil {
+			errTrap(tiktok, "Error calling trello for actions in `ExportBoard` in `backup.go`", err)
+			return backup, err
+		}
+		backup.Actions = append(backup.Actions, actions...)
+		if len(actions) < 1000 {
+			break
+		}
+
+		var last struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(actions[len(actions)-1], &last)
+		before = last.ID
+	}
+
+	return backup, nil
+}
+
+// BackupBoard - export a team board to BackupDir/<team>/<team>-<timestamp>.json and rotate out the oldest files past
+// BackupKeep
+func BackupBoard(tiktok *TikTokConf, opts Config, team string) (file string, err error) {
+	backup, err := ExportBoard(tiktok, team, opts.General.BoardID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(backupDir(tiktok), team)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		errTrap(tiktok, "Unable to create backup directory `"+dir+"` in `BackupBoard` in `backup.go`", err)
+		return "", err
+	}
+
+	file = filepath.Join(dir, team+"-"+backup.Taken.Format("20060102-150405")+".json")
+
+	// write then rename so a half written file never looks like a backup
+	if err := ioutil.WriteFile(file+".tmp", data, 0640); err != nil {
+		errTrap(tiktok, "Unable to write backup `"+file+"` in `BackupBoard` in `backup.go`", err)
+		return "", err
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		errTrap(tiktok, "Unable to write backup `"+file+"` in `BackupBoard` in `backup.go`", err)
+		return "", err
+	}
+
+	removed := rotateBackups(tiktok, dir, team)
+
+	if tiktok.Config.LogToSlack {
+		var attachments Attachment
+		LogToSlack("Backed up the `"+opts.General.TeamName+"` board to `"+file+"` ("+strconv.Itoa(len(backup.Actions))+" actions, "+strconv.Itoa(len(data)/1024)+"KB).  Removed "+strconv.Itoa(removed)+" old backups.", tiktok, attachments)
+	}
+
+	return file, nil
+}
+
+// rotateBackups - keep the newest BackupKeep backups of a team, 0 keeps them all
+func rotateBackups(tiktok *TikTokConf, dir string, team string) (removed int) {
+	if tiktok.Config.BackupKeep <= 0 {
+		return 0
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+
+	var backups []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), team+"-") && strings.HasSuffix(f.Name(), ".json") {
+			backups = append(backups, f.Name())
+		}
+	}
+
+	// the timestamp in the name sorts oldest first
+	sort.Strings(backups)
+	for len(backups) > tiktok.Config.BackupKeep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			errTrap(tiktok, "Unable to remove old backup `"+backups[0]+"` in `rotateBackups` in `backup.go`", err)
+		} else {
+			removed++
+		}
+		backups = backups[1:]
+	}
+
+	return removed
+}
+
+// LoadBackup - read a backup file written by BackupBoard
+func LoadBackup(file string) (backup BoardBackup, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return backup, err
+	}
+
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return backup, errors.New(file + " is not a board backup: " + err.Error())
+	}
+	if backup.Version != backupVersion {
+		return backup, errors.New(file + " is backup version " + strconv.Itoa(backup.Version) + ", this build reads version " + strconv.Itoa(backupVersion))
+	}
+
+	return backup, nil
+}
+
+// DiffBackups - what changed on a board between two backups of it
+func DiffBackups(older BoardBackup, newer BoardBackup) (message string, err error) {
+	ov, err := older.view()
+	if err != nil {
+		return "", err
+	}
+	nv, err := newer.view()
+	if err != nil {
+		return "", err
+	}
+
+	message = "Board " + nv.Name + " from " + older.Taken.Format(time.RFC3339) + " to " + newer.Taken.Format(time.RFC3339) + "\n"
+	if older.BoardID != newer.BoardID {
+		message = message + "NOTE: these are backups of different boards (" + older.BoardID + " and " + newer.BoardID + ")\n"
+	}
+
+	listName := make(map[string]string)
+	oldLists := make(map[string]string)
+	for _, l := range ov.Lists {
+		listName[l.ID] = l.Name
+		oldLists[l.ID] = l.Name
+	}
+	for _, l := range nv.Lists {
+		listName[l.ID] = l.Name
+		if name, ok := oldLists[l.ID]; !ok {
+			message = message + "+ list " + l.Name + "\n"
+		} else if name != l.Name {
+			message = message + "~ list " + name + " renamed to " + l.Name + "\n"
+		}
+		delete(oldLists, l.ID)
+	}
+	for _, name := range oldLists {
+		message = message + "- list " + name + "\n"
+	}
+
+	labelName := make(map[string]string)
+	for _, l := range append(ov.Labels, nv.Labels...) {
+		labelName[l.ID] = l.Name + "(" + l.Color + ")"
+	}
+	fieldName := make(map[string]string)
+	for _, f := range append(ov.CustomFields, nv.CustomFields...) {
+		fieldName[f.ID] = f.Name
+	}
+
+	oldCards := make(map[string]backupCard)
+	for _, c := range ov.Cards {
+		oldCards[c.ID] = c
+	}
+	for _, c := range nv.Cards {
+		o, ok := oldCards[c.ID]
+		delete(oldCards, c.ID)
+		if !ok {
+			message = message + "+ card " + c.Name + " in " + listName[c.IDList] + "\n"
+			continue
+		}
+
+		var changes []string
+		if o.Name != c.Name {
+			changes = append(changes, "renamed from \""+o.Name+"\"")
+		}
+		if o.IDList != c.IDList {
+			changes = append(changes, "moved from "+listName[o.IDList]+" to "+listName[c.IDList])
+		}
+		if o.Closed != c.Closed {
+			if c.Closed {
+				changes = append(changes, "archived")
+			} else {
+				changes = append(changes, "un-archived")
+			}
+		}
+		if o.Desc != c.Desc {
+			changes = append(changes, "description changed")
+		}
+		if o.Due != c.Due {
+			changes = append(changes, "due date changed from \""+o.Due+"\" to \""+c.Due+"\"")
+		}
+		if strings.Join(namesOf(o.IDLabels, labelName), ",") != strings.Join(namesOf(c.IDLabels, labelName), ",") {
+			changes = append(changes, "labels changed from ["+strings.Join(namesOf(o.IDLabels, labelName), ", ")+"] to ["+strings.Join(namesOf(c.IDLabels, labelName), ", ")+"]")
+		}
+		oldValues := fieldValues(o)
+		newValues := fieldValues(c)
+		for id, v := range newValues {
+			if oldValues[id] != v {
+				changes = append(changes, fieldName[id]+" changed from \""+oldValues[id]+"\" to \""+v+"\"")
+			}
+		}
+		for id, v := range oldValues {
+			if _, ok := newValues[id]; !ok {
+				changes = append(changes, fieldName[id]+" cleared (was \""+v+"\")")
+			}
+		}
+		od, ot := backupChecklist(ov, o.ID)
+		nd, nt := backupChecklist(nv, c.ID)
+		if od != nd || ot != nt {
+			changes = append(changes, "checklists went from "+strconv.Itoa(od)+"/"+strconv.Itoa(ot)+" to "+strconv.Itoa(nd)+"/"+strconv.Itoa(nt)+" checked")
+		}
+
+		if len(changes) > 0 {
+			message = message + "~ card " + c.Name + ": " + strings.Join(changes, "; ") + "\n"
+		}
+	}
+	for _, c := range oldCards {
+		message = message + "- card " + c.Name + " (was in " + listName[c.IDList] + ", deleted)\n"
+	}
+
+	message = message + strconv.Itoa(len(newer.Actions)-len(older.Actions)) + " new actions\n"
+
+	return message, nil
+}
+
+// namesOf - sorted names for a set of IDs
+func namesOf(ids []string, names map[string]string) (out []string) {
+	for _, id := range ids {
+		out = append(out, names[id])
+	}
+	sort.Strings(out)
+	return out
+}
+
+// fieldValues - a card's text and number custom field values by field ID
+func fieldValues(c backupCard) map[string]string {
+	values := make(map[string]string)
+	for _, f := range c.CustomFieldItems {
+		values[f.IDCustomField] = f.Value.Text + f.Value.Number
+	}
+	return values
+}
+
+// backupChecklist - checked and total checklist items on a backed up card
+func backupChecklist(v backupView, cardID string) (done int, total int) {
+	for _, cl := range v.Checklists {
+		if cl.IDCard != cardID {
+			continue
+		}
+		for _, item := range cl.CheckItems {
+			total++
+			if item.State == "complete" {
+				done++
+			}
+		}
+	}
+	return done, total
+}
+
+// trelloCreate - POST a new trello object and return its ID
+func trelloCreate(tiktok *TikTokConf, path string, fields map[string]interface{}) (id string, err error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+
+	fields["key"] = tiktok.Config.Tkey
+	fields["token"] = tiktok.Config.Ttoken
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	err = trelloDo(tiktok, "POST", "https://api.trello.com/1/"+path, body, &created)
+	return created.ID, err
+}
+
+// RestoreBackup - recreate the open lists and cards of a backup on a board.  Lists, labels and custom fields are
+// matched by name, missing lists are created.  Cards already in the same list with the same name are skipped so a
+// restore can be run again after a failure.  Comments and history can't be written back to trello, each card gets a
+// comment pointing at the card it was restored from instead
+func RestoreBackup(tiktok *TikTokConf, trello TrelloAPI, backup BoardBackup, boardID string) (message string, err error) {
+	v, err := backup.view()
+	if err != nil {
+		return "", err
+	}
+	defer boards.invalidateBoard(boardID)
+
+	lists, err := trello.GetLists(boardID)
+	if err != nil {
+		return "", err
+	}
+	labels, err := GetBoardLabels(boardID, tiktok)
+	if err != nil {
+		return "", err
+	}
+	fields, err := GetBoardCustoms(boardID, tiktok)
+	if err != nil {
+		return "", err
+	}
+	existing, err := trello.RetrieveAll(boardID, "open")
+	if err != nil {
+		return "", err
+	}
+
+	// backup list ID to target list ID
+	listMap := make(map[string]string)
+	created := 0
+	for _, bl := range v.Lists {
+		if bl.Closed {
+			continue
+		}
+		for _, l := range lists {
+			if strings.EqualFold(l.Name, bl.Name) && !l.Closed {
+				listMap[bl.ID] = l.ID
+				break
+			}
+		}
+		if listMap[bl.ID] == "" {
+			id, err := trelloCreate(tiktok, "lists", map[string]interface{}{"name": bl.Name, "idBoard": boardID, "pos": "bottom"})
+			if err != nil {
+				return message, err
+			}
+			listMap[bl.ID] = id
+			created++
+		}
+	}
+	message = message + "Created " + strconv.Itoa(created) + " lists\n"
+
+	labelMap := make(map[string]string)
+	for _, bl := range v.Labels {
+		for _, l := range labels {
+			if l.Name == bl.Name && l.Color == bl.Color {
+				labelMap[bl.ID] = l.ID
+			}
+		}
+	}
+	fieldMap := make(map[string]*Customs)
+	for _, bf := range v.CustomFields {
+		for _, f := range fields {
+			if f.Name == bf.Name && f.Type == bf.Type {
+				fieldMap[bf.ID] = f
+			}
+		}
+	}
+
+	restored, skipped := 0, 0
+	for _, c := range v.Cards {
+		idList := listMap[c.IDList]
+		if c.Closed || idList == "" {
+			continue
+		}
+
+		dupe := false
+		for _, e := range existing.Cards {
+			if e.IDList == idList && e.Name == c.Name {
+				dupe = true
+			}
+		}
+		if dupe {
+			skipped++
+			continue
+		}
+
+		var idLabels []string
+		for _, l := range c.IDLabels {
+			if labelMap[l] != "" {
+				idLabels = append(idLabels, labelMap[l])
+			}
+		}
+		card := map[string]interface{}{"name": c.Name, "desc": c.Desc, "idList": idList, "pos": c.Pos, "idLabels": strings.Join(idLabels, ",")}
+		if c.Due != "" {
+			card["due"] = c.Due
+		}
+		cardID, err := trelloCreate(tiktok, "cards", card)
+		if err != nil {
+			return message, err
+		}
+		restored++
+
+		for _, f := range c.CustomFieldItems {
+			target := fieldMap[f.IDCustomField]
+			switch {
+			case target == nil:
+			case f.Value.Text != "":
+				_ = trello.PutCustomField(cardID, target.ID, "text", f.Value.Text)
+			case f.Value.Number != "":
+				_ = trello.PutCustomField(cardID, target.ID, "number", f.Value.Number)
+			}
+		}
+
+		for _, cl := range v.Checklists {
+			if cl.IDCard != c.ID {
+				continue
+			}
+			checklistID, err := trelloCreate(tiktok, "checklists", map[string]interface{}{"idCard": cardID, "name": cl.Name})
+			if err != nil {
+				errTrap(tiktok, "Unable to restore checklist on card "+cardID+" in `RestoreBackup` in `backup.go`", err)
+				continue
+			}
+			for _, item := range cl.CheckItems {
+				_, err := trelloCreate(tiktok, "checklists/"+checklistID+"/checkItems", map[string]interface{}{"name": item.Name, "pos": item.Pos, "checked": item.State == "complete"})
+				if err != nil {
+					errTrap(tiktok, "Unable to restore checklist item on card "+cardID+" in `RestoreBackup` in `backup.go`", err)
+				}
+			}
+		}
+
+		_ = trello.CommentCard(cardID, tiktok.Config.BotName+" restored this card from a backup taken "+backup.Taken.Format(time.RFC3339)+" of "+c.ShortURL)
+	}
+
+	message = message + "Restored " + strconv.Itoa(restored) + " cards, skipped " + strconv.Itoa(skipped) + " already on the board\n"
+
+	return message, nil
+}
@@ -0,0 +1,288 @@
+package tiktokmod
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cardLink - a link to a trello card, the short link is the first path part after /c/
+var cardLink = regexp.MustCompile(`trello\.com/c/([A-Za-z0-9]+)`)
+
+// Blocker - a card another card is waiting on
+type Blocker struct {
+	ShortLink string
+	ID        string
+	Name      string
+	ShortURL  string
+	IDList    string
+	OnBoard   bool
+	Done      bool
+}
+
+// Dependency - a card and the cards blocking it
+type Dependency struct {
+	Card     BoardCard
+	Blockers []Blocker
+}
+
+// cardShortLink - a card's short link, pulled out of its short URL if trello didn't send it
+func cardShortLink(card BoardCard) string {
+	if card.ShortLink != "" {
+		return card.ShortLink
+	}
+	if m := cardLink.FindStringSubmatch(card.ShortURL); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// blockerLinks - short links of the cards blocking this one.  Any card attachment named "Blocked by ..." or
+// "Blocker ..." counts, and on cards with the team's BlockedLabel so does every card attachment or card link in the
+// description
+func blockerLinks(opts Config, card BoardCard) (links []string) {
+	labelled := false
+	if opts.General.BlockedLabel != "" {
+		for _, l := range card.Labels {
+			if l.ID == opts.General.BlockedLabel {
+				labelled = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(link string) {
+		if link != "" && link != cardShortLink(card) && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+
+	for _, a := range card.Attachments {
+		m := cardLink.FindStringSubmatch(a.URL)
+		if a.IsUpload || m == nil {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(a.Name))
+		if labelled || strings.HasPrefix(name, "blocked by") || strings.HasPrefix(name, "blocker") {
+			add(m[1])
+		}
+	}
+
+	if labelled {
+		for _, m := range cardLink.FindAllStringSubmatch(card.Desc, -1) {
+			add(m[1])
+		}
+	}
+
+	return links
+}
+
+// BoardDependencies - every open card on the board that's blocked by another card.  Blockers on other boards are looked
+// up on their own and count as done once they're archived
+func BoardDependencies(tiktok *TikTokConf, trello TrelloAPI, opts Config, board BoardData) (deps []Dependency) {
+	byLink := make(map[string]BoardCard)
+	for _, c := range board.Cards {
+		byLink[cardShortLink(c)] = c
+	}
+	offBoard := make(map[string]Blocker)
+
+	for _, aTt := range board.Cards {
+		if aTt.Closed {
+			continue
+		}
+		links := blockerLinks(opts, aTt)
+		if len(links) == 0 {
+			continue
+		}
+
+		dep := Dependency{Card: aTt}
+		for _, link := range links {
+			if c, ok := byLink[link]; ok {
+				dep.Blockers = append(dep.Blockers, Blocker{ShortLink: link, ID: c.ID, Name: c.Name, ShortURL: c.ShortURL, IDList: c.IDList, OnBoard: true, Done: c.Closed || c.IDList == opts.General.Done})
+				continue
+			}
+
+			b, ok := offBoard[link]
+			if !ok {
+				c, err := trello.GetCard(link)
+				if err != nil {
+					errTrap(tiktok, "Unable to find blocker card "+link+" for <"+aTt.ShortURL+"|"+aTt.Name+"> in `BoardDependencies` in `blockers.go`", err)
+					continue
+				}
+				b = Blocker{ShortLink: link, ID: c.ID, Name: c.Name, ShortURL: c.ShortURL, IDList: c.IDList, Done: c.Closed}
+				offBoard[link] = b
+			}
+			dep.Blockers = append(dep.Blockers, b)
+		}
+
+		if len(dep.Blockers) > 0 {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// BlockerCheck - Alert on Working cards blocked by unfinished cards, and let owners know when a blocker reaches Done
+func BlockerCheck(tiktok *TikTokConf, trello TrelloAPI, opts Config) {
+	var message string
+	var attachments Attachment
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll in `BlockerCheck` in `blockers.go` for `"+opts.General.TeamName+"` board", err)
+		return
+	}
+
+	deps := BoardDependencies(tiktok, trello, opts, allTheThings)
+
+	for _, d := range deps {
+		if d.Card.IDList != opts.General.Working || CardHushed(opts, d.Card) {
+			continue
+		}
+		for _, b := range d.Blockers {
+			if !b.Done {
+				message = message + "<" + d.Card.ShortURL + "|" + d.Card.Name + "> is blocked by <" + b.ShortURL + "|" + b.Name + ">\n"
+			}
+		}
+	}
+
+	if message != "" {
+		attachments.Color = "#ff0000"
+		attachments.Text = message
+		TeamWrangler(tiktok, opts, "<!here> Warning! These cards are being worked but are blocked by cards that aren't done yet", opts.General.ComplaintChannel, attachments)
+	}
+
+	NotifyUnblocked(tiktok, trello, opts, allTheThings, deps)
+}
+
+// NotifyUnblocked - DM the owners of cards whose blocker has reached Done and comment on the card so it's only said once
+func NotifyUnblocked(tiktok *TikTokConf, trello TrelloAPI, opts Config, board BoardData, deps []Dependency) {
+	var attachments Attachment
+
+	users, err := tiktok.DB.GetUsers()
+	if err != nil {
+		errTrap(tiktok, "Error getting user data from `GetDBUsers` in `NotifyUnblocked` in `blockers.go`", err)
+		return
+	}
+
+	for _, d := range deps {
+		if d.Card.IDList == opts.General.Done {
+			continue
+		}
+
+		var cardComments CardComment
+		loaded := false
+
+		for _, b := range d.Blockers {
+			if !b.Done {
+				continue
+			}
+
+			// only load the comments of cards that have a finished blocker
+			if !loaded {
+				cardComments, err = trello.GetCardComments(d.Card.ID)
+				if err != nil {
+					errTrap(tiktok, "Error on return from `GetCardComments` in `NotifyUnblocked` in `blockers.go`", err)
+					break
+				}
+				loaded = true
+			}
+
+			marker := tiktok.Config.BotName + " Blocker Message: " + b.ShortURL
+			told := false
+			for _, c := range cardComments {
+				if c.MemberCreator.Username == tiktok.Config.BotTrelloID && strings.Contains(c.Data.Text, marker) {
+					told = true
+				}
+			}
+			if told {
+				continue
+			}
+
+			for _, m := range d.Card.IDMembers {
+				_, _, userName := BoardMember(trello, board, m)
+				for _, u := range users {
+					if userName != "" && userName == u.Trello && u.SlackID != "" {
+						Wrangler(tiktok.Config.SlackHook, "*Good news!* <"+b.ShortURL+"|"+b.Name+"> is done, so it's no longer blocking your card <"+d.Card.ShortURL+"|"+d.Card.Name+">", "@"+u.SlackID, tiktok.Config.SlackEmoji, attachments)
+					}
+				}
+			}
+
+			err = trello.CommentCard(d.Card.ID, marker+" is done and no longer blocks this card.")
+			if err != nil {
+				errTrap(tiktok, "Error attempting to comment on card "+d.Card.ID+" in `NotifyUnblocked` in `blockers.go`", err)
+			}
+		}
+	}
+}
+
+// BlockerChains - text listing of every blocked card on the board and what it's waiting on, blockers of blockers indented
+// underneath them
+func BlockerChains(tiktok *TikTokConf, trello TrelloAPI, opts Config) (message string, err error) {
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll in `BlockerChains` in `blockers.go` for `"+opts.General.TeamName+"` board", err)
+		return "", err
+	}
+
+	listNames := make(map[string]string)
+	lists, err := trello.GetLists(opts.General.BoardID)
+	if err == nil {
+		for _, l := range lists {
+			listNames[l.ID] = l.Name
+		}
+	}
+
+	deps := BoardDependencies(tiktok, trello, opts, allTheThings)
+	blockedBy := make(map[string][]Blocker)
+	blocking := make(map[string]bool)
+	for _, d := range deps {
+		blockedBy[d.Card.ID] = d.Blockers
+		for _, b := range d.Blockers {
+			blocking[b.ID] = true
+		}
+	}
+
+	status := func(b Blocker) string {
+		switch {
+		case b.Done:
+			return "done"
+		case !b.OnBoard:
+			return "other board"
+		case listNames[b.IDList] != "":
+			return listNames[b.IDList]
+		}
+		return "open"
+	}
+
+	printed := make(map[string]bool)
+
+	var chain func(cardID string, depth int, seen map[string]bool)
+	chain = func(cardID string, depth int, seen map[string]bool) {
+		printed[cardID] = true
+		for _, b := range blockedBy[cardID] {
+			message = message + strings.Repeat("    ", depth) + "↳ blocked by <" + b.ShortURL + "|" + b.Name + "> (" + status(b) + ")\n"
+			if seen[b.ID] {
+				message = message + strings.Repeat("    ", depth+1) + "↳ *circular dependency!*\n"
+				continue
+			}
+			seen[b.ID] = true
+			chain(b.ID, depth+1, seen)
+			delete(seen, b.ID)
+		}
+	}
+
+	// start from the cards at the end of each chain, the ones nothing else is waiting on, then pick up any loops
+	for _, ends := range []bool{true, false} {
+		for _, d := range deps {
+			if printed[d.Card.ID] || d.Card.IDList == opts.General.Done || (ends && blocking[d.Card.ID]) {
+				continue
+			}
+			message = message + "<" + d.Card.ShortURL + "|" + d.Card.Name + ">\n"
+			chain(d.Card.ID, 1, map[string]bool{d.Card.ID: true})
+		}
+	}
+
+	return message, nil
+}
@@ -0,0 +1,170 @@
+package tiktokmod
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// boardSnapshot - one cached RetrieveAll answer
+type boardSnapshot struct {
+	board   BoardData
+	fetched time.Time
+}
+
+// boardCache - RetrieveAll answers shared across cron jobs and commands for BoardCacheTTL seconds.  Our own writes
+// drop the board the card lives on so nobody reads back stale data they just changed.  Every drop bumps the board's
+// generation, and a RetrieveAll that started before the drop isn't cached when it finishes
+type boardCache struct {
+	mu        sync.Mutex
+	snapshots map[string]boardSnapshot
+	cardBoard map[string]string
+	gens      map[string]uint64
+	allGen    uint64
+}
+
+var boards = boardCache{snapshots: make(map[string]boardSnapshot), cardBoard: make(map[string]string), gens: make(map[string]uint64)}
+
+// get - a copy of a cached board if it's younger than ttl
+func (c *boardCache) get(boardID string, whichCards string, ttl time.Duration) (BoardData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snap, ok := c.snapshots[boardID+":"+whichCards]
+	if !ok || time.Since(snap.fetched) > ttl {
+		return BoardData{}, false
+	}
+
+	return copyBoard(snap.board), true
+}
+
+// generation - take this before fetching a board from trello and hand it to put
+func (c *boardCache) generation(boardID string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.allGen + c.gens[boardID]
+}
+
+// put - cache a board fetched at generation gen, unless it's been invalidated since then and could be stale
+func (c *boardCache) put(boardID string, whichCards string, board BoardData, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.allGen+c.gens[boardID] != gen {
+		return
+	}
+
+	c.snapshots[boardID+":"+whichCards] = boardSnapshot{board: copyBoard(board), fetched: time.Now()}
+	for _, card := range board.Cards {
+		c.cardBoard[card.ID] = boardID
+	}
+}
+
+// invalidateBoard - drop every cached snapshot of a board
+func (c *boardCache) invalidateBoard(boardID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gens[boardID]++
+	for key := range c.snapshots {
+		if strings.HasPrefix(key, boardID+":") {
+			delete(c.snapshots, key)
+		}
+	}
+}
+
+// invalidateCard - drop the board a card was last seen on, or everything if we've never seen it
+func (c *boardCache) invalidateCard(cardID string) {
+	c.mu.Lock()
+	boardID, ok := c.cardBoard[cardID]
+	c.mu.Unlock()
+
+	if !ok {
+		c.invalidateAll()
+		return
+	}
+	c.invalidateBoard(boardID)
+}
+
+// invalidateAll - drop everything, for writes we can't tie to a board
+func (c *boardCache) invalidateAll() {
+	c.mu.Lock()
+	c.allGen++
+	c.snapshots = make(map[string]boardSnapshot)
+	c.mu.Unlock()
+}
+
+// copyBoard - callers add up points on the labels and shuffle cards around, so they never get the cached slices, or
+// anything hanging off them
+func copyBoard(board BoardData) BoardData {
+	cards := make([]BoardCard, len(board.Cards))
+	for i, card := range board.Cards {
+		cards[i] = copyCard(card)
+	}
+	if board.Cards != nil {
+		board.Cards = cards
+	}
+	board.Labels = append(Themes(nil), board.Labels...)
+	board.Members = append([]Member(nil), board.Members...)
+	board.Checklists = copyChecklists(board.Checklists)
+
+	var fields CustomCollection
+	for _, f := range board.CustomFields {
+		field := *f
+		fields = append(fields, &field)
+	}
+	board.CustomFields = fields
+
+	return board
+}
+
+// copyCard - a card with none of its slices shared
+func copyCard(card BoardCard) BoardCard {
+	card.IDMembersVoted = append([]interface{}(nil), card.IDMembersVoted...)
+	card.IDLabels = append([]interface{}(nil), card.IDLabels...)
+	card.IDChecklists = append([]interface{}(nil), card.IDChecklists...)
+	card.IDMembers = append([]string(nil), card.IDMembers...)
+	card.Labels = append([]CardLabel(nil), card.Labels...)
+	card.CustomFieldItems = append([]CustomFieldItem(nil), card.CustomFieldItems...)
+	card.Checklists = copyChecklists(card.Checklists)
+
+	var plugins PluginCollection
+	for _, p := range card.PluginData {
+		plugin := *p
+		plugins = append(plugins, &plugin)
+	}
+	card.PluginData = plugins
+
+	var attachments []CardAttachment
+	for _, a := range card.Attachments {
+		a.Previews = append([]AttachmentPreview(nil), a.Previews...)
+		attachments = append(attachments, a)
+	}
+	card.Attachments = attachments
+
+	return card
+}
+
+func copyChecklists(checklists []Checklist) []Checklist {
+	var copied []Checklist
+	for _, cl := range checklists {
+		cl.CheckItems = append([]CheckItem(nil), cl.CheckItems...)
+		copied = append(copied, cl)
+	}
+
+	return copied
+}
+
+// boardCacheTTL - BoardCacheTTL from tiktok.toml, 0 turns the cache off
+func boardCacheTTL(tiktok *TikTokConf) time.Duration {
+	return time.Duration(tiktok.Config.BoardCacheTTL) * time.Second
+}
+
+// RefreshBoard - throw away any cached snapshots and list/label names of a board and load it fresh from trello
+func RefreshBoard(tiktok *TikTokConf, trello TrelloAPI, boardID string) (BoardData, error) {
+	boards.invalidateBoard(boardID)
+	names.forget(boardID)
+
+	return trello.RetrieveAll(boardID, "visible")
+}
@@ -0,0 +1,73 @@
+package tiktokmod
+
+import (
+	"testing"
+	"time"
+)
+
+func testBoard() BoardData {
+	var board BoardData
+	board.ID = "5c8a0e1f2b3c4d5e6f708091"
+	board.Cards = []BoardCard{{
+		ID:               "5c8a0e2a2b3c4d5e6f700001",
+		IDMembers:        []string{"5c8a0e1f2b3c4d5e6f70f001"},
+		Labels:           []CardLabel{{ID: "5c8a0e1f2b3c4d5e6f70b001", Name: "ROLL-OVER"}},
+		CustomFieldItems: []CustomFieldItem{{IDCustomField: "5c8a0e1f2b3c4d5e6f70c001"}},
+		PluginData:       PluginCollection{{IDPlugin: "59d4ef8cfea15a55b0086614", Value: `{"points":3}`}},
+		Checklists:       []Checklist{{ID: "cl1", CheckItems: []CheckItem{{ID: "ci1", State: "incomplete"}}}},
+	}}
+
+	return board
+}
+
+func TestBoardCacheCopiesCards(t *testing.T) {
+	cache := boardCache{snapshots: make(map[string]boardSnapshot), cardBoard: make(map[string]string), gens: make(map[string]uint64)}
+	board := testBoard()
+	cache.put(board.ID, "visible", board, cache.generation(board.ID))
+
+	// scribble on the board we cached and on the first copy handed out
+	board.Cards[0].IDMembers[0] = "changed"
+	got, ok := cache.get(board.ID, "visible", time.Minute)
+	if !ok {
+		t.Fatal("board wasn't cached")
+	}
+	got.Cards[0].Labels[0].Name = "changed"
+	got.Cards[0].CustomFieldItems[0].Value.Text = "changed"
+	got.Cards[0].PluginData[0].Value = "changed"
+	got.Cards[0].Checklists[0].CheckItems[0].State = "complete"
+
+	again, _ := cache.get(board.ID, "visible", time.Minute)
+	c := again.Cards[0]
+	if c.IDMembers[0] == "changed" || c.Labels[0].Name == "changed" || c.CustomFieldItems[0].Value.Text == "changed" || c.PluginData[0].Value == "changed" || c.Checklists[0].CheckItems[0].State == "complete" {
+		t.Errorf("cached card was changed through a copy: %+v", c)
+	}
+}
+
+func TestBoardCacheRejectsStalePut(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *boardCache)
+		wantCached bool
+	}{
+		{name: "nothing changed", invalidate: func(c *boardCache) {}, wantCached: true},
+		{name: "board written", invalidate: func(c *boardCache) { c.invalidateBoard("5c8a0e1f2b3c4d5e6f708091") }},
+		{name: "unknown card written", invalidate: func(c *boardCache) { c.invalidateCard("5c8a0e2a2b3c4d5e6f7000ff") }},
+		{name: "another board written", invalidate: func(c *boardCache) { c.invalidateBoard("5c8a0e1f2b3c4d5e6f709001") }, wantCached: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := boardCache{snapshots: make(map[string]boardSnapshot), cardBoard: make(map[string]string), gens: make(map[string]uint64)}
+			board := testBoard()
+
+			// a fetch starts, a write lands while it's in flight, then the fetch finishes
+			gen := cache.generation(board.ID)
+			tc.invalidate(&cache)
+			cache.put(board.ID, "visible", board, gen)
+
+			if _, ok := cache.get(board.ID, "visible", time.Minute); ok != tc.wantCached {
+				t.Errorf("cached = %v, want %v", ok, tc.wantCached)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package tiktokmod
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"sort"
@@ -13,1619 +12,1221 @@ import (
 	"github.com/robfig/cron"
 )
 
-// BotActions - TikTokConf Actions based on commands
-func BotActions(lowerString string, tiktok *TikTokConf, ev *slack.MessageEvent, rtm *slack.RTM, api *slack.Client, c *cron.Cron, cronjobs *Cronjobs, CronState string) (*cron.Cron, *Cronjobs, string) {
-
-	var attachments Attachment
-	var smessage string
-	var teamID string
-	var labelID string
-	var cardTitle string
-	var testPayload BotDMPayload
+// BotActions - TikTokConf Actions based on commands.  See Commands() in `commands.go` for what tiktok listens for
+func BotActions(lowerString string, tiktok *TikTokConf, ev *slack.MessageEvent, out Messenger, api *slack.Client, c *cron.Cron, cronjobs *Cronjobs, CronState string) (*cron.Cron, *Cronjobs, string) {
+	return DispatchCommand(lowerString, tiktok, ev, out, api, c, cronjobs, CronState)
+}
 
-	if strings.Contains(lowerString, "run builtin test") || strings.Contains(lowerString, "run built-in test") {
-		//rtm.SendMessage(rtm.NewOutgoingMessage("Running Built-In test that you built! :unicornfart:", ev.Msg.Channel))
-		rtm.SendMessage(rtm.NewOutgoingMessage("Currently no built-in tests!", ev.Msg.Channel))
-		//opts, _ := LoadConf(tiktok, "mcboard")
+// cmdBuiltinTest - hidden hook for trying things out against a live slack
+func cmdBuiltinTest(tiktok *TikTokConf, req *CommandRequest) {
+	//req.Reply("Running Built-In test that you built! :unicornfart:")
+	req.Reply("Currently no built-in tests!")
+	//SendAlert(tiktok, req.Opts, "demo")
+}
 
-		//SendAlert(tiktok, opts, "demo")
-	}
+// cmdRetroActions - checks and alerts on non-active retro action item cards (this can also be CRON'd)
+func cmdRetroActions(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-	// Request that TikTokConf checks and alerts on non-active retro action item cards (this can also be CRON'd)
-	if strings.Contains(lowerString, "check retro action activity") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
+	Wrangler(tiktok.Config.SlackHook, "Checking Sprint Retro boards for action items with no activity!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	LogToSlack(req.User.Name+" asked me to check sprint retro boards for action items with no activity on `"+req.Team+"` trello board.", tiktok, attachments)
 
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+	CheckActionCards(tiktok, tiktok.Trello, req.Opts, req.Team)
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "Checking Sprint Retro boards for action items with no activity!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	Wrangler(tiktok.Config.SlackHook, "Check process complete.", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to check sprint retro boards for action items with no activity on `"+teamID+"` trello board.", tiktok, attachments)
+// cmdChapterPoints - add up chapter points by column
+func cmdChapterPoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var message string
 
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				return c, cronjobs, CronState
-			}
+	Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-			CheckActionCards(tiktok, opts, teamID)
+	columnID, colName := GetColumn(req.Opts, req.Lower)
 
-			Wrangler(tiktok.Config.SlackHook, "Check process complete.", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	LogToSlack(req.User.Name+" asked me to add up chapter points on `"+req.Team+"` trello board in column `"+colName+"`.", tiktok, attachments)
 
-		}
+	allChapters, noChapter, err := ChapterPoint(tiktok, tiktok.Trello, req.Opts, columnID)
+	if err != nil {
+		return
+	}
+	for _, chapter := range allChapters {
+		message = message + "Points for " + chapter.ChapterName + " = " + strconv.Itoa(chapter.ChapterPoints) + "\n"
 	}
 
-	// Dump Chapter Cards by column
-	if strings.Contains(lowerString, "chapter points") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-
-			var columnID string
-			var colName string
-			var message string
-
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				return c, cronjobs, CronState
-			}
-
-			columnID, colName = GetColumn(opts, lowerString)
-
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to add up chapter points on `"+teamID+"` trello board in column `"+colName+"`.", tiktok, attachments)
-
-			allChapters, noChapter, err := ChapterPoint(tiktok, opts, columnID)
-			if err != nil {
-				return c, cronjobs, CronState
-			}
-			for _, chapter := range allChapters {
-				message = message + "Points for " + chapter.ChapterName + " = " + strconv.Itoa(chapter.ChapterPoints) + "\n"
-			}
-
-			message = message + "Points not assigned to a chapter: " + strconv.Itoa(noChapter) + "\n"
-			attachments.Color = "#00ff00"
-			attachments.Text = message
-			Wrangler(tiktok.Config.SlackHook, "Chapter point counts", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	message = message + "Points not assigned to a chapter: " + strconv.Itoa(noChapter) + "\n"
+	attachments.Color = "#00ff00"
+	attachments.Text = message
+	Wrangler(tiktok.Config.SlackHook, "Chapter point counts", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			return c, cronjobs, CronState
-		}
-	}
+// cmdCriticalBugs - check for critical bugs when asked, defaults to the mcboard board
+func cmdCriticalBugs(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-	// Check for Critical bugs when asked
-	if strings.Contains(lowerString, "are there any critical bugs") || strings.Contains(lowerString, "check for critical bugs") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-		if teamID == "" {
-			teamID = "mcboard"
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to check for Critical Bugs and didn't specify a team, so defaulting to `mcboard` trello board.", tiktok, attachments)
-			Wrangler(tiktok.Config.SlackHook, "You didn't specify a team/trello board so I'm assuming you mean `mcboard`.", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	opts := req.Opts
+	teamID := req.Team
 
-		}
-		LogToSlack(userInfo.Name+" asked me to check for Critical Bugs on the "+teamID+" trello board.", tiktok, attachments)
+	if teamID == "" {
+		teamID = "mcboard"
+		LogToSlack(req.User.Name+" asked me to check for Critical Bugs and didn't specify a team, so defaulting to `mcboard` trello board.", tiktok, attachments)
+		Wrangler(tiktok.Config.SlackHook, "You didn't specify a team/trello board so I'm assuming you mean `mcboard`.", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-		opts, err := LoadConf(tiktok, teamID)
+		var err error
+		opts, err = LoadConf(tiktok, teamID)
 		if err != nil {
 			errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-			return c, cronjobs, CronState
-		}
-
-		attachments.Color = ""
-		attachments.Text = ""
-		Wrangler(tiktok.Config.SlackHook, "One sec, I will check and then alert the "+opts.General.ComplaintChannel+" channel if I find any.", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-		numBug := CheckBugs(opts, tiktok)
-
-		if numBug == 0 {
-			Wrangler(tiktok.Config.SlackHook, "I didn't find any critical bugs, Sweet!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-			Wrangler(tiktok.Config.SlackHook, "I found a critical bug quantity of "+strconv.Itoa(numBug)+"!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+			return
 		}
-		return c, cronjobs, CronState
 	}
+	LogToSlack(req.User.Name+" asked me to check for Critical Bugs on the "+teamID+" trello board.", tiktok, attachments)
 
-	// Dump Chapter Cards by column into the Database - defaults to backlog if no column specified
-	if strings.Contains(lowerString, "record chapter count ") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-
-			var colName string
+	Wrangler(tiktok.Config.SlackHook, "One sec, I will check and then alert the "+opts.General.ComplaintChannel+" channel if I find any.", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up and record it to the database!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	numBug := CheckBugs(opts, tiktok, tiktok.Trello)
 
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				return c, cronjobs, CronState
-			}
-
-			_, colName = GetColumn(opts, lowerString)
-
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to record in the DB the card count on chapter cards on `"+teamID+"` trello board in column `"+colName+"`.", tiktok, attachments)
-
-			err = RecordChapters(tiktok, teamID, colName)
-
-			if err != nil {
-				Wrangler(tiktok.Config.SlackHook, "Something went wrong, please check the logs in the log channel #"+tiktok.Config.LogChannel, ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-				return c, cronjobs, CronState
-			}
-
-			Wrangler(tiktok.Config.SlackHook, "Data recorded!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-			return c, cronjobs, CronState
-		}
+	if numBug == 0 {
+		Wrangler(tiktok.Config.SlackHook, "I didn't find any critical bugs, Sweet!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	} else {
+		Wrangler(tiktok.Config.SlackHook, "I found a critical bug quantity of "+strconv.Itoa(numBug)+"!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 	}
+}
 
-	// Dump Chapter Cards by column
-	if strings.Contains(lowerString, "check chapters") || strings.Contains(lowerString, "chapter count ") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-
-			var columnID string
-			var colName string
-			var message string
-
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				return c, cronjobs, CronState
-			}
-
-			columnID, colName = GetColumn(opts, lowerString)
+// cmdRecordChapterCount - dump chapter cards by column into the Database - defaults to backlog if no column specified
+func cmdRecordChapterCount(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to add report on chapter cards on `"+teamID+"` trello board in column `"+colName+"`.", tiktok, attachments)
+	Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up and record it to the database!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-			allChapters, totalCards, err := ChapterCount(tiktok, opts, columnID)
-			if err != nil {
-				return c, cronjobs, CronState
-			}
-			for _, chapter := range allChapters {
-				message = message + "Cards for " + chapter.ChapterName + " = " + strconv.Itoa(chapter.ChapterCount) + "\n"
-			}
-			message = message + "Total Cards in column " + colName + " = " + strconv.Itoa(totalCards) + "\n"
+	_, colName := GetColumn(req.Opts, req.Lower)
 
-			attachments.Color = "#00ff00"
-			attachments.Text = message
-			Wrangler(tiktok.Config.SlackHook, "Chapter card counts", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	LogToSlack(req.User.Name+" asked me to record in the DB the card count on chapter cards on `"+req.Team+"` trello board in column `"+colName+"`.", tiktok, attachments)
 
-			return c, cronjobs, CronState
-		}
+	err := RecordChapters(tiktok, tiktok.Trello, req.Team, colName)
+	if err != nil {
+		Wrangler(tiktok.Config.SlackHook, "Something went wrong, please check the logs in the log channel #"+tiktok.Config.LogChannel, req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+		return
 	}
 
-	// Grab Card Timing Data on Command
-	if strings.Contains(lowerString, "get card data") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
+	Wrangler(tiktok.Config.SlackHook, "Data recorded!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+// cmdChapterCount - count chapter cards by column
+func cmdChapterCount(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var message string
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to get all the card timing data on `"+teamID+"` trello board", tiktok, attachments)
+	Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+	columnID, colName := GetColumn(req.Opts, req.Lower)
 
-			rtm.SendMessage(rtm.NewOutgoingMessage("Attempting to pull card timing data.\n*Warning* This can take several minutes, please wait patiently. :knuckles_waiting:", ev.Msg.Channel))
+	LogToSlack(req.User.Name+" asked me to add report on chapter cards on `"+req.Team+"` trello board in column `"+colName+"`.", tiktok, attachments)
 
-			if strings.Contains(lowerString, "DB ONLY") {
-				CardPlay(tiktok, opts, ev.Msg.Channel, teamID, false)
-			} else {
-				CardPlay(tiktok, opts, ev.Msg.Channel, teamID, true)
-			}
-			LogToSlack("Completed retrieving card timing on `"+teamID+"` trello board for "+userInfo.Name, tiktok, attachments)
-		}
+	allChapters, totalCards, err := ChapterCount(tiktok, tiktok.Trello, req.Opts, columnID)
+	if err != nil {
+		return
 	}
-
-	// Check That cards have a Theme
-	if strings.Contains(lowerString, "check themes") {
-		var amessage string
-		var temp string
-
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to verify Theme labels on `"+teamID+"` trello board", tiktok, attachments)
-
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
-
-			temp, _ = CheckThemes(tiktok, opts, opts.General.Upcoming)
-			amessage = amessage + temp
-			temp, _ = CheckThemes(tiktok, opts, opts.General.Scoped)
-			amessage = amessage + temp
-			temp, _ = CheckThemes(tiktok, opts, opts.General.ReadyForWork)
-			amessage = amessage + temp
-
-			if amessage != "" {
-				attachments.Color = "#ff0000"
-				attachments.Text = amessage
-				Wrangler(tiktok.Config.SlackHook, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
-			} else {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Hurray all cards have theme labels!", ev.Msg.Channel))
-			}
-		}
+	for _, chapter := range allChapters {
+		message = message + "Cards for " + chapter.ChapterName + " = " + strconv.Itoa(chapter.ChapterCount) + "\n"
 	}
+	message = message + "Total Cards in column " + colName + " = " + strconv.Itoa(totalCards) + "\n"
 
-	// Retrieve Previous Card Description
-	if strings.Contains(lowerString, "description history ") {
-		var cardID string
-		var msgBreak []string
-
-		amessage := ""
-
-		if strings.Contains(strings.ToLower(lowerString), "@"+strings.ToLower(tiktok.Config.BotID)) {
-
-			msgBreak = strings.SplitAfterN(lowerString, " ", 4)
-			if len(msgBreak) != 4 {
-
-				rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-				return c, cronjobs, CronState
+	attachments.Color = "#00ff00"
+	attachments.Text = message
+	Wrangler(tiktok.Config.SlackHook, "Chapter card counts", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			}
-			cardID = msgBreak[3]
+// cmdCardData - grab card timing data on command
+func cmdCardData(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		} else {
+	LogToSlack(req.User.Name+" asked me to get all the card timing data on `"+req.Team+"` trello board", tiktok, attachments)
 
-			msgBreak = strings.SplitAfterN(lowerString, " ", 3)
-			if len(msgBreak) != 3 {
+	req.Reply("Attempting to pull card timing data.\n*Warning* This can take several minutes, please wait patiently. :knuckles_waiting:")
 
-				rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-				return c, cronjobs, CronState
+	CardPlay(tiktok, tiktok.Trello, req.Opts, req.Ev.Msg.Channel, req.Team, !req.Flags["db only"])
 
-			}
-			cardID = msgBreak[2]
-		}
+	LogToSlack("Completed retrieving card timing on `"+req.Team+"` trello board for "+req.User.Name, tiktok, attachments)
+}
 
-		descHistory, err := GetDescHistory(tiktok, cardID)
-		if err != nil {
-			errTrap(tiktok, "Error in retrieve card description history for card "+cardID+" function GetDescHistory in trello.go", err)
-			return c, cronjobs, CronState
-		}
+// cmdCheckThemes - check that cards have a Theme
+func cmdCheckThemes(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var amessage string
+	var temp string
 
-		// Build message
-		if len(descHistory) > 0 {
-			cardTitle = descHistory[0].Data.Card.Name
+	opts := req.Opts
 
-		}
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	LogToSlack(req.User.Name+" asked me to verify Theme labels on `"+req.Team+"` trello board", tiktok, attachments)
 
-		for _, dh := range descHistory {
-			amessage = amessage + "*Date:* " + dh.Date.Format("2006-01-02 15:04:05\n")
-			amessage = amessage + "*Editor:* " + dh.MemberCreator.FullName + "\n"
-			amessage = amessage + "*Desc:* " + dh.Data.Card.Desc + "\n\n"
-		}
+	temp, _ = CheckThemes(tiktok, tiktok.Trello, opts, opts.General.Upcoming)
+	amessage = amessage + temp
+	temp, _ = CheckThemes(tiktok, tiktok.Trello, opts, opts.General.Scoped)
+	amessage = amessage + temp
+	temp, _ = CheckThemes(tiktok, tiktok.Trello, opts, opts.General.ReadyForWork)
+	amessage = amessage + temp
 
-		testPayload.Text = "Description history for requested card (" + cardTitle + "): "
-		testPayload.Channel = userInfo.ID
-		attachments.Color = "#00ff00"
+	if amessage != "" {
+		attachments.Color = "#ff0000"
 		attachments.Text = amessage
-		testPayload.Attachments = append(testPayload.Attachments, attachments)
-
-		err = WranglerDM(tiktok, testPayload)
-		if err != nil {
-			errTrap(tiktok, "Issue sending Direct Slack message to "+userInfo.Name+" when card history was requested.", err)
-			rtm.SendMessage(rtm.NewOutgoingMessage("Issue sending Direct Slack message to "+userInfo.Name+" when card history was requested for `"+cardTitle+"`!", ev.Msg.Channel))
-
-			return c, cronjobs, CronState
-		}
-
-		rtm.SendMessage(rtm.NewOutgoingMessage("I have DM'd you the history description of the card `"+cardTitle+"`!", ev.Msg.Channel))
-
+		TeamWrangler(tiktok, opts, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, attachments)
+	} else {
+		req.Reply("Hurray all cards have theme labels!")
 	}
+}
 
-	// Retrieve Holiday List
-	if strings.Contains(strings.ToLower(lowerString), "company holidays") {
-		var holidaymsg string
-		var year string
-
-		if strings.Contains(strings.ToLower(lowerString), "company holidays all") {
-			year = "0"
-		} else {
-			t := time.Now()
-			year = t.Format("2006")
-		}
-
-		holiday, err := GetHoliday(tiktok, year)
-
-		if err != nil {
-			rtm.SendMessage(rtm.NewOutgoingMessage("Sorry this data was unavailable, please check my logs.", ev.Msg.Channel))
-			if tiktok.Config.LogToSlack {
-				LogToSlack("Error retrieving Holiday dates from DB. "+err.Error(), tiktok, attachments)
-			}
-			return c, cronjobs, CronState
-		}
-
-		for _, h := range holiday {
-			holidayDate := h.Day.Format("01/02/2006")
-			holidaymsg = holidaymsg + holidayDate + " - " + h.Name + "\n"
-		}
+// cmdDescriptionHistory - retrieve previous card descriptions and DM them
+func cmdDescriptionHistory(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var testPayload BotDMPayload
+	var cardTitle string
 
-		attachments.Color = "#0000ff"
-		attachments.Text = holidaymsg
-		Wrangler(tiktok.Config.SlackHook, "Known Holidays for "+year+":", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	amessage := ""
+	cardID := req.Args["cardID"]
 
+	descHistory, err := GetDescHistory(tiktok, cardID)
+	if err != nil {
+		errTrap(tiktok, "Error in retrieve card description history for card "+cardID+" function GetDescHistory in trello.go", err)
+		return
 	}
 
-	// Record todays points
-	if strings.Contains(lowerString, "record points for") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-		} else {
-
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-
-			if Permissions(tiktok, ev.Msg.User, "scrum", api, tiktok.Config.ScrumControlChannel) {
-
-				LogToSlack(userInfo.Name+" asked me to record all board points for "+teamID+".", tiktok, attachments)
-
-				rtm.SendMessage(rtm.NewOutgoingMessage("Permissions accepted. Checking points for ["+teamID+"] this may take a moment.", ev.Msg.Channel))
+	// Build message
+	if len(descHistory) > 0 {
+		cardTitle = descHistory[0].Data.Card.Name
+	}
 
-				opts, _ := LoadConf(tiktok, teamID)
-				sOpts, _ := GetDBSprint(tiktok, teamID)
-				message, valid := GetAllPoints(tiktok, opts, sOpts)
+	for _, dh := range descHistory {
+		amessage = amessage + "*Date:* " + dh.Date.Format("2006-01-02 15:04:05\n")
+		amessage = amessage + "*Editor:* " + dh.MemberCreator.FullName + "\n"
+		amessage = amessage + "*Desc:* " + dh.Data.Card.Desc + "\n\n"
+	}
 
-				if valid {
-					hmessage := "Recording today's sprint points for *" + opts.General.TeamName + "*\n"
-					rtm.SendMessage(rtm.NewOutgoingMessage(hmessage+message, ev.Msg.Channel))
-				}
+	testPayload.Text = "Description history for requested card (" + cardTitle + "): "
+	testPayload.Channel = req.User.ID
+	attachments.Color = "#00ff00"
+	attachments.Text = amessage
+	testPayload.Attachments = append(testPayload.Attachments, attachments)
+
+	err = WranglerDM(tiktok, testPayload)
+	if err != nil {
+		errTrap(tiktok, "Issue sending Direct Slack message to "+req.User.Name+" when card history was requested.", err)
+		req.Reply("Issue sending Direct Slack message to " + req.User.Name + " when card history was requested for `" + cardTitle + "`!")
+		return
+	}
 
-			} else {
+	req.Reply("I have DM'd you the history description of the card `" + cardTitle + "`!")
+}
 
-				smessage = "You are not the boss of me! Permission denied."
-				rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-				LogToSlack(userInfo.Name+" asked me to record todays points for "+teamID+"  but did not have permissions so I ignored them.", tiktok, attachments)
+// cmdHolidays - retrieve holiday list for this year, or every year with `all`
+func cmdHolidays(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var holidaymsg string
+	var year string
+
+	if req.Flags["company holidays all"] {
+		year = "0"
+	} else {
+		t := time.Now()
+		year = t.Format("2006")
+	}
 
-			}
+	holiday, err := tiktok.DB.GetHolidays(year)
+	if err != nil {
+		req.Reply("Sorry this data was unavailable, please check my logs.")
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Error retrieving Holiday dates from DB. "+err.Error(), tiktok, attachments)
 		}
+		return
 	}
 
-	// Counting Cards - Record Theme Card #'s for reporting
-	if strings.Contains(lowerString, "count cards") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-		} else {
-
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to count up the Theme cards on `"+teamID+"` trello board.", tiktok, attachments)
-
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
-
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "Hold please while I count some cards!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-			allThemes, err := CountCards(opts, tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Hrm, something went a foul, please check the logs.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
-
-			// Should Sort Array DESC by points
-			sort.Slice(allThemes, func(i, j int) bool {
-				return allThemes[i].Pts > allThemes[j].Pts
-			})
-
-			amessage := ""
-			for _, s := range allThemes {
-				amessage = amessage + "Total `" + s.Name + "` Cards: " + strconv.Itoa(s.Pts) + "\n"
-			}
-
-			attachments.Color = "#0000ff"
-			attachments.Text = amessage
-			Wrangler(tiktok.Config.SlackHook, "Number of cards per theme (label) in `Un-Scoped` and `Ready for Points` on "+opts.General.TeamName+" board:", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
+	for _, h := range holiday {
+		holidayDate := h.Day.Format("01/02/2006")
+		holidaymsg = holidaymsg + holidayDate + " - " + h.Name
+		if h.HalfDay {
+			holidaymsg = holidaymsg + " (half day)"
 		}
+		if h.Team != "" {
+			holidaymsg = holidaymsg + " [" + h.Team + " only]"
+		}
+		holidaymsg = holidaymsg + "\n"
 	}
 
-	// Add up Theme Points
-	if strings.Contains(lowerString, "theme points") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-
-			var columnID string
-			var colName string
-			var myInt int
-			var myPerc float64
-
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
-
-			// check which column was specified if any
-			lowString := strings.ToLower(lowerString)
-
-			if strings.Contains(lowString, "next sprint") {
-				columnID = opts.General.NextsprintID
-				colName = "Next Sprint"
-			} else if strings.Contains(lowString, "ready for points") {
-				columnID = opts.General.Scoped
-				colName = "Ready for Points"
-			} else if strings.Contains(lowString, "ready for work") {
-				columnID = opts.General.ReadyForWork
-				colName = "Ready for Work"
-			} else {
-				columnID = opts.General.NextsprintID
-				colName = "Next Sprint"
-			}
+	attachments.Color = "#0000ff"
+	attachments.Text = holidaymsg
+	Wrangler(tiktok.Config.SlackHook, "Known Holidays for "+year+":", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			// check if total points was specified for % calcs
-			tP := Between(ev.Msg.Text, "{", "}")
-			if tP != "" {
-				myInt, err = strconv.Atoi(tP)
-				if err != nil {
-					errTrap(tiktok, "Integer Conversion Error", err)
-					rtm.SendMessage(rtm.NewOutgoingMessage("There's an issue with what you specified in { } as total points.  This must be an integer! You specified: "+tP, ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
-			}
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to add up the Theme points on `"+teamID+"` trello board in column `"+colName+"`.", tiktok, attachments)
-
-			allThemes, err := ThemePoints(opts, tiktok, columnID)
-			if err != nil {
-				errTrap(tiktok, "Trello Error", err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("There seems to be an issue with this RetroID in Trello, I can't retrieve this information. ("+err.Error()+")", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+// cmdRecordPoints - record todays points
+func cmdRecordPoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			// Should Sort Array DESC by points
-			sort.Slice(allThemes, func(i, j int) bool {
-				return allThemes[i].Pts > allThemes[j].Pts
-			})
+	LogToSlack(req.User.Name+" asked me to record all board points for "+req.Team+".", tiktok, attachments)
 
-			// Grab ignore labels
-			ignoreLabels, err := GetIgnoreLabels(tiktok, opts.General.BoardID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Gack there was an error! ("+err.Error()+")", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+	req.Reply("Permissions accepted. Checking points for [" + req.Team + "] this may take a moment.")
 
-			// Build Output
-			amessage := ""
-			for _, s := range allThemes {
-				if SliceExists(tiktok, ignoreLabels, s.ID) {
-					if tiktok.Config.DEBUG {
-						fmt.Println("Skipping " + s.Name)
-					}
-				} else {
-					if tP != "" {
-						deci := float64(s.Pts) / float64(myInt)
-						myPerc = deci * 100.0
-						amessage = amessage + "Total `" + s.Name + "` Points: " + strconv.Itoa(s.Pts) + " - (" + strconv.FormatFloat(myPerc, 'f', 0, 64) + "%)\n"
-					} else {
-						amessage = amessage + "Total `" + s.Name + "` Points: " + strconv.Itoa(s.Pts) + "\n"
-					}
-				}
-			}
-			attachments.Color = "#0000ff"
-			attachments.Text = amessage
-			Wrangler(tiktok.Config.SlackHook, "Points per label (Theme)  in `"+colName+"` on "+opts.General.TeamName+" board:", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		}
+	sOpts, _ := tiktok.DB.GetSprint(req.Team)
+	message, valid := GetAllPoints(tiktok, tiktok.Trello, req.Opts, sOpts)
 
+	if valid {
+		hmessage := "Recording today's sprint points for *" + req.Opts.General.TeamName + "*\n"
+		req.Reply(hmessage + message)
 	}
+}
 
-	// Check sprint points for previous sprint (squads and totals)
-	// @tiktok previous sprint points [mcboard] mcboard-07-25-2018
-	if strings.Contains(lowerString, "previous sprint points") {
-
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
+// cmdCountCards - counting cards - record theme card #'s for reporting
+func cmdCountCards(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+	LogToSlack(req.User.Name+" asked me to count up the Theme cards on `"+req.Team+"` trello board.", tiktok, attachments)
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team inside the [ ]\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	Wrangler(tiktok.Config.SlackHook, "Hold please while I count some cards!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-		} else {
-
-			var msgBreak []string
-			var locale int
-			var amessage string
-			var myTotal int
+	allThemes, err := CountCards(req.Opts, tiktok, tiktok.Trello, req.Team)
+	if err != nil {
+		req.Reply("Hrm, something went a foul, please check the logs.")
+		return
+	}
 
-			//break down message
-			if strings.Contains(strings.ToLower(lowerString), "@"+strings.ToLower(tiktok.Config.BotID)) {
+	// Should Sort Array DESC by points
+	sort.Slice(allThemes, func(i, j int) bool {
+		return allThemes[i].Pts > allThemes[j].Pts
+	})
 
-				msgBreak = strings.SplitAfterN(lowerString, " ", 6)
-				if len(msgBreak) != 6 {
+	amessage := ""
+	for _, s := range allThemes {
+		amessage = amessage + "Total `" + s.Name + "` Cards: " + strconv.Itoa(s.Pts) + "\n"
+	}
 
-					rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-					return c, cronjobs, CronState
+	attachments.Color = "#0000ff"
+	attachments.Text = amessage
+	Wrangler(tiktok.Config.SlackHook, "Number of cards per theme (label) in `Un-Scoped` and `Ready for Points` on "+req.Opts.General.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-				}
-				locale = 5
+// cmdThemePoints - add up theme points in a column, with percentages if total points were given
+func cmdThemePoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var columnID string
+	var colName string
+	var myPerc float64
+
+	opts := req.Opts
+
+	Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+
+	// check which column was specified if any
+	if strings.Contains(req.Lower, "next sprint") {
+		columnID = opts.General.NextsprintID
+		colName = "Next Sprint"
+	} else if strings.Contains(req.Lower, "ready for points") {
+		columnID = opts.General.Scoped
+		colName = "Ready for Points"
+	} else if strings.Contains(req.Lower, "ready for work") {
+		columnID = opts.General.ReadyForWork
+		colName = "Ready for Work"
+	} else {
+		columnID = opts.General.NextsprintID
+		colName = "Next Sprint"
+	}
 
-			} else {
+	// total points for % calcs, 0 if not specified
+	myInt := req.Int("total points")
 
-				msgBreak = strings.SplitAfterN(lowerString, " ", 5)
-				if len(msgBreak) != 5 {
+	LogToSlack(req.User.Name+" asked me to add up the Theme points on `"+req.Team+"` trello board in column `"+colName+"`.", tiktok, attachments)
 
-					rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-					return c, cronjobs, CronState
+	allThemes, err := ThemePoints(opts, tiktok, tiktok.Trello, columnID)
+	if err != nil {
+		errTrap(tiktok, "Trello Error", err)
+		req.Reply("There seems to be an issue with this RetroID in Trello, I can't retrieve this information. (" + err.Error() + ")")
+		return
+	}
 
-				}
-				locale = 4
+	// Should Sort Array DESC by points
+	sort.Slice(allThemes, func(i, j int) bool {
+		return allThemes[i].Pts > allThemes[j].Pts
+	})
 
-			}
+	// Grab ignore labels
+	ignoreLabels, err := tiktok.DB.GetIgnoreLabels(opts.General.BoardID)
+	if err != nil {
+		req.Reply("Gack there was an error! (" + err.Error() + ")")
+		return
+	}
 
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID in `previous sprint points` command "+teamID, err)
-				return c, cronjobs, CronState
+	// Build Output
+	amessage := ""
+	for _, s := range allThemes {
+		if SliceExists(tiktok, ignoreLabels, s.ID) {
+			if tiktok.Config.DEBUG {
+				fmt.Println("Skipping " + s.Name)
 			}
-
-			sprintLow := strings.ToLower(msgBreak[locale])
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to retrieve the squad points for previous sprint `"+sprintLow+"` on `"+teamID+"` trello board", tiktok, attachments)
-
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "Let me grab the point data for `"+sprintLow+"`!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-			myTotal = 0
-			SprintPoints, err := GetPreviousSprintPoints(tiktok, sprintLow)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Something went totally wrong, please check the logs.", ev.Msg.Channel))
+		} else {
+			if myInt != 0 {
+				deci := float64(s.Pts) / float64(myInt)
+				myPerc = deci * 100.0
+				amessage = amessage + "Total `" + s.Name + "` Points: " + strconv.Itoa(s.Pts) + " - (" + strconv.FormatFloat(myPerc, 'f', 0, 64) + "%)\n"
 			} else {
-				amessage = ""
-				for _, s := range SprintPoints {
-					amessage = amessage + "Total `" + s.SquadName + "` Points: " + strconv.Itoa(s.SprintPoints) + "\n"
-					myTotal = myTotal + s.SprintPoints
-				}
-				amessage = amessage + "Total Sprint Points:" + strconv.Itoa(myTotal)
-				attachments.Color = "#0000ff"
-				attachments.Text = amessage
-				Wrangler(tiktok.Config.SlackHook, "Points per squad for sprint `"+sprintLow+"` on "+opts.General.TeamName+" board:", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+				amessage = amessage + "Total `" + s.Name + "` Points: " + strconv.Itoa(s.Pts) + "\n"
 			}
 		}
-
 	}
+	attachments.Color = "#0000ff"
+	attachments.Text = amessage
+	Wrangler(tiktok.Config.SlackHook, "Points per label (Theme)  in `"+colName+"` on "+opts.General.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-	// Add up squad points
-	if strings.Contains(lowerString, "squad points") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
+// cmdPreviousSprintPoints - check sprint points for previous sprint (squads and totals)
+// @tiktok previous sprint points [mcboard] mcboard-07-25-2018
+func cmdPreviousSprintPoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var amessage string
+	var myTotal int
 
-			var columnID string
-			var colName string
+	sprintLow := strings.ToLower(req.Args["SprintName"])
+	LogToSlack(req.User.Name+" asked me to retrieve the squad points for previous sprint `"+sprintLow+"` on `"+req.Team+"` trello board", tiktok, attachments)
 
-			attachments.Color = ""
-			attachments.Text = ""
-			Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	Wrangler(tiktok.Config.SlackHook, "Let me grab the point data for `"+sprintLow+"`!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
 
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				return c, cronjobs, CronState
-			}
+	SprintPoints, err := tiktok.DB.GetPreviousSprintPoints(sprintLow)
+	if err != nil {
+		req.Reply("Something went totally wrong, please check the logs.")
+		return
+	}
 
-			// check which column was specified if any
-			lowString := strings.ToLower(lowerString)
-
-			if strings.Contains(lowString, "next sprint") {
-				columnID = opts.General.NextsprintID
-				colName = "Next Sprint"
-			} else if strings.Contains(lowString, "ready for points") {
-				columnID = opts.General.Scoped
-				colName = "Ready for Points"
-			} else if strings.Contains(lowString, "ready for work") {
-				columnID = opts.General.ReadyForWork
-				colName = "Ready for Work"
-			} else if strings.Contains(lowString, "working") {
-				columnID = opts.General.Working
-				colName = "Working"
-			} else {
-				columnID = opts.General.NextsprintID
-				colName = "Next Sprint"
-			}
+	for _, s := range SprintPoints {
+		amessage = amessage + "Total `" + s.SquadName + "` Points: " + strconv.Itoa(s.SprintPoints) + "\n"
+		myTotal = myTotal + s.SprintPoints
+	}
+	amessage = amessage + "Total Sprint Points:" + strconv.Itoa(myTotal)
+	attachments.Color = "#0000ff"
+	attachments.Text = amessage
+	Wrangler(tiktok.Config.SlackHook, "Points per squad for sprint `"+sprintLow+"` on "+req.Opts.General.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-			LogToSlack(userInfo.Name+" asked me to add up the squad points on `"+teamID+"` trello board in column `"+colName+"`.", tiktok, attachments)
+// cmdSquadPoints - add up squad points in a column
+func cmdSquadPoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var columnID string
+	var colName string
+
+	opts := req.Opts
+
+	Wrangler(tiktok.Config.SlackHook, "One sec, let me add that up!", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+
+	// check which column was specified if any
+	if strings.Contains(req.Lower, "next sprint") {
+		columnID = opts.General.NextsprintID
+		colName = "Next Sprint"
+	} else if strings.Contains(req.Lower, "ready for points") {
+		columnID = opts.General.Scoped
+		colName = "Ready for Points"
+	} else if strings.Contains(req.Lower, "ready for work") {
+		columnID = opts.General.ReadyForWork
+		colName = "Ready for Work"
+	} else if strings.Contains(req.Lower, "working") {
+		columnID = opts.General.Working
+		colName = "Working"
+	} else {
+		columnID = opts.General.NextsprintID
+		colName = "Next Sprint"
+	}
 
-			allSquads, nonPoints, err := SquadPoints(columnID, opts, tiktok)
+	LogToSlack(req.User.Name+" asked me to add up the squad points on `"+req.Team+"` trello board in column `"+colName+"`.", tiktok, attachments)
 
-			amessage := ""
-			for _, s := range allSquads {
-				if opts.General.BoardID == s.BoardID {
-					amessage = amessage + "Total `" + s.Squadname + "` Points: " + strconv.Itoa(s.SquadPts) + "\n"
-				}
-			}
-			amessage = amessage + "Total Points not assigned to a squad: " + strconv.Itoa(nonPoints) + "\n"
-			attachments.Color = "#0000ff"
-			attachments.Text = amessage
-			Wrangler(tiktok.Config.SlackHook, "Points per squad in `"+colName+"` on "+opts.General.TeamName+" board:", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	allSquads, nonPoints, err := SquadPoints(columnID, opts, tiktok, tiktok.Trello)
+	if err != nil {
+		errTrap(tiktok, "SquadPoints function error returned in `botactions.go`", err)
+	}
 
+	amessage := ""
+	for _, s := range allSquads {
+		if opts.General.BoardID == s.BoardID {
+			amessage = amessage + "Total `" + s.Squadname + "` Points: " + strconv.Itoa(s.SquadPts) + "\n"
 		}
 	}
+	amessage = amessage + "Total Points not assigned to a squad: " + strconv.Itoa(nonPoints) + "\n"
+	attachments.Color = "#0000ff"
+	attachments.Text = amessage
+	Wrangler(tiktok.Config.SlackHook, "Points per squad in `"+colName+"` on "+opts.General.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-	// List retro board
-	if strings.Contains(lowerString, "retro board") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
+// cmdRetroBoard - link the current sprint retro board
+func cmdRetroBoard(tiktok *TikTokConf, req *CommandRequest) {
+	sOpts, err := tiktok.DB.GetSprint(req.Team)
+	if err != nil {
+		req.Reply("Sorry I couldn't find what you were asking for! - ")
+		return
+	}
 
-			message := ListAllTOML(tiktok)
+	allTheThings, err := tiktok.Trello.RetrieveAll(sOpts.RetroID, "none")
+	if err != nil {
+		errTrap(tiktok, "Error from `RetrieveAll` getting board info in `botactions.go` retro board command ", err)
+		req.Reply("There seems to be an issue with this RetroID in Trello, I can't retrieve this information. Please see logs.")
+		return
+	}
 
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+	req.Reply("The current sprint reto board for `" + sOpts.SprintName + "` is <" + allTheThings.ShortURL + "|" + allTheThings.Name + ">")
+}
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+// cmdListPR - list all cards in PR Column
+func cmdListPR(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		} else {
-			sOpts, err := GetDBSprint(tiktok, teamID)
-			if err != nil {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Sorry I couldn't find what you were asking for! - ", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+	LogToSlack(req.User.Name+" asked me to list the PR's on `"+req.Team+"` trello board.", tiktok, attachments)
 
-			allTheThings, err := RetrieveAll(tiktok, sOpts.RetroID, "none")
-			if err != nil {
-				errTrap(tiktok, "Error from `RetrieveAll` getting board info in `botactions.go` retro board command ", err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("There seems to be an issue with this RetroID in Trello, I can't retrieve this information. Please see logs.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+	output, err := PRSummary(req.Opts, tiktok, tiktok.Trello)
+	if err != nil {
+		errTrap(tiktok, "PRSummary function error returned in `botactions.go`", err)
+		return
+	}
 
-			message := "The current sprint reto board for `" + sOpts.SprintName + "` is <" + allTheThings.ShortURL + "|" + allTheThings.Name + ">"
-			rtm.SendMessage(rtm.NewOutgoingMessage(message, ev.Msg.Channel))
-		}
+	if output == "" {
+		req.Reply("Dumping list of PRs to main Slack channel, per your request.")
+	} else {
+		req.Reply(output)
 	}
+}
 
-	// List all cards in PR Column
-	ls := strings.ToLower(lowerString)
-	if strings.Contains(ls, "list pr") || strings.Contains(ls, "open pr") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which board you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-			} else {
-				userInfo, err := api.GetUserInfo(ev.Msg.User)
-				if err != nil {
-					errTrap(tiktok, "api.GetUserInfo function error returned in `botactions.go`", err)
-				}
-				LogToSlack(userInfo.Name+" asked me to list the PR's on `"+teamID+"` trello board.", tiktok, attachments)
+// cmdDupeBoard - dupe a board
+func cmdDupeBoard(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-				output, err := PRSummary(opts, tiktok)
-				if err != nil {
-					errTrap(tiktok, "PRSummary function error returned in `botactions.go`", err)
-					return c, cronjobs, CronState
-				}
+	req.Reply("Hold please, attempting to dupe it up.")
 
-				if output == "" {
-					rtm.SendMessage(rtm.NewOutgoingMessage("Dumping list of PRs to main Slack channel, per your request.", ev.Msg.Channel))
-				} else {
-					rtm.SendMessage(rtm.NewOutgoingMessage(output, ev.Msg.Channel))
+	LogToSlack(req.User.Name+" asked me to make a dupe of the `"+req.Team+"` trello board, so I'm doing that.", tiktok, attachments)
 
-				}
-
-			}
-		}
+	allTheThings, err := tiktok.Trello.RetrieveAll(req.Opts.General.BoardID, "none")
+	if err != nil {
+		errTrap(tiktok, "Error from `RetrieveAll` getting board info in `botactions.go` dupe trello board command ", err)
+		req.Reply("There seems to be an issue with this request in Trello, I can't retrieve this information. Please see logs.")
+		return
 	}
 
-	// Dupe a board
-	if strings.Contains(lowerString, "dupe trello board") || strings.Contains(lowerString, "copy trello board") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which board you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-			} else {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Hold please, attempting to dupe it up.", ev.Msg.Channel))
+	rightnow := time.Now().Local()
+	nameDate := rightnow.Format("01-02-06")
+	dupeName := "DUPE-" + nameDate + ": " + allTheThings.Name
+	output, _ := DupeTrelloBoard(allTheThings.ID, dupeName, req.Opts.General.TrelloOrg, tiktok)
 
-				userInfo, _ := api.GetUserInfo(ev.Msg.User)
-				LogToSlack(userInfo.Name+" asked me to make a dupe of the `"+teamID+"` trello board, so I'm doing that.", tiktok, attachments)
+	if tiktok.Config.DEBUG {
+		fmt.Println(output)
+	}
+	if tiktok.Config.LogToSlack {
+		LogToSlack(output, tiktok, attachments)
+	}
 
-				allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "none")
-				if err != nil {
-					errTrap(tiktok, "Error from `RetrieveAll` getting board info in `botactions.go` dupe trello board command ", err)
-					rtm.SendMessage(rtm.NewOutgoingMessage("There seems to be an issue with this request in Trello, I can't retrieve this information. Please see logs.", ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
+	req.Reply(output)
+}
 
-				rightnow := time.Now().Local()
-				nameDate := rightnow.Format("01-02-06")
-				dupeName := "DUPE-" + nameDate + ": " + allTheThings.Name
-				output, _ := DupeTrelloBoard(allTheThings.ID, dupeName, opts.General.TrelloOrg, tiktok)
+// cmdReloadCron - reload cron jobs from file
+func cmdReloadCron(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var err error
 
-				if tiktok.Config.DEBUG {
-					fmt.Println(output)
-				}
-				if tiktok.Config.LogToSlack {
-					LogToSlack(output, tiktok, attachments)
-				}
+	LogToSlack(req.User.Name+" asked me to re-load all the CRONJobs, so I'm attempting that.", tiktok, attachments)
 
-				rtm.SendMessage(rtm.NewOutgoingMessage(output, ev.Msg.Channel))
+	req.Cron.Stop()
+	req.CronState = "Halted"
 
-			}
-		}
+	req.Cronjobs, req.Cron, err = CronLoad(tiktok)
+	if err != nil {
+		errTrap(tiktok, "CRON Jobs failed to load due to file error.", err)
+		req.Reply("CRON Jobs failed to load due to file error.")
+		return
 	}
 
-	// Reload Cron Jobs from file
-	if strings.Contains(lowerString, "reload cron") || strings.Contains(lowerString, "re-load cron") || strings.Contains(lowerString, "reload all cron") {
-		userInfo, err := api.GetUserInfo(ev.Msg.User)
-		LogToSlack(userInfo.Name+" asked me to re-load all the CRONJobs, so I'm attempting that.", tiktok, attachments)
+	if tiktok.Config.DEBUG {
+		fmt.Println("CRON Jobs were re-loaded from file!")
+	}
+	req.Reply("CRON Jobs were re-loaded!")
+	req.CronState = "Running"
+}
 
-		c.Stop()
-		CronState = "Halted"
+// cmdListCron - list cron jobs and DM them
+func cmdListCron(tiktok *TikTokConf, req *CommandRequest) {
+	var message string
+	var attachments Attachment
+	var testPayload BotDMPayload
 
-		cronjobs, c, err = CronLoad(tiktok)
+	if req.CronState == "Not Loaded" {
+		req.Reply("The Cron State is currently not loaded.  Please Reload it!")
+		return
+	}
 
+	message = "Existing Cron State is: `" + req.CronState + "`\n"
+	message = message + "```"
+	for _, cr := range req.Cronjobs.Cronjob {
+		opts, err := LoadConf(tiktok, cr.Config)
 		if err != nil {
-			errTrap(tiktok, "CRON Jobs failed to load due to file error.", err)
-			rtm.SendMessage(rtm.NewOutgoingMessage("CRON Jobs failed to load due to file error.", ev.Msg.Channel))
-		} else {
-			if tiktok.Config.DEBUG {
-				fmt.Println("CRON Jobs were re-loaded from file!")
-			}
-			rtm.SendMessage(rtm.NewOutgoingMessage("CRON Jobs were re-loaded!", ev.Msg.Channel))
-			CronState = "Running"
+			errTrap(tiktok, "Load Conf Error for TeamID "+cr.Config, err)
 		}
+		message = message + cr.Timing + " - " + opts.General.TeamName + " - " + cr.Action + "\n"
 	}
+	message = message + "```"
 
-	// List Cron Jobs from file
-	if strings.Contains(lowerString, "list all cronjobs") || strings.Contains(lowerString, "show me all cronjobs") || strings.Contains(lowerString, "list cronjobs") {
-		var message string
-		var attachments Attachment
+	testPayload.Text = "Current Running Cron Jobs"
+	testPayload.Channel = req.User.ID
+	attachments.Color = "#0c15dd"
+	attachments.Text = message
+	testPayload.Attachments = append(testPayload.Attachments, attachments)
 
-		if CronState == "Not Loaded" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("The Cron State is currently not loaded.  Please Reload it!", ev.Msg.Channel))
-			return c, cronjobs, CronState
-		}
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-
-		message = "Existing Cron State is: `" + CronState + "`\n"
-		message = message + "```"
-		for _, cr := range cronjobs.Cronjob {
-			opts, err := LoadConf(tiktok, cr.Config)
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-			}
-			message = message + cr.Timing + " - " + opts.General.TeamName + " - " + cr.Action + "\n"
-		}
-		message = message + "```"
-
-		testPayload.Text = "Current Running Cron Jobs"
-		testPayload.Channel = userInfo.ID
-		attachments.Color = "#0c15dd"
-		attachments.Text = message
-		testPayload.Attachments = append(testPayload.Attachments, attachments)
-
-		err := WranglerDM(tiktok, testPayload)
-		if err != nil {
-			return c, cronjobs, CronState
-		}
-
-		rtm.SendMessage(rtm.NewOutgoingMessage("I have DM'd you the current cron jobs, lucky you!", ev.Msg.Channel))
+	err := WranglerDM(tiktok, testPayload)
+	if err != nil {
+		return
 	}
 
-	// New Sprint Setup
-	if strings.Contains(lowerString, "start a new sprint") {
+	req.Reply("I have DM'd you the current cron jobs, lucky you!")
+}
 
-		var rboard bool
+// cmdJobHistory - recent cron runs for a board and any job that's missed its schedule
+func cmdJobHistory(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		smessage = ""
+	message, overdue, err := CronHistory(tiktok, req.Cronjobs, req.Team, 7)
+	if err != nil {
+		req.Reply("Sorry I couldn't read the cron history, please check my logs.")
+		return
+	}
 
-		boardID := Between(ev.Msg.Text, "[", "]")
-		if boardID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-
-			LogToSlack(userInfo.Name+" asked me to run a new sprint for the "+boardID+" configuration.", tiktok, attachments)
-
-			if Permissions(tiktok, ev.Msg.User, "admin", api, tiktok.Config.ScrumControlChannel) {
-				opts, err := LoadConf(tiktok, boardID)
-				if err != nil {
-					errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-					rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+boardID+".toml) you asked for!.", ev.Msg.Channel))
-				} else {
-					if strings.Contains(lowerString, "suppress retro") {
-						rboard = true
-						smessage = smessage + "I will supress creation of a Retro board\n"
-					} else {
-						rboard = false
-					}
-					smessage = smessage + "Permissions accepted, attempting to Sprint it up!"
-					rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-					returnMsg, _ := Sprint(opts, tiktok, rboard)
-					rtm.SendMessage(rtm.NewOutgoingMessage(returnMsg, ev.Msg.Channel))
-				}
-			} else {
-				smessage = "You are not the boss of me! Permission denied."
-				rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-				if tiktok.Config.LogToSlack {
-					LogToSlack(userInfo.Name+" does not have the appropriate permissions and was told to `get bent`.", tiktok, attachments)
-				}
-			}
-		}
+	attachments.Color = "#0000ff"
+	if overdue > 0 {
+		attachments.Color = "#ff0000"
 	}
+	attachments.Text = message
+	Wrangler(tiktok.Config.SlackHook, "Cron job history for the last 7 days on "+req.Opts.General.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-	// STOP Cron Jobs
-	if strings.Contains(lowerString, "stop all cron") || strings.Contains(lowerString, "shutdown all cron") || strings.Contains(lowerString, "halt all cron") {
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
+// cmdRefreshBoard - drop the cached board snapshot and reload it
+func cmdRefreshBoard(tiktok *TikTokConf, req *CommandRequest) {
+	allTheThings, err := RefreshBoard(tiktok, tiktok.Trello, req.Opts.General.BoardID)
+	if err != nil {
+		req.Reply("Sorry I couldn't reload the `" + req.Team + "` board from trello, please check my logs.")
+		return
+	}
 
-		if Permissions(tiktok, ev.Msg.User, "scrum", api, tiktok.Config.ScrumControlChannel) {
-			smessage = "Permissions accepted. Halting all Cron Jobs!"
-			rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-			LogToSlack(userInfo.Name+" asked me to halt all Cron Jobs so I did.", tiktok, attachments)
-			CronState = "Halted"
-			c.Stop()
-		} else {
-			smessage = "You are not the boss of me! Permission denied."
-			rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-			LogToSlack(userInfo.Name+" asked me to halt all Cron Jobs but did not have permissions so I ignored them.", tiktok, attachments)
-		}
+	req.Reply("Reloaded the *" + req.Opts.General.TeamName + "* board, " + strconv.Itoa(len(allTheThings.Cards)) + " cards.")
+}
 
-	}
+// cmdNewSprint - new sprint setup
+func cmdNewSprint(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var smessage string
 
-	// Shutdown
-	if strings.Contains(lowerString, "shutdown please") {
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	rboard := req.Flags["suppress retro"]
 
-		if Permissions(tiktok, ev.Msg.User, "admin", api, tiktok.Config.AdminSlackChannel) {
-			smessage = "Permissions accepted. Okay logging off bye!"
-			rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-			LogToSlack(userInfo.Name+" asked me to shutdown, so I am.", tiktok, attachments)
-			duration := time.Duration(4) * time.Second
-			time.Sleep(duration)
-			os.Exit(0)
-		} else {
-			smessage = "You are not the boss of me! Permission denied."
-			rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-			LogToSlack(userInfo.Name+" asked me to shut down, but did not have permissions so I ignored them.", tiktok, attachments)
+	if req.Flags["dry run"] {
+		LogToSlack(req.User.Name+" asked me for a dry run of a new sprint for the "+req.Team+" configuration.", tiktok, attachments)
+		req.Reply("Permissions accepted, working out what a new sprint would do.  Nothing will be changed!")
 
+		plan, err := PlanSprint(req.Opts, tiktok, tiktok.Trello, rboard)
+		if err != nil {
+			req.Reply("I couldn't work out the sprint plan for `" + req.Team + "`, please check the logs.")
+			return
 		}
 
+		attachments.Color = "#ffaa00"
+		attachments.Text = plan.Summary()
+		Wrangler(tiktok.Config.SlackHook, "*Dry Run* - new sprint plan for "+plan.TeamName+" board:", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+		return
 	}
 
-	// Build a config file
-	if strings.Contains(lowerString, "build a configuration file") {
-		boardID := Between(ev.Msg.Text, "[", "]")
-		BuildConfig(boardID, tiktok, ev.Msg.User, api)
-		rtm.SendMessage(rtm.NewOutgoingMessage("Okay, I Direct Messaged your config to you.", ev.Msg.Channel))
-	}
+	LogToSlack(req.User.Name+" asked me to run a new sprint for the "+req.Team+" configuration.", tiktok, attachments)
 
-	// TROLL the board
-	if strings.Contains(lowerString, "troll team") || strings.Contains(lowerString, "troll board") {
+	if rboard {
+		smessage = smessage + "I will supress creation of a Retro board\n"
+	}
+	smessage = smessage + "Permissions accepted, attempting to Sprint it up!"
+	req.Reply(smessage)
 
-		attachments.Text = ""
-		attachments.Color = ""
+	returnMsg, _ := Sprint(req.Opts, tiktok, tiktok.Trello, rboard)
+	req.Reply(returnMsg)
+}
 
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+// cmdUndoSprint - undo the last sprint rollover on a board
+func cmdUndoSprint(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to Troll the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, running alerting on board for team "+teamID+".", ev.Msg.Channel))
+	LogToSlack(req.User.Name+" asked me to undo the last sprint for the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Permissions accepted, putting the " + req.Team + " board back the way it was before the last sprint started.")
 
-				_, _ = AlertRunner(opts, tiktok)
-			}
-		}
+	message, err := UndoLastSprint(tiktok, tiktok.Trello, req.Opts)
+	if err != nil && message == "" {
+		req.Reply("Sorry, I couldn't undo the last sprint: " + err.Error())
+		return
 	}
+	req.Reply(message)
+}
 
-	// Clean BackLog (separate from archiving)
-	if strings.Contains(lowerString, "clean the backlog") {
+// cmdStopCron - stop all cron jobs
+func cmdStopCron(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		attachments.Text = ""
-		attachments.Color = ""
+	req.Reply("Permissions accepted. Halting all Cron Jobs!")
+	LogToSlack(req.User.Name+" asked me to halt all Cron Jobs so I did.", tiktok, attachments)
+	req.CronState = "Halted"
+	req.Cron.Stop()
+}
 
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+// cmdShutdown - log off slack and exit
+func cmdShutdown(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to clean the BackLog on the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, cleaning the backlog for team "+teamID+".", ev.Msg.Channel))
+	req.Reply("Permissions accepted. Okay logging off bye!")
+	LogToSlack(req.User.Name+" asked me to shutdown, so I am.", tiktok, attachments)
+	duration := time.Duration(4) * time.Second
+	time.Sleep(duration)
+	_ = tiktok.DB.Close()
+	os.Exit(0)
+}
 
-				err = CleanBackLog(opts, tiktok)
-				if err != nil {
-					errTrap(tiktok, "Error in `CleanBackLog` process run by slack command request.", err)
-				}
-			}
-		}
-	}
+// cmdBuildConfig - build a config file for a trello board and DM it
+func cmdBuildConfig(tiktok *TikTokConf, req *CommandRequest) {
+	BuildConfig(req.Args["<trello board ID>"], tiktok, req.Ev.Msg.User, req.API)
+	req.Reply("Okay, I Direct Messaged your config to you.")
+}
 
-	// Archive the BackLog
-	if strings.Contains(lowerString, "archive the backlog") {
+// cmdTroll - TROLL the board
+func cmdTroll(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		attachments.Text = ""
-		attachments.Color = ""
+	LogToSlack(req.User.Name+" asked me to Troll the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, running alerting on board for team " + req.Team + ".")
 
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	_, _ = AlertRunner(req.Opts, tiktok, tiktok.Trello)
+}
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to archive old cards in the `BackLog` on the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, archiving cards older then "+strconv.Itoa(opts.General.BackLogDays)+" days in the `BackLog` for team "+teamID+".", ev.Msg.Channel))
+// cmdBackupBoard - full JSON export of a board to BackupDir
+func cmdBackupBoard(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-				err = ArchiveBacklog(tiktok, opts)
-				if err != nil {
-					errTrap(tiktok, "Error in `ArchiveBacklog` process run by slack command request.", err)
-				}
-			}
-		}
+	LogToSlack(req.User.Name+" asked me to back up the "+req.Team+" board.", tiktok, attachments)
+	req.Reply("Okay, backing up the board for team " + req.Team + ", this can take a minute.")
+
+	file, err := BackupBoard(tiktok, req.Opts, req.Team)
+	if err != nil {
+		req.Reply("Sorry, the backup of the " + req.Team + " board failed: " + err.Error())
+		return
 	}
+	req.Reply("Backed up the " + req.Team + " board to `" + file + "`")
+}
 
-	// Run board archiving
-	if strings.Contains(lowerString, "archiving on board") {
+// cmdBlockers - list the dependency chains on a board
+func cmdBlockers(tiktok *TikTokConf, req *CommandRequest) {
+	message, err := BlockerChains(tiktok, tiktok.Trello, req.Opts)
+	if err != nil {
+		req.Reply("Sorry, I couldn't load the " + req.Team + " board from trello.")
+		return
+	}
+	if message == "" {
+		req.Reply("Nothing on the " + req.Team + " board is blocked by another card.")
+		return
+	}
 
-		attachments.Text = ""
-		attachments.Color = ""
+	req.Reply("Here are the blocked cards on the " + req.Team + " board:\n" + message)
+}
 
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+// cmdDueDates - due date alerting on a board
+func cmdDueDates(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to run archiving on the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, running archiving on board for team "+teamID+".", ev.Msg.Channel))
+	LogToSlack(req.User.Name+" asked me to check due dates on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, checking due dates on board for team " + req.Team + ".")
 
-				_, _ = CleanDone(opts, tiktok)
-			}
-		}
+	_, err := DueDates(tiktok, tiktok.Trello, req.Opts, req.Team)
+	if err != nil {
+		errTrap(tiktok, "Error in `DueDates` process run by slack command request.", err)
 	}
+}
 
-	// Scan for lagging PR
-	if strings.Contains(lowerString, "scan for pr") {
-
-		attachments.Text = ""
-		attachments.Color = ""
-
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+// cmdSetAvailability - set someone's availability for a team's next sprint
+func cmdSetAvailability(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to scan for stale PR's on the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, scanning for stale PR's on board for team "+teamID+".", ev.Msg.Channel))
+	change, ok := parseAvailability(req.Args["availability"])
+	if !ok {
+		req.Reply("I didn't understand that availability.  Try: ```@" + tiktok.Config.BotName + " " + req.Command.Example + "```\nYou can use `pto <days>`, `part-time <percent>`, `full-time`, `on-call`, `not on-call` and `for @someone`.")
+		return
+	}
 
-				returnMsg, _ := StalePRcards(opts, tiktok)
-				rtm.SendMessage(rtm.NewOutgoingMessage(returnMsg, ev.Msg.Channel))
-			}
+	slackID := req.User.ID
+	if change.slackID != "" && change.slackID != req.User.ID {
+		if !Permissions(tiktok, req.Ev.Msg.User, "scrum", req.API, tiktok.Config.ScrumControlChannel) {
+			req.Reply("You can only set your own availability, setting someone else's needs scrum permissions.")
+			LogToSlack(req.User.Name+" asked me to set availability for "+change.slackID+" but did not have permissions so I ignored them.", tiktok, attachments)
+			return
 		}
+		slackID = change.slackID
 	}
 
-	// Sync board points to custom field and Alert on changing points
-	if strings.Contains(lowerString, "sync points") {
-
-		attachments.Text = ""
-		attachments.Color = ""
+	user, err := tiktok.DB.GetUser("slackid", slackID)
+	if err != nil || user.ID == 0 {
+		req.Reply("<@" + slackID + "> isn't registered with me yet, use `add me` first.")
+		return
+	}
 
-		teamID = Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand which team you want, sorry.", ev.Msg.Channel))
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	cal := TeamCalendar(tiktok, req.Opts)
+	start := NextSprintStart(tiktok, req.Opts, cal)
+	teamID := strings.ToLower(req.Opts.General.Sprintname)
 
-			if err != nil {
-				errTrap(tiktok, "Load Conf Error for TeamID "+teamID, err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the team config file ("+teamID+".toml) you asked for!.", ev.Msg.Channel))
-			} else {
-				LogToSlack(userInfo.Name+" asked me to syncronize points on the "+teamID+" configuration.", tiktok, attachments)
-				rtm.SendMessage(rtm.NewOutgoingMessage("Okay, syncronizing points on board for team "+teamID+".", ev.Msg.Channel))
+	people, err := tiktok.DB.GetTeamAvailability(teamID, start, req.Opts.General.SprintDuration)
+	if err != nil {
+		req.Reply("Sorry, I couldn't read availability for " + req.Team + ", please check my logs.")
+		return
+	}
 
-				_ = PointCleanup(opts, tiktok, teamID)
-			}
+	a := Availability{UserID: user.ID, Name: user.Name, SlackID: user.SlackID, Team: teamID, PartTime: 100}
+	for _, p := range people {
+		if p.UserID == user.ID {
+			a = p
 		}
 	}
+	a.SprintStart = start
+	if change.setPTO {
+		a.PTODays = change.pto
+	}
+	if change.setPartTime {
+		a.PartTime = change.partTime
+	}
+	if change.setOnCall {
+		a.OnCall = change.onCall
+	}
 
-	// List all manageable trello boards and tomls
-	if strings.Contains(lowerString, "is your trello board") || strings.Contains(lowerString, "list available boards") || strings.Contains(lowerString, "list all boards") || strings.Contains(lowerString, "show me all boards") || strings.Contains(lowerString, "list available trello boards") || strings.Contains(lowerString, "list all trello boards") {
-
-		var attachments Attachment
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	if err := tiktok.DB.PutAvailability(a); err != nil {
+		req.Reply("Sorry, I couldn't save that availability, please check my logs.")
+		return
+	}
 
-		message := ListAllTOML(tiktok)
+	LogToSlack(req.User.Name+" set availability for "+user.Name+" on "+req.Team+" for the sprint starting "+start.Format("01/02/2006")+": "+availabilityLine(a), tiktok, attachments)
+	req.Reply("Got it, " + user.Name + " for the " + req.Team + " sprint starting " + start.Format("01/02/2006") + ": " + availabilityLine(a) + ".")
+}
 
-		attachments.Color = "#0000CC"
-		attachments.Text = message
+// cmdCapacity - next sprint capacity against the points planned for it
+func cmdCapacity(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		Wrangler(tiktok.Config.SlackHook, "Hey "+userInfo.Name+", I manage the following boards: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	LogToSlack(req.User.Name+" asked me for the next sprint capacity of "+req.Team+".", tiktok, attachments)
 
+	plan, err := PlanSprint(req.Opts, tiktok, tiktok.Trello, true)
+	if err != nil {
+		req.Reply("Sorry, I couldn't load the " + req.Team + " board, please check my logs.")
+		return
 	}
 
-	// Add me to user DB
-	if strings.Contains(lowerString, "add me") || strings.Contains(lowerString, "register me") {
-		var newUserData UserData
-		var tempSID string
-		var myPayload BotDMPayload
-
-		userData := Between(ev.Msg.Text, "[", "]")
-		if userData == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I can help you register here's how:\n```add me [email,trello id,github id]```\n`Do not use quotes anywhere.`\nExample: ```@"+tiktok.Config.BotName+" add me [some.one@mydomain.com,someone12,someone-ea]```", ev.Msg.Channel))
-		} else {
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-
-			LogToSlack(userInfo.Name+" asked me to to register them in the user DB.", tiktok, attachments)
-
-			brokeOut := strings.Split(userData, ",")
-			if len(brokeOut) == 3 {
-				newUserData.Name = strings.Replace(userInfo.Name, ".", " ", -1)
-				newUserData.SlackID = userInfo.ID
-				newUserData.Email = strings.ToLower(brokeOut[0])
-				newUserData.Trello = strings.ToLower(brokeOut[1])
-				newUserData.Github = strings.ToLower(brokeOut[2])
-
-				// check if already registered
-				db, status, err := ConnectDB(tiktok, "tiktok")
-				if err != nil {
-					if tiktok.Config.DEBUG {
-						fmt.Println(err.Error())
-					}
-					if tiktok.Config.LogToSlack {
-						LogToSlack("db.QueryRow error: "+err.Error(), tiktok, attachments)
-					}
-					return c, cronjobs, CronState
-				}
+	attachments.Color = "#0000ff"
+	if plan.PlannedPoints() > plan.Capacity.Points() && len(plan.Capacity.History) > 0 {
+		attachments.Color = "#ff9900"
+	}
+	attachments.Text = CapacityText(plan.Capacity, plan.PlannedPoints())
+	Wrangler(tiktok.Config.SlackHook, "Next sprint capacity for *"+req.Opts.General.TeamName+"*", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-				if status {
-
-					err := db.QueryRow("SELECT slackid FROM tiktok_users where slackid=?", userInfo.ID).Scan(&tempSID)
-
-					switch {
-					case err == sql.ErrNoRows:
-						if newUserData.Name == "" || newUserData.Email == "" || newUserData.SlackID == "" || newUserData.Trello == "" || newUserData.Github == "" {
-							rtm.SendMessage(rtm.NewOutgoingMessage("Your data is Bungle in the Jungle, sorry I can't do this.", ev.Msg.Channel))
-						} else {
-							if AddDBUser(tiktok, newUserData) {
-								rtm.SendMessage(rtm.NewOutgoingMessage("Awesome, I've registered your info!", ev.Msg.Channel))
-								umessage := "Name: " + newUserData.Name + "\nE-Mail: " + newUserData.Email + "\nSlack: " + newUserData.SlackID + "\nTrello: " + newUserData.Trello + "\nGithub: " + newUserData.Github + "\n"
-
-								myPayload.Text = "I've registered you as follows:"
-								myPayload.Channel = userInfo.ID
-								attachments.Color = "#00FF55"
-								attachments.Text = umessage
-								myPayload.Attachments = append(myPayload.Attachments, attachments)
-								_ = WranglerDM(tiktok, myPayload)
-
-								attachments.Color = "#00FF55"
-								attachments.Text = umessage
-								if tiktok.Config.LogToSlack {
-									LogToSlack("A new user was registered per their request.", tiktok, attachments)
-								}
-							} else {
-								rtm.SendMessage(rtm.NewOutgoingMessage("Something went horribly wrong I could not add your new user info!", ev.Msg.Channel))
-							}
-						}
-					case err != nil:
-						if tiktok.Config.DEBUG {
-							fmt.Println(err.Error())
-						}
-						if tiktok.Config.LogToSlack {
-							LogToSlack("db.QueryRow error: "+err.Error(), tiktok, attachments)
-						}
-
-						return c, cronjobs, CronState
-
-					default:
-						p := strings.Replace(userInfo.Name, ".", " ", -1)
-						rtm.SendMessage(rtm.NewOutgoingMessage("Hey "+p+" I already have you registered. :cheers:", ev.Msg.Channel))
-						if tiktok.Config.LogToSlack {
-							LogToSlack(userInfo.Name+" is already registered!.", tiktok, attachments)
-						}
-					}
-				}
-			} else {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Your data is Bungle in the Jungle, sorry I can't do this.", ev.Msg.Channel))
-			}
-		}
+// cmdCleanBacklog - clean BackLog (separate from archiving)
+func cmdCleanBacklog(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		return c, cronjobs, CronState
+	LogToSlack(req.User.Name+" asked me to clean the BackLog on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, cleaning the backlog for team " + req.Team + ".")
 
+	err := CleanBackLog(req.Opts, tiktok, tiktok.Trello)
+	if err != nil {
+		errTrap(tiktok, "Error in `CleanBackLog` process run by slack command request.", err)
 	}
+}
 
-	// Add user to user DB
-	if strings.Contains(lowerString, "add a new user") {
-		var newUserData UserData
+// cmdArchiveBacklog - archive the BackLog
+func cmdArchiveBacklog(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		userData := Between(ev.Msg.Text, "[", "]")
-		if userData == "" {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I did not understand what you want me to do, sorry.\nFormat: ```add a new user [name,email,slackID,trello,github]```\nDo not use quotes. SlackID must be UID not username.", ev.Msg.Channel))
-		} else {
-			userInfo, _ := api.GetUserInfo(ev.Msg.User)
-
-			LogToSlack(userInfo.Name+" asked me to to add data to the user DB.", tiktok, attachments)
-
-			if Permissions(tiktok, ev.Msg.User, "scrum", api, tiktok.Config.ScrumControlChannel) {
-				brokeOut := strings.Split(userData, ",")
-				if len(brokeOut) == 5 {
-					newUserData.Name = strings.ToLower(brokeOut[0])
-					newUserData.Email = strings.ToLower(brokeOut[1])
-					newUserData.SlackID = brokeOut[2]
-					newUserData.Trello = brokeOut[3]
-					newUserData.Github = brokeOut[4]
-
-					if newUserData.Name == "" || newUserData.Email == "" || newUserData.SlackID == "" || newUserData.Trello == "" || newUserData.Github == "" {
-						rtm.SendMessage(rtm.NewOutgoingMessage("Your data is Bungle in the Jungle, sorry I can't do this.", ev.Msg.Channel))
-					} else {
-						if AddDBUser(tiktok, newUserData) {
-							rtm.SendMessage(rtm.NewOutgoingMessage("Awesome, i've added your new user info!", ev.Msg.Channel))
-							if tiktok.Config.LogToSlack {
-								umessage := "Name: " + newUserData.Name + "\nE-Mail: " + newUserData.Email + "\nSlack: " + newUserData.SlackID + "\nTrello: " + newUserData.Trello + "\nGithub: " + newUserData.Github + "\n"
-								attachments.Color = "#00FF55"
-								attachments.Text = umessage
-								LogToSlack("Added new user info to json", tiktok, attachments)
-							}
-						} else {
-							rtm.SendMessage(rtm.NewOutgoingMessage("Something went horribly wrong I could not add your new user info!", ev.Msg.Channel))
-
-						}
-					}
-				} else {
-					rtm.SendMessage(rtm.NewOutgoingMessage("Your data is Bungle in the Jungle, sorry I can't do this.", ev.Msg.Channel))
-				}
+	LogToSlack(req.User.Name+" asked me to archive old cards in the `BackLog` on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, archiving cards older then " + strconv.Itoa(req.Opts.General.BackLogDays) + " days in the `BackLog` for team " + req.Team + ".")
 
-			} else {
-				smessage = "You are not the boss of me! Permission denied."
-				rtm.SendMessage(rtm.NewOutgoingMessage(smessage, ev.Msg.Channel))
-			}
-		}
+	err := ArchiveBacklog(tiktok, tiktok.Trello, req.Opts)
+	if err != nil {
+		errTrap(tiktok, "Error in `ArchiveBacklog` process run by slack command request.", err)
 	}
+}
 
-	// Create retro card
-	if strings.Contains(lowerString, "retro card") {
-
-		var listName string
-		var listID string
+// cmdArchiveDone - run board archiving
+func cmdArchiveDone(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
+	LogToSlack(req.User.Name+" asked me to run archiving on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, running archiving on board for team " + req.Team + ".")
 
-			message := ListAllTOML(tiktok)
+	_, _ = CleanDone(req.Opts, tiktok, tiktok.Trello)
+}
 
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+// cmdStalePR - scan for lagging PR
+func cmdStalePR(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" {well|wrong} retro card [mcboard] my card title`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+	LogToSlack(req.User.Name+" asked me to scan for stale PR's on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, scanning for stale PR's on board for team " + req.Team + ".")
 
-		} else {
-			var msgBreak []string
-			var locale int
+	returnMsg, _ := StalePRcards(req.Opts, tiktok, tiktok.Trello)
+	req.Reply(returnMsg)
+}
 
-			//break down message
-			if strings.Contains(strings.ToLower(lowerString), "@"+strings.ToLower(tiktok.Config.BotID)) {
+// cmdSyncPoints - sync board points to custom field and Alert on changing points
+func cmdSyncPoints(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-				msgBreak = strings.SplitAfterN(lowerString, " ", 6)
-				if len(msgBreak) != 6 {
+	LogToSlack(req.User.Name+" asked me to syncronize points on the "+req.Team+" configuration.", tiktok, attachments)
+	req.Reply("Okay, syncronizing points on board for team " + req.Team + ".")
 
-					rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-					return c, cronjobs, CronState
+	_ = PointCleanup(req.Opts, tiktok, tiktok.Trello, req.Team)
+}
 
-				}
-				locale = 1
+// cmdListBoards - list all manageable trello boards and tomls
+func cmdListBoards(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
 
-			} else {
+	attachments.Color = "#0000CC"
+	attachments.Text = ListAllTOML(tiktok)
 
-				msgBreak = strings.SplitAfterN(lowerString, " ", 5)
-				if len(msgBreak) != 5 {
+	Wrangler(tiktok.Config.SlackHook, "Hey "+req.User.Name+", I manage the following boards: ", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-					rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-					return c, cronjobs, CronState
+// cmdAddMe - add me to user DB
+func cmdAddMe(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var newUserData UserData
+	var myPayload BotDMPayload
 
-				}
-				locale = 0
+	userData := req.Args["email,trello id,github id"]
+	if userData == "" {
+		req.Reply("I can help you register here's how:\n```add me [email,trello id,github id]```\n`Do not use quotes anywhere.`\nExample: ```@" + tiktok.Config.BotName + " add me [some.one@mydomain.com,someone12,someone-ea]```")
+		return
+	}
 
-			}
+	userInfo := req.User
 
-			if strings.Contains(msgBreak[locale], "well") || strings.Contains(msgBreak[locale], "wrong") || strings.Contains(msgBreak[locale], "good") || strings.Contains(msgBreak[locale], "bad") || strings.Contains(msgBreak[locale], "improve") || strings.Contains(msgBreak[locale], "improvement") || strings.Contains(msgBreak[locale], "vent") {
+	LogToSlack(userInfo.Name+" asked me to to register them in the user DB.", tiktok, attachments)
 
-				if strings.Contains(msgBreak[locale], "well") || strings.Contains(msgBreak[locale], "good") {
-					listName = "What Went Well"
-				} else if strings.Contains(msgBreak[locale], "vent") {
-					listName = "Vent"
-				} else {
-					listName = "What Needs Improvement"
-				}
+	brokeOut := strings.Split(userData, ",")
+	if len(brokeOut) != 3 {
+		req.Reply("Your data is Bungle in the Jungle, sorry I can't do this.")
+		return
+	}
 
-				sOpts, err := GetDBSprint(tiktok, teamID)
-				if err != nil {
-					rtm.SendMessage(rtm.NewOutgoingMessage("Sorry I couldn't find what you were asking for! - ", ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
+	newUserData.Name = strings.Replace(userInfo.Name, ".", " ", -1)
+	newUserData.SlackID = userInfo.ID
+	newUserData.Email = strings.ToLower(brokeOut[0])
+	newUserData.Trello = strings.ToLower(brokeOut[1])
+	newUserData.Github = strings.ToLower(brokeOut[2])
 
-				allTheThings, err := RetrieveAll(tiktok, sOpts.RetroID, "none")
-				if err != nil {
-					errTrap(tiktok, "Attempting to add card to retro board and received RetrieveAll trello error: ", err)
-					rtm.SendMessage(rtm.NewOutgoingMessage("Sorry somethings wrong with that trello board I can't do it!", ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
+	// check if already registered
+	registered, err := tiktok.DB.IsRegistered(userInfo.ID)
 
-				allLists, err := GetLists(tiktok, allTheThings.ID)
-				if err != nil {
-					errTrap(tiktok, "Attempting to add card to retro board and received GetLists trello error: ", err)
-					return c, cronjobs, CronState
-				}
+	switch {
+	case err == nil && !registered:
+		if newUserData.Name == "" || newUserData.Email == "" || newUserData.SlackID == "" || newUserData.Trello == "" || newUserData.Github == "" {
+			req.Reply("Your data is Bungle in the Jungle, sorry I can't do this.")
+		} else {
+			if tiktok.DB.AddUser(newUserData) {
+				req.Reply("Awesome, I've registered your info!")
+				umessage := "Name: " + newUserData.Name + "\nE-Mail: " + newUserData.Email + "\nSlack: " + newUserData.SlackID + "\nTrello: " + newUserData.Trello + "\nGithub: " + newUserData.Github + "\n"
 
-				for _, list := range allLists {
-					if list.Name == listName {
-						listID = list.ID
-					}
-				}
+				myPayload.Text = "I've registered you as follows:"
+				myPayload.Channel = userInfo.ID
+				attachments.Color = "#00FF55"
+				attachments.Text = umessage
+				myPayload.Attachments = append(myPayload.Attachments, attachments)
+				_ = WranglerDM(tiktok, myPayload)
 
-				if listID == "" {
-					if tiktok.Config.LogToSlack {
-						LogToSlack("Retro Board <"+allTheThings.ShortURL+"|"+allTheThings.Name+"> ("+allTheThings.ID+") is missing a column for `"+listName+"`", tiktok, attachments)
-					}
-					rtm.SendMessage(rtm.NewOutgoingMessage("Sorry somethings wrong with that trello board I can't find the `"+listName+"` column!", ev.Msg.Channel))
-					return c, cronjobs, CronState
+				if tiktok.Config.LogToSlack {
+					LogToSlack("A new user was registered per their request.", tiktok, attachments)
 				}
-
-				locale = locale + 4
-				err = CreateCard(msgBreak[locale], listID, tiktok)
-
-				rtm.SendMessage(rtm.NewOutgoingMessage("I created your card `"+msgBreak[locale]+"` on list `"+listName+"` in <"+allTheThings.ShortURL+"|"+allTheThings.Name+">", ev.Msg.Channel))
-
 			} else {
-				rtm.SendMessage(rtm.NewOutgoingMessage("Please specify card type first: What Went Well = `well` or What Went Wrong = `wrong`\n```@"+tiktok.Config.BotName+" well retro card [<team>] my card title```", ev.Msg.Channel))
+				req.Reply("Something went horribly wrong I could not add your new user info!")
 			}
+		}
+	case err != nil:
+		if tiktok.Config.DEBUG {
+			fmt.Println(err.Error())
+		}
+		if tiktok.Config.LogToSlack {
+			LogToSlack("db.QueryRow error: "+err.Error(), tiktok, attachments)
+		}
 
+	default:
+		p := strings.Replace(userInfo.Name, ".", " ", -1)
+		req.Reply("Hey " + p + " I already have you registered. :cheers:")
+		if tiktok.Config.LogToSlack {
+			LogToSlack(userInfo.Name+" is already registered!.", tiktok, attachments)
 		}
 	}
+}
 
-	// Add Ignore Label
-	if strings.Contains(lowerString, "ignore label ") {
-
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" ignore label {myLabel} [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
-		} else {
-
-			labelName := Between(ev.Msg.Text, "{", "}")
-			if labelName == "" {
-				attachments.Color = ""
-				attachments.Text = ""
-				Wrangler(tiktok.Config.SlackHook, "Please specify the label you want me to ignore inside curly braces {myLabel}", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-			} else {
-				// we have a label and a team
-				opts, err := LoadConf(tiktok, teamID)
-				if err != nil {
-					errTrap(tiktok, "Can not find team "+teamID+" in `botactions.go` for `ignore label` action", err)
-					rtm.SendMessage(rtm.NewOutgoingMessage("I can not find the team called `"+teamID+"` that you requested.", ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
-
-				labelData, err := GetLabel(tiktok, opts.General.BoardID)
-				if err != nil {
-					errTrap(tiktok, "Error retrieving label data for board "+opts.General.BoardID+" in `trello.go` GetLabelData function", err)
-					return c, cronjobs, CronState
-				}
+// cmdAddUser - add someone else to user DB
+func cmdAddUser(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var newUserData UserData
 
-				for _, l := range labelData {
-					if l.Name == labelName {
-						labelID = l.ID
-					}
-				}
+	userData := req.Args["name,email,slackID,trello,github"]
+	if userData == "" {
+		req.Reply("I did not understand what you want me to do, sorry.\nFormat: ```add a new user [name,email,slackID,trello,github]```\nDo not use quotes. SlackID must be UID not username.")
+		return
+	}
 
-				if labelID != "" {
-					err = LabelIgnore(opts, tiktok, labelID)
-					if tiktok.Config.LogToSlack {
-						userInfo, _ := api.GetUserInfo(ev.Msg.User)
+	LogToSlack(req.User.Name+" asked me to to add data to the user DB.", tiktok, attachments)
 
-						attachments.Color = ""
-						attachments.Text = ""
-						LogToSlack(userInfo.Name+" asked me to add the label "+labelName+" on board "+opts.General.TeamName+" to the ignore list", tiktok, attachments)
-					}
+	brokeOut := strings.Split(userData, ",")
+	if len(brokeOut) != 5 {
+		req.Reply("Your data is Bungle in the Jungle, sorry I can't do this.")
+		return
+	}
 
-					rtm.SendMessage(rtm.NewOutgoingMessage("I've added the label `"+labelName+"` on the *"+opts.General.TeamName+"* board to the Theme ignore list.", ev.Msg.Channel))
+	newUserData.Name = strings.ToLower(brokeOut[0])
+	newUserData.Email = strings.ToLower(brokeOut[1])
+	newUserData.SlackID = brokeOut[2]
+	newUserData.Trello = brokeOut[3]
+	newUserData.Github = brokeOut[4]
 
-				} else {
-					rtm.SendMessage(rtm.NewOutgoingMessage("I can not find the label called `"+labelName+"` that you requested.", ev.Msg.Channel))
-					return c, cronjobs, CronState
-				}
-			}
+	if newUserData.Name == "" || newUserData.Email == "" || newUserData.SlackID == "" || newUserData.Trello == "" || newUserData.Github == "" {
+		req.Reply("Your data is Bungle in the Jungle, sorry I can't do this.")
+		return
+	}
 
+	if tiktok.DB.AddUser(newUserData) {
+		req.Reply("Awesome, i've added your new user info!")
+		if tiktok.Config.LogToSlack {
+			umessage := "Name: " + newUserData.Name + "\nE-Mail: " + newUserData.Email + "\nSlack: " + newUserData.SlackID + "\nTrello: " + newUserData.Trello + "\nGithub: " + newUserData.Github + "\n"
+			attachments.Color = "#00FF55"
+			attachments.Text = umessage
+			LogToSlack("Added new user info to json", tiktok, attachments)
 		}
+	} else {
+		req.Reply("Something went horribly wrong I could not add your new user info!")
 	}
+}
 
-	// Check Epic Links
-	if strings.Contains(lowerString, "check epic links") {
-		teamID := Between(ev.Msg.Text, "[", "]")
-		if teamID == "" {
-
-			message := ListAllTOML(tiktok)
-			attachments.Color = "#0000CC"
-			attachments.Text = message
+// cmdRetroCard - create retro card, the word in front of `retro card` picks the list
+func cmdRetroCard(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var listName string
+	var listID string
+	var cardType string
 
-			Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" retro [mcboard]`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-		} else {
-			opts, err := LoadConf(tiktok, teamID)
-			if err != nil {
-				errTrap(tiktok, "Can not find team "+teamID+" in `botactions.go` for `check epic links` action.", err)
-				rtm.SendMessage(rtm.NewOutgoingMessage("I can not find the team called `"+teamID+"` that you requested.", ev.Msg.Channel))
-				return c, cronjobs, CronState
-			}
+	before := strings.Fields(req.Lower[:strings.Index(req.Lower, req.Trigger)])
+	if len(before) > 0 {
+		cardType = before[len(before)-1]
+	}
 
-			EpicLink(tiktok, opts)
-		}
+	if !(strings.Contains(cardType, "well") || strings.Contains(cardType, "wrong") || strings.Contains(cardType, "good") || strings.Contains(cardType, "bad") || strings.Contains(cardType, "improve") || strings.Contains(cardType, "vent")) {
+		req.Reply("Please specify card type first: What Went Well = `well` or What Went Wrong = `wrong`\n```@" + tiktok.Config.BotName + " well retro card [<team>] my card title```")
+		return
 	}
 
-	// What time is it according to TikTok
-	if strings.Contains(lowerString, "what time is it") {
-		today := time.Now()
-		workingTime := today.Format("2006-01-02 15:04:05")
-		rtm.SendMessage(rtm.NewOutgoingMessage("My Time is: "+workingTime, ev.Msg.Channel))
+	if strings.Contains(cardType, "well") || strings.Contains(cardType, "good") {
+		listName = "What Went Well"
+	} else if strings.Contains(cardType, "vent") {
+		listName = "Vent"
+	} else {
+		listName = "What Needs Improvement"
 	}
 
-	// List registered users
-	if strings.Contains(lowerString, "list registered users") || strings.Contains(lowerString, "get registered users") {
+	sOpts, err := tiktok.DB.GetSprint(req.Team)
+	if err != nil {
+		req.Reply("Sorry I couldn't find what you were asking for! - ")
+		return
+	}
 
-		var thisMessage string
+	allTheThings, err := tiktok.Trello.RetrieveAll(sOpts.RetroID, "none")
+	if err != nil {
+		errTrap(tiktok, "Attempting to add card to retro board and received RetrieveAll trello error: ", err)
+		req.Reply("Sorry somethings wrong with that trello board I can't do it!")
+		return
+	}
 
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-		LogToSlack(userInfo.Name+" asked me to for a list of all registered users in my Database.", tiktok, attachments)
+	allLists, err := tiktok.Trello.GetLists(allTheThings.ID)
+	if err != nil {
+		errTrap(tiktok, "Attempting to add card to retro board and received GetLists trello error: ", err)
+		return
+	}
 
-		allUsers, err := GetDBUsers(tiktok)
-		if err != nil {
-			errTrap(tiktok, "Error returning from `GetDBUsers` in `botactions.go` when asked to `list registered users`", err)
-			return c, cronjobs, CronState
+	for _, list := range allLists {
+		if list.Name == listName {
+			listID = list.ID
 		}
+	}
 
-		for _, u := range allUsers {
-			thisMessage = thisMessage + "*" + u.Name + "* : (Slack: `" + u.SlackID + "`) (Trello: `" + u.Trello + "`) (Github: `" + u.Github + "`)\n"
+	if listID == "" {
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Retro Board <"+allTheThings.ShortURL+"|"+allTheThings.Name+"> ("+allTheThings.ID+") is missing a column for `"+listName+"`", tiktok, attachments)
 		}
+		req.Reply("Sorry somethings wrong with that trello board I can't find the `" + listName + "` column!")
+		return
+	}
 
-		attachments.Color = "#12ffcc"
-		attachments.Text = thisMessage
-		Wrangler(tiktok.Config.SlackHook, "List of users registered with me currently: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-
+	cardTitle := req.Args["my card info"]
+	err = CreateCard(cardTitle, listID, tiktok)
+	if err != nil {
+		errTrap(tiktok, "Attempting to add card to retro board and received CreateCard trello error: ", err)
 	}
 
-	// List all Github REPOS
-	if strings.Contains(ev.Msg.Text, "list repo") || strings.Contains(ev.Msg.Text, "list github repo") {
+	req.Reply("I created your card `" + cardTitle + "` on list `" + listName + "` in <" + allTheThings.ShortURL + "|" + allTheThings.Name + ">")
+}
 
-		var numCount = 0
-		var message = ""
+// cmdIgnoreLabel - add a label to the theme ignore list
+func cmdIgnoreLabel(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var labelID string
 
-		rtm.SendMessage(rtm.NewOutgoingMessage("Let me grab the Github Repo List, I will Direct Message you the list.", ev.Msg.Channel))
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-		LogToSlack(userInfo.Name+" asked me to list all the GitHub REPOs in "+tiktok.Config.GithubOrgName, tiktok, attachments)
+	opts := req.Opts
+	labelName := req.Args["myLabel"]
 
-		repoList := RetrieveOrgRepo(tiktok, tiktok.Config.GithubOrgName)
+	labelData, err := tiktok.Trello.GetLabel(opts.General.BoardID)
+	if err != nil {
+		errTrap(tiktok, "Error retrieving label data for board "+opts.General.BoardID+" in `trello.go` GetLabelData function", err)
+		return
+	}
 
-		for _, r := range repoList {
-			message = message + "• " + *r.Name + " - " + *r.HTMLURL + "\n"
-			numCount++
+	for _, l := range labelData {
+		if l.Name == labelName {
+			labelID = l.ID
 		}
+	}
 
-		testPayload.Text = "List of all *" + strconv.Itoa(numCount) + "* " + tiktok.Config.GithubOrgName + " REPOs:"
-		testPayload.Channel = userInfo.ID
-		attachments.Color = "#6600ff"
-		attachments.Text = message
-		testPayload.Attachments = append(testPayload.Attachments, attachments)
-		_ = WranglerDM(tiktok, testPayload)
+	if labelID == "" {
+		req.Reply("I can not find the label called `" + labelName + "` that you requested.")
+		return
+	}
 
+	err = tiktok.DB.AddIgnoreLabel(opts.General.BoardID, labelID)
+	if err != nil {
+		errTrap(tiktok, "Error from `LabelIgnore` in `botactions.go` for `ignore label` action", err)
+	}
+	if tiktok.Config.LogToSlack {
+		LogToSlack(req.User.Name+" asked me to add the label "+labelName+" on board "+opts.General.TeamName+" to the ignore list", tiktok, attachments)
 	}
 
-	// List GitHub Users
-	if strings.Contains(ev.Msg.Text, "list users github") {
+	req.Reply("I've added the label `" + labelName + "` on the *" + opts.General.TeamName + "* board to the Theme ignore list.")
+}
 
-		var numCount = 0
-		var message = ""
+// cmdEpicLinks - check epic links
+func cmdEpicLinks(tiktok *TikTokConf, req *CommandRequest) {
+	EpicLink(tiktok, tiktok.Trello, req.Opts)
+}
 
-		rtm.SendMessage(rtm.NewOutgoingMessage("Let me grab all the Trello users for you, one sec...I will Direct Message you the list.", ev.Msg.Channel))
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-		LogToSlack(userInfo.Name+" asked me to list all the GitHub users in "+tiktok.Config.GithubOrgName, tiktok, attachments)
+// cmdWhatTime - what time is it according to TikTok
+func cmdWhatTime(tiktok *TikTokConf, req *CommandRequest) {
+	today := time.Now()
+	workingTime := today.Format("2006-01-02 15:04:05")
+	req.Reply("My Time is: " + workingTime)
+}
 
-		userList := RetrieveUsers(tiktok, tiktok.Config.GithubOrgName)
+// cmdListUsers - list registered users
+func cmdListUsers(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var thisMessage string
 
-		for _, u := range userList {
-			if u.HTMLURL != nil {
-				message = message + *u.Login + " - [" + strconv.Itoa(int(*u.ID)) + "] - " + *u.HTMLURL + "\n"
-			} else {
-				message = message + *u.Login + " - [" + strconv.Itoa(int(*u.ID)) + "]\n"
-			}
-			numCount++
-		}
+	LogToSlack(req.User.Name+" asked me to for a list of all registered users in my Database.", tiktok, attachments)
 
-		testPayload.Text = "List of all *" + strconv.Itoa(numCount) + "* " + tiktok.Config.GithubOrgName + " Github Users: \n"
-		testPayload.Channel = userInfo.ID
-		attachments.Color = "#00ff00"
-		attachments.Text = message
-		testPayload.Attachments = append(testPayload.Attachments, attachments)
-		_ = WranglerDM(tiktok, testPayload)
+	allUsers, err := tiktok.DB.GetUsers()
+	if err != nil {
+		errTrap(tiktok, "Error returning from `GetDBUsers` in `botactions.go` when asked to `list registered users`", err)
+		return
 	}
 
-	// List Open PRs on a Repo
-	if strings.Contains(ev.Msg.Text, "list pull request") {
+	for _, u := range allUsers {
+		thisMessage = thisMessage + "*" + u.Name + "* : (Slack: `" + u.SlackID + "`) (Trello: `" + u.Trello + "`) (Github: `" + u.Github + "`)\n"
+	}
 
-		var prMessage = ""
-		var repoName = ""
-		var locale int
-		var msgBreak []string
+	attachments.Color = "#12ffcc"
+	attachments.Text = thisMessage
+	Wrangler(tiktok.Config.SlackHook, "List of users registered with me currently: ", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
 
-		//break down message
-		if strings.Contains(strings.ToLower(lowerString), "@"+strings.ToLower(tiktok.Config.BotID)) {
+// cmdListRepos - list all Github REPOS
+func cmdListRepos(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var testPayload BotDMPayload
+	var numCount = 0
+	var message = ""
 
-			msgBreak = strings.SplitAfterN(lowerString, " ", 5)
-			if len(msgBreak) != 5 {
+	req.Reply("Let me grab the Github Repo List, I will Direct Message you the list.")
+	LogToSlack(req.User.Name+" asked me to list all the GitHub REPOs in "+tiktok.Config.GithubOrgName, tiktok, attachments)
 
-				rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-				return c, cronjobs, CronState
+	repoList := RetrieveOrgRepo(tiktok, tiktok.Config.GithubOrgName)
 
-			}
-			locale = 4
+	for _, r := range repoList {
+		message = message + "• " + *r.Name + " - " + *r.HTMLURL + "\n"
+		numCount++
+	}
 
-		} else {
+	testPayload.Text = "List of all *" + strconv.Itoa(numCount) + "* " + tiktok.Config.GithubOrgName + " REPOs:"
+	testPayload.Channel = req.User.ID
+	attachments.Color = "#6600ff"
+	attachments.Text = message
+	testPayload.Attachments = append(testPayload.Attachments, attachments)
+	_ = WranglerDM(tiktok, testPayload)
+}
 
-			msgBreak = strings.SplitAfterN(lowerString, " ", 4)
-			if len(msgBreak) != 5 {
+// cmdListGithubUsers - list GitHub Users
+func cmdListGithubUsers(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var testPayload BotDMPayload
+	var numCount = 0
+	var message = ""
 
-				rtm.SendMessage(rtm.NewOutgoingMessage("I'm not sure what you are asking me to do.", ev.Msg.Channel))
-				return c, cronjobs, CronState
+	req.Reply("Let me grab all the Trello users for you, one sec...I will Direct Message you the list.")
+	LogToSlack(req.User.Name+" asked me to list all the GitHub users in "+tiktok.Config.GithubOrgName, tiktok, attachments)
 
-			}
-			locale = 3
+	userList := RetrieveUsers(tiktok, tiktok.Config.GithubOrgName)
 
+	for _, u := range userList {
+		if u.HTMLURL != nil {
+			message = message + *u.Login + " - [" + strconv.Itoa(int(*u.ID)) + "] - " + *u.HTMLURL + "\n"
+		} else {
+			message = message + *u.Login + " - [" + strconv.Itoa(int(*u.ID)) + "]\n"
 		}
+		numCount++
+	}
 
-		repoName = msgBreak[locale]
+	testPayload.Text = "List of all *" + strconv.Itoa(numCount) + "* " + tiktok.Config.GithubOrgName + " Github Users: \n"
+	testPayload.Channel = req.User.ID
+	attachments.Color = "#00ff00"
+	attachments.Text = message
+	testPayload.Attachments = append(testPayload.Attachments, attachments)
+	_ = WranglerDM(tiktok, testPayload)
+}
 
-		rtm.SendMessage(rtm.NewOutgoingMessage("Grabbing open PR list for repo `"+repoName+"`", ev.Msg.Channel))
-		userInfo, _ := api.GetUserInfo(ev.Msg.User)
-		LogToSlack(userInfo.Name+" asked me to list all the open PRs in repo `"+repoName+"` in "+tiktok.Config.GithubOrgName, tiktok, attachments)
+// cmdListPulls - list open PRs on a repo
+func cmdListPulls(tiktok *TikTokConf, req *CommandRequest) {
+	var attachments Attachment
+	var prMessage = ""
 
-		pullList, err := GitPRList(tiktok, repoName, tiktok.Config.GithubOrgName)
-		if err != nil {
-			rtm.SendMessage(rtm.NewOutgoingMessage("I couldn't find the Repo you wanted called `"+repoName+"`", ev.Msg.Channel))
-			return c, cronjobs, CronState
-		}
+	repoName := req.Args["repo"]
 
-		if len(pullList) == 0 {
-			Wrangler(tiktok.Config.SlackHook, "The repo requested `"+repoName+"` currently has no open Pull Requests", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
-			return c, cronjobs, CronState
-		}
+	req.Reply("Grabbing open PR list for repo `" + repoName + "`")
+	LogToSlack(req.User.Name+" asked me to list all the open PRs in repo `"+repoName+"` in "+tiktok.Config.GithubOrgName, tiktok, attachments)
 
-		for _, u := range pullList {
-			loc, _ := time.LoadLocation("America/Los_Angeles")
-			prUptime := *u.UpdatedAt
-			lastUpdate := prUptime.In(loc).Format("2006-01-02 15:04:05")
+	pullList, err := GitPRList(tiktok, repoName, tiktok.Config.GithubOrgName)
+	if err != nil {
+		req.Reply("I couldn't find the Repo you wanted called `" + repoName + "`")
+		return
+	}
 
-			prMessage = prMessage + "Pull Request #" + strconv.Itoa(*u.Number) + " - <" + *u.HTMLURL + "|" + *u.Title + "> (Last Updated: `" + lastUpdate + " PT`)\n" // is " + *u.State  + " by <" + *u.User.HTMLURL + "|" + *u.User.Name + ">\n"
+	if len(pullList) == 0 {
+		Wrangler(tiktok.Config.SlackHook, "The repo requested `"+repoName+"` currently has no open Pull Requests", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+		return
+	}
 
-		}
+	for _, u := range pullList {
+		loc, _ := time.LoadLocation("America/Los_Angeles")
+		prUptime := *u.UpdatedAt
+		lastUpdate := prUptime.In(loc).Format("2006-01-02 15:04:05")
 
-		attachments.Text = prMessage
-		attachments.Color = "#0000cc"
-		Wrangler(tiktok.Config.SlackHook, "List of all *Open PRs* on Repo `"+repoName+"` in "+tiktok.Config.GithubOrgName+" Github: \n", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+		prMessage = prMessage + "Pull Request #" + strconv.Itoa(*u.Number) + " - <" + *u.HTMLURL + "|" + *u.Title + "> (Last Updated: `" + lastUpdate + " PT`)\n"
 	}
 
-	return c, cronjobs, CronState
+	attachments.Text = prMessage
+	attachments.Color = "#0000cc"
+	Wrangler(tiktok.Config.SlackHook, "List of all *Open PRs* on Repo `"+repoName+"` in "+tiktok.Config.GithubOrgName+" Github: \n", req.Ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+}
+
+// cmdHelp - DM the command list
+func cmdHelp(tiktok *TikTokConf, req *CommandRequest) {
+	req.Reply("I have DM'd you some help information!")
+	Help(tiktok, req.Ev.Msg.User, req.API)
 }
@@ -1,17 +1,16 @@
 package tiktokmod
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 )
 
 //GetAllPoints - GetAll Points in a sprint
-func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message string, valid bool) {
+func GetAllPoints(tiktok *TikTokConf, trello TrelloAPI, opts Config, sOpts SprintData) (message string, valid bool) {
 
 	var attachments Attachment
-	var plugins PointsHistory
 	var sprintName string
 	var numCards int
 
@@ -26,7 +25,7 @@ func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message st
 	rfrpts := 0
 	dnepts := 0
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err == nil {
 		for _, aTt := range allTheThings.Cards {
 			if !aTt.Closed {
@@ -41,50 +40,41 @@ func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message st
 						}
 					}
 
-					pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-
-					for _, pl := range pluginCard {
-						// zero out this struct field, as sometimes its non-existent in the json payload
-						plugins.Points = 0
-
-						if pl.IDPlugin == tiktok.Config.PointsPowerUpID {
-							pluginJSON := []byte(pl.Value)
-							json.Unmarshal(pluginJSON, &plugins)
-
-							switch {
-
-							case aTt.IDList == opts.General.ReadyForWork:
-								rfwpts = rfwpts + plugins.Points
-								numCards = numCards + 1
-							case aTt.IDList == opts.General.Working:
-								wkgpts = wkgpts + plugins.Points
-								numCards = numCards + 1
-							case aTt.IDList == opts.General.ReadyForReview:
-								rfrpts = rfrpts + plugins.Points
-								numCards = numCards + 1
-							case aTt.IDList == opts.General.Done:
-								if sprintName != "" {
-									if sOpts.SprintName == sprintName {
-										if tiktok.Config.LogToSlack && tiktok.Config.DEBUG {
-											LogToSlack("Done Card w/ SprintName `"+sprintName+"` found, adding "+strconv.Itoa(plugins.Points)+" points. Card: "+aTt.ShortURL, tiktok, attachments)
-										}
-										dnepts = dnepts + plugins.Points
-										numCards = numCards + 1
+					points, pointed := EstimatedPoints(tiktok, opts, aTt)
+					if pointed {
+						switch {
+
+						case aTt.IDList == opts.General.ReadyForWork:
+							rfwpts = rfwpts + points
+							numCards = numCards + 1
+						case aTt.IDList == opts.General.Working:
+							wkgpts = wkgpts + points
+							numCards = numCards + 1
+						case aTt.IDList == opts.General.ReadyForReview:
+							rfrpts = rfrpts + points
+							numCards = numCards + 1
+						case aTt.IDList == opts.General.Done:
+							if sprintName != "" {
+								if sOpts.SprintName == sprintName {
+									if tiktok.Config.LogToSlack && tiktok.Config.DEBUG {
+										LogToSlack("Done Card w/ SprintName `"+sprintName+"` found, adding "+strconv.Itoa(points)+" points. Card: "+aTt.ShortURL, tiktok, attachments)
 									}
-								} else {
-									if tiktok.Config.LogToSlack {
-										LogToSlack("Done Card w/ missing Sprint Name (`"+aTt.Name+"`) found. Card: "+aTt.ShortURL, tiktok, attachments)
-									}
-									value, cardListTime := GetTimePutList(opts.General.Done, aTt.ID, opts, tiktok)
-									if value {
-										format := "2006-01-02 15:04:05"
-										fmtTime := cardListTime.Format("2006-01-02 15:04:05")
-										cardTime, _ := time.Parse(format, fmtTime)
-										if cardTime.After(sOpts.SprintStart) {
-											dnepts = dnepts + plugins.Points
-											if tiktok.Config.LogToSlack && tiktok.Config.DEBUG {
-												LogToSlack("Card (`"+aTt.Name+"`) also in current sprint time frame so adding "+strconv.Itoa(plugins.Points)+" points", tiktok, attachments)
-											}
+									dnepts = dnepts + points
+									numCards = numCards + 1
+								}
+							} else {
+								if tiktok.Config.LogToSlack {
+									LogToSlack("Done Card w/ missing Sprint Name (`"+aTt.Name+"`) found. Card: "+aTt.ShortURL, tiktok, attachments)
+								}
+								value, cardListTime := TimePutList(trello, opts.General.Done, aTt.ID)
+								if value {
+									format := "2006-01-02 15:04:05"
+									fmtTime := cardListTime.Format("2006-01-02 15:04:05")
+									cardTime, _ := time.Parse(format, fmtTime)
+									if cardTime.After(sOpts.SprintStart) {
+										dnepts = dnepts + points
+										if tiktok.Config.LogToSlack && tiktok.Config.DEBUG {
+											LogToSlack("Card (`"+aTt.Name+"`) also in current sprint time frame so adding "+strconv.Itoa(points)+" points", tiktok, attachments)
 										}
 									}
 								}
@@ -97,24 +87,17 @@ func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message st
 
 		totalPoints := rfwpts + wkgpts + rfrpts + dnepts
 
-		if totalPoints > 0 {
-			db, status, _ := ConnectDB(tiktok, "tiktok")
-			if status {
-
-				today := time.Now().Local()
-				today.Format("2006-01-02 15:04:05")
-
-				stmt, _ := db.Prepare("INSERT tiktok_burndown SET pointdate=?,team=?,totalpoints=?,rfwpts=?,wkgpts=?,uatpts=?,dnepts=?,numcards=?")
-
-				_, err := stmt.Exec(today, sOpts.TeamID, totalPoints, rfwpts, wkgpts, rfrpts, dnepts, numCards)
-
-				if err != nil {
-					errTrap(tiktok, "SQL Error in tiktok_burndown table insert:", err)
-				}
+		// ideal line runs from what was committed at sprint start down to zero over the sprint's working days
+		committed, err := tiktok.DB.GetCommittedPoints(sOpts)
+		if err != nil || committed == 0 {
+			committed = totalPoints
+		}
+		cal := TeamCalendar(tiktok, opts)
+		idealPts := int(math.Round(cal.IdealRemaining(committed, cal.Date(sOpts.SprintStart), sOpts.Duration, time.Now())))
 
-			}
-			if tiktok.Config.DEBUG {
-				fmt.Println("Failed connection, bailing out...")
+		if totalPoints > 0 {
+			if err := tiktok.DB.RecordBurndown(sOpts.TeamID, totalPoints, rfwpts, wkgpts, rfrpts, dnepts, numCards, idealPts); err != nil {
+				return "Unable to record points.", false
 			}
 		} else {
 			if tiktok.Config.DEBUG {
@@ -135,7 +118,8 @@ func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message st
 		message = message + "Points in Done: " + strconv.Itoa(dnepts) + "\n"
 		message = message + "Total Points in Sprint: " + strconv.Itoa(totalPoints) + "\n"
 		message = message + "Total Cards in Sprint: " + strconv.Itoa(numCards) + "\n"
-		message = message + "Avg Points Per Card: " + strconv.FormatFloat(avgPtsCard, 'f', 2, 64)
+		message = message + "Avg Points Per Card: " + strconv.FormatFloat(avgPtsCard, 'f', 2, 64) + "\n"
+		message = message + "Ideal Points Remaining: " + strconv.Itoa(idealPts)
 
 		if tiktok.Config.LogToSlack {
 			attachments.Color = "#0000ff"
@@ -152,7 +136,7 @@ func GetAllPoints(tiktok *TikTokConf, opts Config, sOpts SprintData) (message st
 }
 
 // SprintSquadPoints - Determine squad points used on a specific sprint by sprint name
-func SprintSquadPoints(tiktok *TikTokConf, opts Config, sprintName string) (totalpoints Squads, nonPoints int, err error) {
+func SprintSquadPoints(tiktok *TikTokConf, trello TrelloAPI, opts Config, sprintName string) (totalpoints Squads, nonPoints int, err error) {
 	var checker bool
 	var points int
 
@@ -166,13 +150,13 @@ func SprintSquadPoints(tiktok *TikTokConf, opts Config, sprintName string) (tota
 	m["customFieldItems"] = "true"
 
 	// Load Squad Information
-	totalpoints, err = GetDBSquads(tiktok, opts.General.BoardID)
+	totalpoints, err = tiktok.DB.GetSquads(opts.General.BoardID)
 	if err != nil {
 		errTrap(tiktok, "Failed DB Call to get squad information in `burndown.go` func `SprintSquadPoints`", err)
 		return totalpoints, nonPoints, err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in SprintSquadPoints `burndown.go` for `"+opts.General.TeamName+"` board", err)
 		return
@@ -185,18 +169,7 @@ func SprintSquadPoints(tiktok *TikTokConf, opts Config, sprintName string) (tota
 					if cusval.Value.Text == sprintName {
 						points = 0
 
-						pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-						for _, p := range pluginCard {
-
-							if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-
-								var plugins PointsHistory
-
-								pluginJSON := []byte(p.Value)
-								json.Unmarshal(pluginJSON, &plugins)
-								points = plugins.Points
-							}
-						}
+						points = CardPoints(tiktok, opts, aTt)
 
 						checker = false
 						for _, labels := range aTt.Labels {
@@ -222,15 +195,15 @@ func SprintSquadPoints(tiktok *TikTokConf, opts Config, sprintName string) (tota
 }
 
 //ChapterCount - Card count by chapter on given list
-func ChapterCount(tiktok *TikTokConf, opts Config, listID string) (allChapter Chapters, totalCards int, err error) {
+func ChapterCount(tiktok *TikTokConf, trello TrelloAPI, opts Config, listID string) (allChapter Chapters, totalCards int, err error) {
 
-	allChapter, err = GetDBChapters(tiktok, opts.General.BoardID)
+	allChapter, err = tiktok.DB.GetChapters(opts.General.BoardID)
 	if err != nil {
 		errTrap(tiktok, "Failed DB Call to get chapter information in `burndown.go` func `ChapterCount`", err)
 		return allChapter, 0, err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in `ChapterCount` in `burndown.go` for `"+opts.General.TeamName+"` board", err)
 		return allChapter, 0, err
@@ -257,18 +230,18 @@ func ChapterCount(tiktok *TikTokConf, opts Config, listID string) (allChapter Ch
 }
 
 //ChapterPoint - Point count by chapter on given list
-func ChapterPoint(tiktok *TikTokConf, opts Config, listID string) (allChapter Chapters, noChapter int, err error) {
+func ChapterPoint(tiktok *TikTokConf, trello TrelloAPI, opts Config, listID string) (allChapter Chapters, noChapter int, err error) {
 
 	var points int
 	var checker bool
 
-	allChapter, err = GetDBChapters(tiktok, opts.General.BoardID)
+	allChapter, err = tiktok.DB.GetChapters(opts.General.BoardID)
 	if err != nil {
 		errTrap(tiktok, "Failed DB Call to get chapter information in `burndown.go` func `ChapterCount`", err)
 		return allChapter, 0, err
 	}
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in `ChapterCount` in `burndown.go` for `"+opts.General.TeamName+"` board", err)
 		return allChapter, 0, err
@@ -279,18 +252,7 @@ func ChapterPoint(tiktok *TikTokConf, opts Config, listID string) (allChapter Ch
 			if aTt.IDList == listID {
 				points = 0
 
-				pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-				for _, p := range pluginCard {
-
-					if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-
-						var plugins PointsHistory
-
-						pluginJSON := []byte(p.Value)
-						json.Unmarshal(pluginJSON, &plugins)
-						points = plugins.Points
-					}
-				}
+				points = CardPoints(tiktok, opts, aTt)
 
 				checker = false
 				for _, labels := range aTt.Labels {
@@ -0,0 +1,168 @@
+package tiktokmod
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTimeZone - time zone of a team that doesn't set TimeZone in its TOML
+const DefaultTimeZone = "America/Los_Angeles"
+
+// Calendar - a team's business calendar.  Everything works on real dates in the team's time zone.  Saturdays and
+// Sundays are only days off when the team has IgnoreWeekends set, holidays are company wide or the team's own and only
+// count when the team has HolidaySupport set, and a half-day holiday counts as half a working day
+type Calendar struct {
+	Location       *time.Location
+	IgnoreWeekends bool
+	holidays       map[string]Holiday
+}
+
+// TeamHolidayKey - what the team column of tiktok_holidays holds for a team's own holidays, the same key tiktok_main
+// uses for the team
+func TeamHolidayKey(opts Config) string {
+	return strings.ToLower(opts.General.Sprintname)
+}
+
+// TeamCalendar - business calendar of a team.  A bad TimeZone falls back to DefaultTimeZone and holidays that can't be
+// read are left out, both are logged.  Teams without HolidaySupport get no holidays at all
+func TeamCalendar(tiktok *TikTokConf, opts Config) *Calendar {
+	cal := &Calendar{IgnoreWeekends: opts.General.IgnoreWeekends, holidays: make(map[string]Holiday)}
+
+	tz := opts.General.TimeZone
+	if tz == "" {
+		tz = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		errTrap(tiktok, "Bad TimeZone `"+tz+"` for `"+opts.General.TeamName+"`, using "+DefaultTimeZone+" in `TeamCalendar` in `calendar.go`", err)
+		loc, _ = time.LoadLocation(DefaultTimeZone)
+	}
+	cal.Location = loc
+
+	if !opts.General.HolidaySupport {
+		return cal
+	}
+
+	holidays, err := tiktok.DB.GetTeamHolidays(TeamHolidayKey(opts))
+	if err != nil {
+		errTrap(tiktok, "Unable to load holidays for `"+opts.General.TeamName+"` in `TeamCalendar` in `calendar.go`, working days will not account for them", err)
+		return cal
+	}
+	for _, h := range holidays {
+		key := h.Day.Format("2006-01-02")
+		// a full day off wins over a half day on the same date
+		if old, ok := cal.holidays[key]; ok && !old.HalfDay {
+			continue
+		}
+		cal.holidays[key] = h
+	}
+
+	return cal
+}
+
+// dayStart - midnight at the start of t's date in the team's time zone
+func (c *Calendar) dayStart(t time.Time) time.Time {
+	t = t.In(c.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.Location)
+}
+
+// Date - a DB date column, which comes back as midnight UTC, as that same date in the team's time zone
+func (c *Calendar) Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.Location)
+}
+
+// DBDate - t's date in the team's time zone as the midnight UTC a DB date column is written and read back as
+func (c *Calendar) DBDate(t time.Time) time.Time {
+	t = t.In(c.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Weekend - t falls on a weekend the team takes off
+func (c *Calendar) Weekend(t time.Time) bool {
+	d := t.In(c.Location).Weekday()
+	return c.IgnoreWeekends && (d == time.Saturday || d == time.Sunday)
+}
+
+// Holiday - the holiday on t's date, if there is one
+func (c *Calendar) Holiday(t time.Time) (holiday Holiday, ok bool) {
+	holiday, ok = c.holidays[t.In(c.Location).Format("2006-01-02")]
+	return holiday, ok
+}
+
+// DayOff - the whole of t's date is a day off, a weekend or a full day holiday
+func (c *Calendar) DayOff(t time.Time) bool {
+	h, ok := c.Holiday(t)
+	return c.Weekend(t) || (ok && !h.HalfDay)
+}
+
+// WorkFraction - how much of t's date is worked, 0, 0.5 or 1
+func (c *Calendar) WorkFraction(t time.Time) float64 {
+	if c.Weekend(t) {
+		return 0
+	}
+	if h, ok := c.Holiday(t); ok {
+		if h.HalfDay {
+			return 0.5
+		}
+		return 0
+	}
+	return 1
+}
+
+// WorkingDays - working days in the `days` dates starting with start's date
+func (c *Calendar) WorkingDays(start time.Time, days int) (working float64) {
+	day := c.dayStart(start)
+	for i := 0; i < days; i++ {
+		working = working + c.WorkFraction(day)
+		day = day.AddDate(0, 0, 1)
+	}
+	return working
+}
+
+// Elapsed - working time between from and to.  Days off don't count and half days count half
+func (c *Calendar) Elapsed(from time.Time, to time.Time) (elapsed time.Duration) {
+	if !to.After(from) {
+		return 0
+	}
+
+	for day := c.dayStart(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+
+		start := day
+		if from.After(start) {
+			start = from
+		}
+		end := next
+		if to.Before(end) {
+			end = to
+		}
+
+		elapsed = elapsed + time.Duration(float64(end.Sub(start))*c.WorkFraction(day))
+	}
+
+	return elapsed
+}
+
+// Days - whole working days between from and to, for card ages
+func (c *Calendar) Days(from time.Time, to time.Time) int {
+	return int(c.Elapsed(from, to).Hours() / 24)
+}
+
+// IdealRemaining - points left on the ideal burndown line at `at` for a sprint of `days` dates starting at start with
+// total points committed.  The line only drops on working days
+func (c *Calendar) IdealRemaining(total int, start time.Time, days int, at time.Time) float64 {
+	planned := c.WorkingDays(start, days)
+	if planned == 0 {
+		return float64(total)
+	}
+
+	done := 0.0
+	for day := c.dayStart(start); day.Before(c.dayStart(at)) && done < planned; day = day.AddDate(0, 0, 1) {
+		done = done + c.WorkFraction(day)
+	}
+	if done > planned {
+		done = planned
+	}
+
+	return float64(total) * (1 - done/planned)
+}
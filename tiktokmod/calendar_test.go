@@ -0,0 +1,70 @@
+package tiktokmod
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestTeamCalendarHolidaySupport(t *testing.T) {
+	christmas := time.Date(2019, time.December, 25, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		holidaySupport bool
+		wantHoliday    bool
+		wantWorking    float64
+	}{
+		{name: "holidays on", holidaySupport: true, wantHoliday: true, wantWorking: 4},
+		{name: "holidays off", holidaySupport: false, wantHoliday: false, wantWorking: 5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.opts.General.HolidaySupport = tc.holidaySupport
+			tt.opts.General.IgnoreWeekends = true
+			tt.db.on("FROM tiktok_holidays", []string{"holidayid", "name", "holiday", "message", "team", "halfday"}, []driver.Value{int64(1), "Christmas", christmas, "", "", false})
+
+			cal := TeamCalendar(tt.tiktok, tt.opts)
+			if _, ok := cal.Holiday(christmas); ok != tc.wantHoliday {
+				t.Errorf("Holiday() = %v, want %v", ok, tc.wantHoliday)
+			}
+			// Monday the 23rd to Sunday the 29th
+			if got := cal.WorkingDays(christmas.AddDate(0, 0, -2), 7); got != tc.wantWorking {
+				t.Errorf("WorkingDays() = %v, want %v", got, tc.wantWorking)
+			}
+		})
+	}
+}
+
+func TestCalendarDBDate(t *testing.T) {
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{zone: "America/Los_Angeles", want: "2019-03-01"},
+		{zone: "UTC", want: "2019-03-02"},
+		{zone: "Asia/Tokyo", want: "2019-03-02"},
+	}
+
+	// 8pm in Los Angeles is the next day in UTC and Tokyo
+	at := time.Date(2019, time.March, 2, 4, 0, 0, 0, time.UTC)
+	for _, tc := range tests {
+		t.Run(tc.zone, func(t *testing.T) {
+			loc, err := time.LoadLocation(tc.zone)
+			if err != nil {
+				t.Skip(err)
+			}
+			cal := &Calendar{Location: loc}
+
+			got := cal.DBDate(at)
+			if got.Location() != time.UTC || got.Format("2006-01-02 15:04") != tc.want+" 00:00" {
+				t.Errorf("DBDate() = %v, want %s midnight UTC", got, tc.want)
+			}
+			if name := NewSprintName(Config{}, at.In(cal.Location)); name != "-"+got.Format("01-02-2006") {
+				t.Errorf("NewSprintName() = %q, want the same date as DBDate()", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package tiktokmod
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOnCallLoad - percent of an on-call person's time lost to on-call when the team TOML doesn't set OnCallLoad
+const DefaultOnCallLoad = 50
+
+// Availability - how much of a sprint one person is around for, kept in tiktok_availability.  PartTime carries over to
+// later sprints until it's changed, PTODays and OnCall only count for the sprint they were set for
+type Availability struct {
+	UserID      int
+	Name        string
+	SlackID     string
+	Team        string
+	SprintStart time.Time
+	PTODays     float64
+	PartTime    int
+	OnCall      bool
+}
+
+// SprintVelocity - points a finished sprint got to Done and the working days it had
+type SprintVelocity struct {
+	SprintName  string
+	WorkingDays float64
+	Done        int
+}
+
+// SprintCapacity - how many points a team can expect to finish in a sprint, from its velocity per working day and who
+// is around
+type SprintCapacity struct {
+	SprintStart time.Time
+	WorkingDays float64
+	History     []SprintVelocity
+	PerDay      float64
+	People      []Availability
+	PersonDays  float64
+	Capacity    float64
+}
+
+// availabilityChange - what a `set availability` command asked to change
+type availabilityChange struct {
+	slackID     string
+	pto         float64
+	setPTO      bool
+	partTime    int
+	setPartTime bool
+	onCall      bool
+	setOnCall   bool
+}
+
+// NextSprintStart - the date the team's next sprint starts on, the current sprint's start plus its duration or today if
+// that's already gone by
+func NextSprintStart(tiktok *TikTokConf, opts Config, cal *Calendar) time.Time {
+	today := cal.dayStart(time.Now())
+
+	sOpts, err := tiktok.DB.GetSprint(strings.ToLower(opts.General.Sprintname))
+	if err != nil {
+		return today
+	}
+
+	next := cal.Date(sOpts.SprintStart).AddDate(0, 0, sOpts.Duration)
+	if next.Before(today) {
+		return today
+	}
+
+	return next
+}
+
+// personDays - working days one person has in a sprint of workingDays
+func personDays(a Availability, workingDays float64, onCallLoad int) float64 {
+	days := workingDays - a.PTODays
+	if days < 0 {
+		days = 0
+	}
+	days = days * float64(a.PartTime) / 100
+	if a.OnCall {
+		days = days * float64(100-onCallLoad) / 100
+	}
+	return days
+}
+
+// TeamCapacity - capacity of the team's sprint starting on start.  Velocity is the points reached Done per working day
+// over the last VelocitySprints finished sprints, scaled by the share of the roster's person-days the team has.  The
+// roster is everyone in tiktok_users, as for the retro board, and anyone who hasn't set availability is all in
+func TeamCapacity(tiktok *TikTokConf, opts Config, cal *Calendar, start time.Time) (c SprintCapacity, err error) {
+	teamID := strings.ToLower(opts.General.Sprintname)
+
+	c.SprintStart = start
+	c.WorkingDays = cal.WorkingDays(start, opts.General.SprintDuration)
+
+	n := opts.General.VelocitySprints
+	if n <= 0 {
+		n = DefaultVelocitySprints
+	}
+	c.History, err = tiktok.DB.GetSprintVelocity(teamID, n)
+	if err != nil {
+		return c, err
+	}
+
+	var done int
+	var days float64
+	for _, h := range c.History {
+		done = done + h.Done
+		days = days + h.WorkingDays
+	}
+	if days > 0 {
+		c.PerDay = float64(done) / days
+	}
+
+	set, err := tiktok.DB.GetTeamAvailability(teamID, start, opts.General.SprintDuration)
+	if err != nil {
+		return c, err
+	}
+	roster, err := tiktok.DB.GetUsers()
+	if err != nil {
+		return c, err
+	}
+	for _, u := range roster {
+		a := Availability{UserID: u.ID, Name: u.Name, SlackID: u.SlackID, Team: teamID, SprintStart: start, PartTime: 100}
+		for _, s := range set {
+			if s.UserID == u.ID {
+				a = s
+			}
+		}
+		c.People = append(c.People, a)
+	}
+
+	load := opts.General.OnCallLoad
+	if load <= 0 {
+		load = DefaultOnCallLoad
+	}
+	for _, p := range c.People {
+		c.PersonDays = c.PersonDays + personDays(p, c.WorkingDays, load)
+	}
+
+	if len(c.People) > 0 && c.WorkingDays > 0 {
+		full := float64(len(c.People)) * c.WorkingDays
+		c.Capacity = c.PerDay * c.PersonDays / full * c.WorkingDays
+	} else {
+		c.Capacity = c.PerDay * c.WorkingDays
+	}
+
+	return c, nil
+}
+
+// Points - capacity rounded to whole points
+func (c SprintCapacity) Points() int {
+	return int(math.Round(c.Capacity))
+}
+
+// availabilityLine - one person's availability, "full sprint" if they're all in
+func availabilityLine(a Availability) string {
+	var parts []string
+
+	if a.PTODays > 0 {
+		parts = append(parts, strconv.FormatFloat(a.PTODays, 'f', -1, 64)+" days PTO")
+	}
+	if a.PartTime != 100 {
+		parts = append(parts, strconv.Itoa(a.PartTime)+"% part-time")
+	}
+	if a.OnCall {
+		parts = append(parts, "on-call")
+	}
+	if len(parts) == 0 {
+		return "full sprint"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// CapacityText - slack formatted capacity of a sprint against the points planned for it
+func CapacityText(c SprintCapacity, planned int) (message string) {
+	message = "*Next sprint starts:* " + c.SprintStart.Format("01/02/2006") + " with " + strconv.FormatFloat(c.WorkingDays, 'f', -1, 64) + " working days\n"
+
+	if len(c.History) == 0 {
+		return message + "No finished sprints with burndown points recorded yet, so I can't work out a capacity.\n"
+	}
+
+	message = message + "*Velocity:* " + strconv.FormatFloat(c.PerDay, 'f', 1, 64) + " points per working day over the last " + strconv.Itoa(len(c.History)) + " sprints\n"
+	for _, h := range c.History {
+		message = message + "    " + h.SprintName + ": " + strconv.Itoa(h.Done) + " points in " + strconv.FormatFloat(h.WorkingDays, 'f', -1, 64) + " working days\n"
+	}
+
+	if len(c.People) > 0 {
+		full := c.WorkingDays * float64(len(c.People))
+		message = message + "*Availability:* " + strconv.FormatFloat(c.PersonDays, 'f', 1, 64) + " of " + strconv.FormatFloat(full, 'f', 1, 64) + " person-days\n"
+		for _, p := range c.People {
+			message = message + "    " + p.Name + ": " + availabilityLine(p) + "\n"
+		}
+	} else {
+		message = message + "*Availability:* nobody is in the users table, counting the team as in for the whole sprint\n"
+	}
+
+	message = message + "*Capacity:* " + strconv.Itoa(c.Points()) + " points\n"
+	message = message + "*Planned:* " + strconv.Itoa(planned) + " points in Next Sprint and roll-over cards"
+	if planned > c.Points() {
+		message = message + " - *" + strconv.Itoa(planned-c.Points()) + " points over capacity*"
+	}
+
+	return message + "\n"
+}
+
+// CapacityCheck - warn SprintChannel when the points headed into the next sprint are more than the team's capacity
+func CapacityCheck(tiktok *TikTokConf, trello TrelloAPI, opts Config) (string, error) {
+	var attachments Attachment
+
+	plan, err := PlanSprint(opts, tiktok, trello, true)
+	if err != nil {
+		return "Unable to plan the next sprint", err
+	}
+
+	if len(plan.Capacity.History) == 0 {
+		return "No velocity history to work out capacity from", nil
+	}
+
+	if plan.PlannedPoints() <= plan.Capacity.Points() {
+		return strconv.Itoa(plan.PlannedPoints()) + " points planned within a capacity of " + strconv.Itoa(plan.Capacity.Points()), nil
+	}
+
+	attachments.Color = "#ff9900"
+	attachments.Text = CapacityText(plan.Capacity, plan.PlannedPoints())
+	TeamWrangler(tiktok, opts, "*Next sprint for "+opts.General.TeamName+" is over capacity!* "+strconv.Itoa(plan.PlannedPoints())+" points are planned against a capacity of "+strconv.Itoa(plan.Capacity.Points())+".", opts.General.SprintChannel, attachments)
+
+	return strconv.Itoa(plan.PlannedPoints()) + " points planned over a capacity of " + strconv.Itoa(plan.Capacity.Points()), nil
+}
+
+// parseAvailability - pull `pto N`, `part-time N`, `full-time`, `on-call`, `not on-call` and `for @user` out of a
+// `set availability` command.  ok is false if anything in it wasn't understood
+func parseAvailability(text string) (change availabilityChange, ok bool) {
+	words := strings.Fields(text)
+
+	for i := 0; i < len(words); i++ {
+		word := strings.ToLower(strings.Trim(words[i], ",."))
+		next := ""
+		if i+1 < len(words) {
+			next = strings.ToLower(strings.Trim(words[i+1], ",.%"))
+		}
+
+		switch word {
+		case "pto":
+			days, err := strconv.ParseFloat(next, 64)
+			if err != nil || days < 0 {
+				return change, false
+			}
+			change.pto = days
+			change.setPTO = true
+			i++
+		case "part-time", "parttime":
+			pct, err := strconv.Atoi(next)
+			if err != nil || pct < 0 || pct > 100 {
+				return change, false
+			}
+			change.partTime = pct
+			change.setPartTime = true
+			i++
+		case "full-time", "fulltime":
+			change.partTime = 100
+			change.setPartTime = true
+		case "on-call", "oncall":
+			change.onCall = true
+			change.setOnCall = true
+		case "not", "off":
+			if next != "on-call" && next != "oncall" {
+				return change, false
+			}
+			change.onCall = false
+			change.setOnCall = true
+			i++
+		case "off-call":
+			change.onCall = false
+			change.setOnCall = true
+		case "for":
+			if !strings.HasPrefix(next, "<@") {
+				return change, false
+			}
+			// slack mentions look like <@U123> or <@U123|name>
+			id := strings.TrimPrefix(strings.Trim(words[i+1], ",."), "<@")
+			id = strings.TrimSuffix(id, ">")
+			change.slackID = strings.Split(id, "|")[0]
+			i++
+		default:
+			return change, false
+		}
+	}
+
+	return change, change.setPTO || change.setPartTime || change.setOnCall
+}
+
+// GetSprintVelocity - done points and working days of a team's last n finished sprints, newest first.  Done points are
+// the most recorded in tiktok_burndown while the sprint ran, sprints without burndowns or working days are left out
+func (r *Repo) GetSprintVelocity(teamID string, n int) (history []SprintVelocity, err error) {
+	type sprintRow struct {
+		name  string
+		start time.Time
+		days  float64
+		dur   int
+	}
+	var sprints []sprintRow
+
+	if err := r.ready(); err != nil {
+		return history, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT sprintname,sprintstart,workingdays,duration FROM tiktok_main WHERE teamid=? ORDER BY sprintstart DESC LIMIT ?", teamID, n+1)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetSprintVelocity` in `capacity.go`", err)
+		return history, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s sprintRow
+		var days sql.NullFloat64
+		if err := rows.Scan(&s.name, &s.start, &days, &s.dur); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetSprintVelocity` in `capacity.go`", err)
+			return history, err
+		}
+		s.days = days.Float64
+		sprints = append(sprints, s)
+	}
+	if err := rows.Err(); err != nil {
+		return history, err
+	}
+
+	for i, s := range sprints {
+		// a sprint ends when the next one starts, the newest has only finished if its duration is up
+		end := time.Now()
+		if i > 0 {
+			end = sprints[i-1].start
+		} else if s.start.AddDate(0, 0, s.dur).After(end) {
+			continue
+		}
+		if s.days <= 0 {
+			continue
+		}
+
+		var done int
+		err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(dnepts),0) FROM tiktok_burndown WHERE team=? AND pointdate>=? AND pointdate<?", teamID, s.start, end).Scan(&done)
+		if err != nil {
+			errTrap(r.tiktok, "DB Query Error for burndown of `"+s.name+"` in `GetSprintVelocity` in `capacity.go`", err)
+			return history, err
+		}
+		if done == 0 {
+			continue
+		}
+
+		history = append(history, SprintVelocity{SprintName: s.name, WorkingDays: s.days, Done: done})
+		if len(history) == n {
+			break
+		}
+	}
+
+	return history, nil
+}
+
+// GetTeamAvailability - availability of everyone who has set it for a team, for the sprint starting on start.  A row
+// dated up to a sprint's length before start still counts for it in case the rollover slipped a few days
+func (r *Repo) GetTeamAvailability(teamID string, start time.Time, duration int) (people []Availability, err error) {
+	if err := r.ready(); err != nil {
+		return people, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT a.userid,u.name,u.slackid,a.sprintstart,a.ptodays,a.parttime,a.oncall FROM tiktok_availability a JOIN tiktok_users u ON u.id=a.userid WHERE a.team=? ORDER BY a.userid, a.sprintstart DESC", teamID)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetTeamAvailability` in `capacity.go`", err)
+		return people, err
+	}
+	defer rows.Close()
+
+	// DB dates are compared as dates so time zones don't shift them
+	target := start.Format("2006-01-02")
+	windowStart := start.AddDate(0, 0, -duration).Format("2006-01-02")
+
+	seen := make(map[int]bool)
+	for rows.Next() {
+		var row Availability
+		if err := rows.Scan(&row.UserID, &row.Name, &row.SlackID, &row.SprintStart, &row.PTODays, &row.PartTime, &row.OnCall); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetTeamAvailability` in `capacity.go`", err)
+			return people, err
+		}
+
+		if len(people) == 0 || people[len(people)-1].UserID != row.UserID {
+			people = append(people, Availability{UserID: row.UserID, Name: row.Name, SlackID: row.SlackID, Team: teamID, SprintStart: start, PartTime: 100})
+		}
+
+		// newest row on or before the sprint sets part-time, and PTO and on-call if it's for this sprint
+		day := row.SprintStart.Format("2006-01-02")
+		if day > target || seen[row.UserID] {
+			continue
+		}
+		seen[row.UserID] = true
+
+		p := &people[len(people)-1]
+		p.PartTime = row.PartTime
+		if day > windowStart {
+			p.PTODays = row.PTODays
+			p.OnCall = row.OnCall
+		}
+	}
+
+	return people, rows.Err()
+}
+
+// PutAvailability - set a person's availability for a team's sprint
+func (r *Repo) PutAvailability(a Availability) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO tiktok_availability (userid,team,sprintstart,ptodays,parttime,oncall,updated) VALUES (?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE ptodays=VALUES(ptodays),parttime=VALUES(parttime),oncall=VALUES(oncall),updated=VALUES(updated)", a.UserID, a.Team, a.SprintStart.Format("2006-01-02"), a.PTODays, a.PartTime, a.OnCall, time.Now())
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `PutAvailability` in `capacity.go`", err)
+		return err
+	}
+
+	return nil
+}
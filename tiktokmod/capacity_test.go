@@ -0,0 +1,237 @@
+package tiktokmod
+
+import (
+	"database/sql/driver"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var availabilityCols = []string{"userid", "name", "slackid", "sprintstart", "ptodays", "parttime", "oncall"}
+
+// velocityRows - one finished sprint of workingDays that got done points to Done, for GetSprintVelocity
+func velocityRows(tt *testTeam, workingDays float64, done int) {
+	tt.db.on("SELECT sprintname,sprintstart,workingdays,duration FROM tiktok_main", []string{"sprintname", "sprintstart", "workingdays", "duration"},
+		[]driver.Value{"Example-01-01-2019", time.Now().AddDate(0, 0, -30), workingDays, int64(14)})
+	tt.db.on("FROM tiktok_burndown", []string{"dnepts"}, []driver.Value{int64(done)})
+}
+
+// rosterRow - a tiktok_users row for someone with a database id of id
+func rosterRow(id int64, name string, slackID string) []driver.Value {
+	row := userRow(name, slackID, strings.ToLower(name))
+	row[0] = id
+	return row
+}
+
+func TestParseAvailability(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   availabilityChange
+		wantOK bool
+	}{
+		{text: "pto 2.5", want: availabilityChange{pto: 2.5, setPTO: true}, wantOK: true},
+		{text: "part-time 60%", want: availabilityChange{partTime: 60, setPartTime: true}, wantOK: true},
+		{text: "full-time", want: availabilityChange{partTime: 100, setPartTime: true}, wantOK: true},
+		{text: "on-call, pto 1", want: availabilityChange{pto: 1, setPTO: true, onCall: true, setOnCall: true}, wantOK: true},
+		{text: "not on-call", want: availabilityChange{setOnCall: true}, wantOK: true},
+		{text: "off-call", want: availabilityChange{setOnCall: true}, wantOK: true},
+		{text: "pto 3 for <@U0002|someone>", want: availabilityChange{slackID: "U0002", pto: 3, setPTO: true}, wantOK: true},
+		{text: "for <@U0002>", want: availabilityChange{slackID: "U0002"}},
+		{text: "pto lots"},
+		{text: "pto -1"},
+		{text: "part-time 120"},
+		{text: "not today"},
+		{text: "for someone"},
+		{text: "vacation"},
+		{text: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			got, ok := parseAvailability(tc.text)
+			if ok != tc.wantOK {
+				t.Fatalf("parseAvailability(%q) ok = %v, want %v", tc.text, ok, tc.wantOK)
+			}
+			if ok && got != tc.want {
+				t.Errorf("parseAvailability(%q) = %+v, want %+v", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetTeamAvailability(t *testing.T) {
+	start := time.Date(2019, time.March, 18, 0, 0, 0, 0, time.UTC)
+
+	tt := newTestTeam(t)
+	tt.db.on("FROM tiktok_availability", availabilityCols,
+		// set for this sprint, over an older part-time row
+		[]driver.Value{int64(1), "Ann", "U0001", start, 2.0, int64(80), true},
+		[]driver.Value{int64(1), "Ann", "U0001", start.AddDate(0, 0, -28), 0.0, int64(50), false},
+		// a sprint ago, only part-time carries over
+		[]driver.Value{int64(2), "Bob", "U0002", start.AddDate(0, 0, -14), 3.0, int64(60), true},
+		// only set for a later sprint
+		[]driver.Value{int64(3), "Cat", "U0003", start.AddDate(0, 0, 14), 5.0, int64(40), true},
+	)
+
+	people, err := tt.tiktok.DB.GetTeamAvailability("example", start, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Availability{
+		{UserID: 1, Name: "Ann", SlackID: "U0001", Team: "example", SprintStart: start, PTODays: 2, PartTime: 80, OnCall: true},
+		{UserID: 2, Name: "Bob", SlackID: "U0002", Team: "example", SprintStart: start, PartTime: 60},
+		{UserID: 3, Name: "Cat", SlackID: "U0003", Team: "example", SprintStart: start, PartTime: 100},
+	}
+	if len(people) != len(want) {
+		t.Fatalf("got %d people, want %d: %+v", len(people), len(want), people)
+	}
+	for i := range want {
+		if people[i] != want[i] {
+			t.Errorf("person %d = %+v, want %+v", i, people[i], want[i])
+		}
+	}
+}
+
+func TestTeamCapacity(t *testing.T) {
+	start := time.Date(2019, time.March, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		roster       [][]driver.Value
+		availability [][]driver.Value
+		noHistory    bool
+		usersErr     error
+		wantErr      bool
+		wantPeople   int
+		wantDays     float64
+		want         float64
+	}{
+		{
+			name:       "nobody set availability",
+			roster:     [][]driver.Value{rosterRow(1, "Ann", "U0001"), rosterRow(2, "Bob", "U0002")},
+			wantPeople: 2,
+			wantDays:   20,
+			want:       20,
+		},
+		{
+			name:         "one person's PTO only takes their share",
+			roster:       [][]driver.Value{rosterRow(1, "Ann", "U0001"), rosterRow(2, "Bob", "U0002")},
+			availability: [][]driver.Value{{int64(1), "Ann", "U0001", start, 5.0, int64(100), false}},
+			wantPeople:   2,
+			wantDays:     15,
+			want:         15,
+		},
+		{
+			name:         "on-call loses the on-call load",
+			roster:       [][]driver.Value{rosterRow(1, "Ann", "U0001"), rosterRow(2, "Bob", "U0002")},
+			availability: [][]driver.Value{{int64(2), "Bob", "U0002", start, 0.0, int64(100), true}},
+			wantPeople:   2,
+			wantDays:     15,
+			want:         15,
+		},
+		{
+			name:         "part-time",
+			roster:       [][]driver.Value{rosterRow(1, "Ann", "U0001"), rosterRow(2, "Bob", "U0002")},
+			availability: [][]driver.Value{{int64(1), "Ann", "U0001", start, 0.0, int64(50), false}},
+			wantPeople:   2,
+			wantDays:     15,
+			want:         15,
+		},
+		{
+			name: "nobody on the roster",
+			want: 20,
+		},
+		{
+			name:       "no velocity history",
+			roster:     [][]driver.Value{rosterRow(1, "Ann", "U0001")},
+			noHistory:  true,
+			wantPeople: 1,
+			wantDays:   10,
+		},
+		{
+			name:     "roster can't be read",
+			usersErr: errors.New("db is down"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.opts.General.SprintDuration = 10
+			tt.opts.General.IgnoreWeekends = false
+			if !tc.noHistory {
+				velocityRows(tt, 10, 20)
+			}
+			tt.db.on("FROM tiktok_users", userCols, tc.roster...)
+			tt.db.on("FROM tiktok_availability", availabilityCols, tc.availability...)
+			if tc.usersErr != nil {
+				tt.db.fail("FROM tiktok_users", tc.usersErr)
+			}
+
+			c, err := TeamCapacity(tt.tiktok, tt.opts, &Calendar{Location: time.UTC}, start)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("TeamCapacity() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if c.WorkingDays != 10 {
+				t.Errorf("working days = %v, want 10", c.WorkingDays)
+			}
+			if len(c.People) != tc.wantPeople {
+				t.Errorf("people = %+v, want %d", c.People, tc.wantPeople)
+			}
+			if c.PersonDays != tc.wantDays {
+				t.Errorf("person-days = %v, want %v", c.PersonDays, tc.wantDays)
+			}
+			if math.Abs(c.Capacity-tc.want) > 0.001 {
+				t.Errorf("capacity = %v, want %v", c.Capacity, tc.want)
+			}
+		})
+	}
+}
+
+func TestCapacityCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		done     int
+		wantWarn bool
+	}{
+		{name: "within capacity", done: 100},
+		{name: "over capacity", done: 1, wantWarn: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.opts.General.SprintDuration = 10
+			tt.opts.General.IgnoreWeekends = false
+			velocityRows(tt, 10, tc.done)
+			tt.db.on("FROM tiktok_users", userCols, rosterRow(1, "Ann", "U0001"), rosterRow(2, "Bob", "U0002"))
+			tt.db.on("FROM tiktok_availability", availabilityCols, []driver.Value{int64(1), "Ann", "U0001", time.Now().UTC(), 2.0, int64(100), false})
+
+			msg, err := CapacityCheck(tt.tiktok, tt.trello, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sent := strings.Join(tt.slack.sent("#sprint"), "\n")
+			if !tc.wantWarn {
+				if sent != "" {
+					t.Errorf("unexpected warning %q (%s)", sent, msg)
+				}
+				return
+			}
+			for _, want := range []string{"is over capacity!", "points over capacity", "of 20.0 person-days", "Ann: 2 days PTO", "Bob: full sprint"} {
+				if !strings.Contains(sent, want) {
+					t.Errorf("warning %q doesn't contain %q", sent, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package tiktokmod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/robfig/cron"
+)
+
+// ArgKind - how a command argument is pulled out of a message
+type ArgKind int
+
+const (
+	// ArgTeam - team TOML name inside [ ], the teams config is loaded into CommandRequest.Opts
+	ArgTeam ArgKind = iota
+	// ArgBracket - raw text inside [ ]
+	ArgBracket
+	// ArgCurly - raw text inside { }
+	ArgCurly
+	// ArgNumber - integer inside { }
+	ArgNumber
+	// ArgRest - everything after the trigger (or after the closing ] if there is one)
+	ArgRest
+)
+
+// CommandArg - an argument a command takes
+type CommandArg struct {
+	Name     string
+	Kind     ArgKind
+	Required bool
+}
+
+// BotCommand - a single command tiktok acts on.  Every command lives in Commands()
+type BotCommand struct {
+	Name       string
+	Triggers   []string
+	Args       []CommandArg
+	Flags      []string
+	Permission string
+	Help       string
+	Example    string
+	Handler    func(tiktok *TikTokConf, req *CommandRequest)
+}
+
+// CommandRequest - everything a command handler needs about the message that fired it
+type CommandRequest struct {
+	Command   *BotCommand
+	Trigger   string
+	Text      string
+	Lower     string
+	Team      string
+	Opts      Config
+	Args      map[string]string
+	Flags     map[string]bool
+	User      *slack.User
+	Ev        *slack.MessageEvent
+	Out       Messenger
+	API       *slack.Client
+	Cron      *cron.Cron
+	Cronjobs  *Cronjobs
+	CronState string
+}
+
+// Reply - send a message back to the channel the command came from
+func (req *CommandRequest) Reply(message string) {
+	req.Out.SendMessage(req.Out.NewOutgoingMessage(message, req.Ev.Msg.Channel))
+}
+
+// Int - value of an ArgNumber argument, 0 if it wasn't given
+func (req *CommandRequest) Int(name string) int {
+	i, _ := strconv.Atoi(req.Args[name])
+	return i
+}
+
+// Usage - how to type the command, built from its first trigger and its arguments
+func (cmd *BotCommand) Usage() string {
+	usage := strings.TrimSpace(cmd.Triggers[0])
+
+	for _, a := range cmd.Args {
+		switch a.Kind {
+		case ArgTeam:
+			usage = usage + " [<board>]"
+		case ArgBracket:
+			usage = usage + " [" + a.Name + "]"
+		case ArgCurly, ArgNumber:
+			usage = usage + " {" + a.Name + "}"
+		case ArgRest:
+			usage = usage + " <" + a.Name + ">"
+		}
+	}
+
+	for _, f := range cmd.Flags {
+		usage = usage + " (" + f + ")"
+	}
+
+	return usage
+}
+
+// MatchCommand - find the one command a message is asking for.  The longest matching trigger wins so
+// "record chapter count" beats "chapter count", ties go to whichever command is registered first
+func MatchCommand(lowerString string) (match *BotCommand, trigger string) {
+	commands := Commands()
+
+	for i := range commands {
+		for _, t := range commands[i].Triggers {
+			if strings.Contains(lowerString, t) && len(t) > len(trigger) {
+				match = &commands[i]
+				trigger = t
+			}
+		}
+	}
+
+	return match, trigger
+}
+
+// parseArgs - pull a commands arguments and flags out of the message text
+func parseArgs(cmd *BotCommand, trigger string, text string, lower string) (args map[string]string, flags map[string]bool, missing string) {
+	args = make(map[string]string)
+	flags = make(map[string]bool)
+
+	// offsets only line up if lower casing didn't change the length of the message
+	source := text
+	if len(source) != len(lower) {
+		source = lower
+	}
+
+	for _, a := range cmd.Args {
+		var value string
+
+		switch a.Kind {
+		case ArgTeam, ArgBracket:
+			value = Between(text, "[", "]")
+		case ArgCurly, ArgNumber:
+			value = Between(text, "{", "}")
+		case ArgRest:
+			start := strings.Index(lower, trigger) + len(trigger)
+			if end := strings.Index(lower, "]"); end >= start {
+				start = end + 1
+			}
+			value = source[start:]
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			if a.Required {
+				return args, flags, a.Name
+			}
+			continue
+		}
+
+		if a.Kind == ArgNumber {
+			if _, err := strconv.Atoi(value); err != nil {
+				return args, flags, a.Name
+			}
+		}
+
+		args[a.Name] = value
+	}
+
+	for _, f := range cmd.Flags {
+		flags[f] = strings.Contains(lower, f)
+	}
+
+	return args, flags, ""
+}
+
+// DispatchCommand - run the command a message is asking for, if any.  Handles arguments, team config and permissions
+// before the handler gets called
+func DispatchCommand(text string, tiktok *TikTokConf, ev *slack.MessageEvent, out Messenger, api *slack.Client, c *cron.Cron, cronjobs *Cronjobs, CronState string) (*cron.Cron, *Cronjobs, string) {
+	var attachments Attachment
+
+	lower := strings.ToLower(text)
+
+	cmd, trigger := MatchCommand(lower)
+	if cmd == nil {
+		return c, cronjobs, CronState
+	}
+
+	req := &CommandRequest{
+		Command:   cmd,
+		Trigger:   trigger,
+		Text:      text,
+		Lower:     lower,
+		Ev:        ev,
+		Out:       out,
+		API:       api,
+		Cron:      c,
+		Cronjobs:  cronjobs,
+		CronState: CronState,
+	}
+
+	userInfo, err := api.GetUserInfo(ev.Msg.User)
+	if err != nil {
+		errTrap(tiktok, "api.GetUserInfo function error returned in `commands.go`", err)
+		userInfo = &slack.User{ID: ev.Msg.User, Name: ev.Msg.User}
+	}
+	req.User = userInfo
+
+	args, flags, missing := parseArgs(cmd, trigger, text, lower)
+	req.Args = args
+	req.Flags = flags
+
+	if missing != "" {
+		for _, a := range cmd.Args {
+			if a.Name == missing && a.Kind == ArgTeam {
+				attachments.Color = "#0000CC"
+				attachments.Text = ListAllTOML(tiktok)
+				Wrangler(tiktok.Config.SlackHook, "Please specify team in [ ] - Like `@"+tiktok.Config.BotName+" "+cmd.Usage()+"`\nHere's a list: ", ev.Msg.Channel, tiktok.Config.SlackEmoji, attachments)
+
+				return req.Cron, req.Cronjobs, req.CronState
+			}
+		}
+
+		req.Reply("I'm not sure what you are asking me to do, I need `" + missing + "`.  Try: ```@" + tiktok.Config.BotName + " " + cmd.Usage() + "```")
+		return req.Cron, req.Cronjobs, req.CronState
+	}
+
+	switch cmd.Permission {
+	case "scrum":
+		if !Permissions(tiktok, ev.Msg.User, "scrum", api, tiktok.Config.ScrumControlChannel) {
+			req.Reply("You are not the boss of me! Permission denied.")
+			LogToSlack(userInfo.Name+" asked me to `"+cmd.Name+"` but did not have permissions so I ignored them.", tiktok, attachments)
+			return req.Cron, req.Cronjobs, req.CronState
+		}
+	case "admin":
+		if !Permissions(tiktok, ev.Msg.User, "admin", api, tiktok.Config.AdminSlackChannel) {
+			req.Reply("You are not the boss of me! Permission denied.")
+			LogToSlack(userInfo.Name+" asked me to `"+cmd.Name+"` but did not have permissions so I ignored them.", tiktok, attachments)
+			return req.Cron, req.Cronjobs, req.CronState
+		}
+	}
+
+	for _, a := range cmd.Args {
+		if a.Kind == ArgTeam && args[a.Name] != "" {
+			req.Team = args[a.Name]
+
+			opts, err := LoadConf(tiktok, req.Team)
+			if err != nil {
+				errTrap(tiktok, "Load Conf Error for TeamID "+req.Team, err)
+				req.Reply("I couldn't find the team config file (" + req.Team + ".toml) you asked for!.")
+				return req.Cron, req.Cronjobs, req.CronState
+			}
+			req.Opts = opts
+		}
+	}
+
+	if tiktok.Config.DEBUG {
+		fmt.Println("Running command `" + cmd.Name + "` for " + userInfo.Name)
+	}
+
+	cmd.Handler(tiktok, req)
+	commandsHandled.Inc(cmd.Name)
+
+	return req.Cron, req.Cronjobs, req.CronState
+}
+
+// Commands - every command tiktok knows.  Help() is built from this list so keep the help text honest
+func Commands() []BotCommand {
+	return []BotCommand{
+		{
+			Name:     "built-in test",
+			Triggers: []string{"run builtin test", "run built-in test"},
+			Handler:  cmdBuiltinTest,
+		},
+		{
+			Name:     "check retro actions",
+			Triggers: []string{"check retro action activity"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll check the sprint retro boards for action item cards with no activity",
+			Handler:  cmdRetroActions,
+		},
+		{
+			Name:     "chapter points",
+			Triggers: []string{"chapter points"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll add up points per chapter in a column (next sprint, ready for work, working, etc - default Backlog)",
+			Handler:  cmdChapterPoints,
+		},
+		{
+			Name:     "critical bugs",
+			Triggers: []string{"are there any critical bugs", "check for critical bugs"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam}},
+			Help:     "I'll look for critical bugs and alert the complaint channel if I find any",
+			Handler:  cmdCriticalBugs,
+		},
+		{
+			Name:     "record chapter count",
+			Triggers: []string{"record chapter count"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll record the chapter card counts in a column to the database",
+			Handler:  cmdRecordChapterCount,
+		},
+		{
+			Name:     "chapter count",
+			Triggers: []string{"check chapters", "chapter count"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll count the cards per chapter in a column",
+			Handler:  cmdChapterCount,
+		},
+		{
+			Name:     "card data",
+			Triggers: []string{"get card data"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Flags:    []string{"db only"},
+			Help:     "I'll pull card timing data for the board and send a CSV, or only record it to the database with `db only`",
+			Handler:  cmdCardData,
+		},
+		{
+			Name:     "check themes",
+			Triggers: []string{"check themes"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll check Upcoming, Scoped and Ready for Work cards have Theme labels",
+			Handler:  cmdCheckThemes,
+		},
+		{
+			Name:     "description history",
+			Triggers: []string{"description history"},
+			Args:     []CommandArg{{Name: "cardID", Kind: ArgRest, Required: true}},
+			Help:     "Well return the historical card description data for a given card ID.  Look in a card URL to get its ID #",
+			Example:  "description history pBxxmKI6",
+			Handler:  cmdDescriptionHistory,
+		},
+		{
+			Name:     "company holidays",
+			Triggers: []string{"company holidays"},
+			Flags:    []string{"company holidays all"},
+			Help:     "I will return a list of company Holidays that I know about.",
+			Handler:  cmdHolidays,
+		},
+		{
+			Name:       "record points",
+			Triggers:   []string{"record points for"},
+			Args:       []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Permission: "scrum",
+			Help:       "I'll record todays sprint points for the board",
+			Handler:    cmdRecordPoints,
+		},
+		{
+			Name:     "count cards",
+			Triggers: []string{"count cards"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll count the cards per theme (label) in `Un-Scoped` and `Ready for Points`",
+			Handler:  cmdCountCards,
+		},
+		{
+			Name:     "theme points",
+			Triggers: []string{"theme points"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}, {Name: "total points", Kind: ArgNumber}},
+			Help:     "I'll add up points per theme (label) in next sprint, ready for points or ready for work.  Give me total points to get percentages",
+			Handler:  cmdThemePoints,
+		},
+		{
+			Name:     "previous sprint points",
+			Triggers: []string{"previous sprint points"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}, {Name: "SprintName", Kind: ArgRest, Required: true}},
+			Help:     "will return points by squad for previous sprint named `SprintName`",
+			Example:  "previous sprint points [mcboard] mcboard-08-25-2018",
+			Handler:  cmdPreviousSprintPoints,
+		},
+		{
+			Name:     "squad points",
+			Triggers: []string{"squad points"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll add up points per squad in next sprint, ready for points, ready for work or working",
+			Handler:  cmdSquadPoints,
+		},
+		{
+			Name:     "retro board",
+			Triggers: []string{"retro board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "will return the URL to the current sprint retro board for that team",
+			Handler:  cmdRetroBoard,
+		},
+		{
+			Name:     "list PRs",
+			Triggers: []string{"list pr", "open pr"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll list the cards waiting on review",
+			Handler:  cmdListPR,
+		},
+		{
+			Name:     "dupe board",
+			Triggers: []string{"dupe trello board", "copy trello board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I will make a copy of this board and name it DUPE-M-D-Y<board name> and assign it to the `Board Copies` Collection",
+			Example:  "dupe trello board [mcboard]",
+			Handler:  cmdDupeBoard,
+		},
+		{
+			Name:     "reload cron",
+			Triggers: []string{"reload cron", "re-load cron", "reload all cron"},
+			Help:     "I will re-read cron.toml and reload all the cron jobs in it",
+			Handler:  cmdReloadCron,
+		},
+		{
+			Name:     "list cronjobs",
+			Triggers: []string{"list all cronjobs", "show me all cronjobs", "list cronjobs"},
+			Help:     "I will list all programmed cron jobs that I know about",
+			Handler:  cmdListCron,
+		},
+		{
+			Name:     "job history",
+			Triggers: []string{"job history", "cron history"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll show the last week of cron runs for a board and flag any job that hasn't succeeded since it was last due",
+			Handler:  cmdJobHistory,
+		},
+		{
+			Name:     "refresh board",
+			Triggers: []string{"refresh board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll forget what I cached about a board and load it fresh from trello",
+			Handler:  cmdRefreshBoard,
+		},
+		{
+			Name:       "start a new sprint",
+			Triggers:   []string{"start a new sprint"},
+			Args:       []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Flags:      []string{"suppress retro", "dry run"},
+			Permission: "scrum",
+			Help:       "I'll setup a new sprint for your board.  Add `dry run` and I'll only tell you what I would do",
+			Handler:    cmdNewSprint,
+		},
+		{
+			Name:       "undo last sprint",
+			Triggers:   []string{"undo last sprint"},
+			Args:       []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Permission: "scrum",
+			Help:       "I'll put the board back the way it was before the last `start a new sprint`, archive its retro board and make the old sprint current again",
+			Handler:    cmdUndoSprint,
+		},
+		{
+			Name:       "stop all cron",
+			Triggers:   []string{"stop all cron", "shutdown all cron", "halt all cron"},
+			Permission: "scrum",
+			Help:       "I will disable all running cronjobs until told otherwise",
+			Handler:    cmdStopCron,
+		},
+		{
+			Name:       "shutdown",
+			Triggers:   []string{"shutdown please"},
+			Permission: "admin",
+			Help:       "I will shut all services down and log out of slack",
+			Handler:    cmdShutdown,
+		},
+		{
+			Name:     "build a configuration file",
+			Triggers: []string{"build a configuration file"},
+			Args:     []CommandArg{{Name: "<trello board ID>", Kind: ArgBracket, Required: true}},
+			Help:     "I will run through any trello board and find the Unique ID's you need to build a .toml file for your board!",
+			Handler:  cmdBuildConfig,
+		},
+		{
+			Name:     "troll board",
+			Triggers: []string{"troll team", "troll board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll run the point, member and theme alerting on the board",
+			Handler:  cmdTroll,
+		},
+		{
+			Name:     "clean the backlog",
+			Triggers: []string{"clean the backlog"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll troll the backlog for clean up",
+			Handler:  cmdCleanBacklog,
+		},
+		{
+			Name:     "archive the backlog",
+			Triggers: []string{"archive the backlog"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll archive old cards in the backlog",
+			Handler:  cmdArchiveBacklog,
+		},
+		{
+			Name:     "archiving on board",
+			Triggers: []string{"archiving on board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll archive old cards in the Done list",
+			Handler:  cmdArchiveDone,
+		},
+		{
+			Name:     "scan for PRs",
+			Triggers: []string{"scan for pr"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll scan for stale PR cards",
+			Handler:  cmdStalePR,
+		},
+		{
+			Name:     "backup board",
+			Triggers: []string{"backup board", "back up board"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll write a full export of the board, cards, comments and history included, to my backup directory",
+			Handler:  cmdBackupBoard,
+		},
+		{
+			Name:     "blockers",
+			Triggers: []string{"blockers"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll list the cards on the board that are blocked by other cards, and what is blocking them",
+			Handler:  cmdBlockers,
+		},
+		{
+			Name:     "check due dates",
+			Triggers: []string{"check due dates"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll alert on overdue cards and cards due before the end of the sprint that haven't been started",
+			Handler:  cmdDueDates,
+		},
+		{
+			Name:     "set availability",
+			Triggers: []string{"set availability", "set my availability"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}, {Name: "availability", Kind: ArgRest, Required: true}},
+			Help:     "I'll record your PTO days, part-time percent and on-call for the team's next sprint, add `for @someone` to set theirs (scrum only)",
+			Example:  "set availability [mcboard] pto 2 part-time 50 on-call",
+			Handler:  cmdSetAvailability,
+		},
+		{
+			Name:     "sprint capacity",
+			Triggers: []string{"sprint capacity"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll work out the next sprint's capacity from velocity and availability and compare it with the points planned",
+			Handler:  cmdCapacity,
+		},
+		{
+			Name:     "sync points",
+			Triggers: []string{"sync points"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll syncronize story points to the Burndown field and alert on changed points",
+			Handler:  cmdSyncPoints,
+		},
+		{
+			Name:     "list boards",
+			Triggers: []string{"is your trello board", "list available boards", "list all boards", "show me all boards", "list available trello boards", "list all trello boards"},
+			Help:     "I will list all of the Trello Team/Boards I have TOML configurations for and their access name",
+			Handler:  cmdListBoards,
+		},
+		{
+			Name:     "add me",
+			Triggers: []string{"add me", "register me"},
+			Args:     []CommandArg{{Name: "email,trello id,github id", Kind: ArgBracket}},
+			Help:     "register yourself with Tik-Tok so he knows your ID's. No quotes needed around items with spaces or special characters",
+			Handler:  cmdAddMe,
+		},
+		{
+			Name:       "add a new user",
+			Triggers:   []string{"add a new user"},
+			Args:       []CommandArg{{Name: "name,email,slackID,trello,github", Kind: ArgBracket}},
+			Permission: "scrum",
+			Help:       "register someone else with Tik-Tok",
+			Handler:    cmdAddUser,
+		},
+		{
+			Name:     "retro card",
+			Triggers: []string{"retro card"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}, {Name: "my card info", Kind: ArgRest, Required: true}},
+			Help:     "<well|wrong|vent> retro card - will create a new card on the current sprint retro board for that team in the Well or Wrong list",
+			Example:  "well retro card [mcboard] this sprint went awesome!",
+			Handler:  cmdRetroCard,
+		},
+		{
+			Name:     "ignore label",
+			Triggers: []string{"ignore label"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}, {Name: "myLabel", Kind: ArgCurly, Required: true}},
+			Help:     "I'll leave this label out of theme point counts",
+			Handler:  cmdIgnoreLabel,
+		},
+		{
+			Name:     "check epic links",
+			Triggers: []string{"check epic links"},
+			Args:     []CommandArg{{Name: "board", Kind: ArgTeam, Required: true}},
+			Help:     "I'll check `Feature` cards have Epic links",
+			Handler:  cmdEpicLinks,
+		},
+		{
+			Name:     "what time is it",
+			Triggers: []string{"what time is it"},
+			Help:     "I'll tell you what time I think it is",
+			Handler:  cmdWhatTime,
+		},
+		{
+			Name:     "list registered users",
+			Triggers: []string{"list registered users", "get registered users"},
+			Help:     "I'll list everyone registered with me",
+			Handler:  cmdListUsers,
+		},
+		{
+			Name:     "list github repos",
+			Triggers: []string{"list repo", "list github repo"},
+			Help:     "Will DM user all github `repos`",
+			Handler:  cmdListRepos,
+		},
+		{
+			Name:     "list github users",
+			Triggers: []string{"list users github"},
+			Help:     "Will DM user all github `users`",
+			Handler:  cmdListGithubUsers,
+		},
+		{
+			Name:     "list pull requests",
+			Triggers: []string{"list pull request"},
+			Args:     []CommandArg{{Name: "repo", Kind: ArgRest, Required: true}},
+			Help:     "I'll list the open Pull Requests on a github repo",
+			Handler:  cmdListPulls,
+		},
+		{
+			Name:     "help",
+			Triggers: []string{"help", "what do you do", "what can you do", "who are you"},
+			Help:     "I'll DM you this list",
+			Handler:  cmdHelp,
+		},
+	}
+}
@@ -1,8 +1,6 @@
 package tiktokmod
 
 import (
-	"encoding/json"
-	"net/http"
 	"strings"
 
 	"github.com/nlopes/slack"
@@ -109,19 +107,11 @@ type Labels struct {
 func GetBoardCustoms(boardID string, tiktok *TikTokConf) (customList CustomCollection, err error) {
 	url := "https://api.trello.com/1/boards/" + boardID + "/customFields?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
+	err = trelloDo(tiktok, "GET", url, nil, &customList)
 	if err != nil {
-		errTrap(tiktok, "Error http Request to trello in `configme.go` func `GetBoardCustoms`", err)
+		errTrap(tiktok, "Error calling trello in `configme.go` func `GetBoardCustoms`", err)
 		return customList, err
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error http.Client request to trello in `configme.go` func `GetBoardCustoms`", err)
-		return customList, err
-	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&customList)
 
 	return customList, err
 }
@@ -130,19 +120,11 @@ func GetBoardCustoms(boardID string, tiktok *TikTokConf) (customList CustomColle
 func GetBoardLabels(boardID string, tiktok *TikTokConf) (labelList LabelCollection, err error) {
 	url := "https://api.trello.com/1/boards/" + boardID + "/labels?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error http Request to trello in `configme.go` func `GetBoardLabels`", err)
-		return labelList, err
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "GET", url, nil, &labelList)
 	if err != nil {
-		errTrap(tiktok, "Error http.Client request to trello in `configme.go` func `GetBoardLabels`", err)
+		errTrap(tiktok, "Error calling trello in `configme.go` func `GetBoardLabels`", err)
 		return labelList, err
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&labelList)
 
 	return labelList, err
 }
@@ -162,7 +144,7 @@ func BuildConfig(boardID string, tiktok *TikTokConf, user string, api *slack.Cli
 	userInfo, _ := api.GetUserInfo(user)
 
 	// handle columns
-	listData, err := GetLists(tiktok, boardID)
+	listData, err := tiktok.Trello.GetLists(boardID)
 	if err != nil {
 		errTrap(tiktok, "Trying to run Config Builder for "+userInfo.Name+" but had request for all lists on board `"+boardID+"` returned error.", err)
 		return
@@ -310,6 +292,7 @@ func BuildConfig(boardID string, tiktok *TikTokConf, user string, api *slack.Cli
 	message = message + "TrainingLabel      = " + config.TrainingLabel + "\n"
 	message = message + "SilenceCardLabel   = " + config.SilenceCardLabel + "\n"
 	message = message + "```"
+	message = message + "\nLists and labels can also be given by name instead, IE: `Working = \"" + NamePrefix + WorkingName + "\"`"
 
 	myPayload.Text = message
 	_ = WranglerDM(tiktok, myPayload)
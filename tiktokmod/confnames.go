@@ -0,0 +1,155 @@
+package tiktokmod
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// NamePrefix - a team TOML list or label value starting with this is a trello name to look up, not a UID.
+// IE: Working = "name:Working"
+const NamePrefix = "name:"
+
+// boardNames - list and label names to UIDs for one board
+type boardNames struct {
+	lists  map[string][]string
+	labels map[string][]string
+}
+
+// nameCache - board names looked up by LoadConf.  UIDs never change so a board is only asked for once, until someone
+// runs `refresh board` or a name can't be found
+type nameCache struct {
+	mu     sync.Mutex
+	boards map[string]boardNames
+}
+
+var names = nameCache{boards: make(map[string]boardNames)}
+
+func (c *nameCache) get(tiktok *TikTokConf, boardID string) (boardNames, error) {
+	c.mu.Lock()
+	bn, ok := c.boards[boardID]
+	c.mu.Unlock()
+	if ok {
+		return bn, nil
+	}
+
+	trello := tiktok.Trello
+	if trello == nil {
+		trello = NewTrelloClient(tiktok)
+	}
+
+	bn = boardNames{lists: make(map[string][]string), labels: make(map[string][]string)}
+
+	lists, err := trello.GetLists(boardID)
+	if err != nil {
+		return bn, err
+	}
+	for _, l := range lists {
+		if !l.Closed {
+			key := strings.ToLower(strings.TrimSpace(l.Name))
+			bn.lists[key] = append(bn.lists[key], l.ID)
+		}
+	}
+
+	labels, err := trello.GetLabel(boardID)
+	if err != nil {
+		return bn, err
+	}
+	for _, l := range labels {
+		key := strings.ToLower(strings.TrimSpace(l.Name))
+		bn.labels[key] = append(bn.labels[key], l.ID)
+	}
+
+	c.mu.Lock()
+	c.boards[boardID] = bn
+	c.mu.Unlock()
+
+	return bn, nil
+}
+
+// forget - look a board's names up again next time
+func (c *nameCache) forget(boardID string) {
+	c.mu.Lock()
+	delete(c.boards, boardID)
+	c.mu.Unlock()
+}
+
+// ResolveNames - swap any "name:" list and label values in a team config for their trello UIDs.  Names are matched
+// case-insensitively and must match exactly one open list or label on the board
+func ResolveNames(tiktok *TikTokConf, configLocation string, opts *Config) error {
+	g := &opts.General
+
+	lists := []struct {
+		field string
+		value *string
+	}{
+		{"BacklogID", &g.BacklogID},
+		{"Upcoming", &g.Upcoming},
+		{"Scoped", &g.Scoped},
+		{"NextsprintID", &g.NextsprintID},
+		{"ReadyForWork", &g.ReadyForWork},
+		{"Working", &g.Working},
+		{"ReadyForReview", &g.ReadyForReview},
+		{"Done", &g.Done},
+	}
+	labels := []struct {
+		field string
+		value *string
+	}{
+		{"ROLabelID", &g.ROLabelID},
+		{"TemplateLabelID", &g.TemplateLabelID},
+		{"AllowMembersLabel", &g.AllowMembersLabel},
+		{"TrainingLabel", &g.TrainingLabel},
+		{"SilenceCardLabel", &g.SilenceCardLabel},
+		{"BlockedLabel", &g.BlockedLabel},
+	}
+
+	wanted := false
+	for _, l := range lists {
+		wanted = wanted || strings.HasPrefix(*l.value, NamePrefix)
+	}
+	for _, l := range labels {
+		wanted = wanted || strings.HasPrefix(*l.value, NamePrefix)
+	}
+	if !wanted {
+		return nil
+	}
+
+	bn, err := names.get(tiktok, g.BoardID)
+	if err != nil {
+		return errors.New("unable to look up list and label names on board " + g.BoardID + " for " + configLocation + ": " + err.Error())
+	}
+
+	message := ""
+	lookup := func(field string, value *string, kind string, found map[string][]string) {
+		if !strings.HasPrefix(*value, NamePrefix) {
+			return
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(*value, NamePrefix))
+		ids := found[strings.ToLower(name)]
+
+		switch len(ids) {
+		case 1:
+			*value = ids[0]
+		case 0:
+			message = message + field + ": no " + kind + " named \"" + name + "\" on board " + g.BoardID + "\n"
+		default:
+			message = message + field + ": " + strings.Join(ids, ", ") + " are all " + kind + "s named \"" + name + "\", use the UID instead\n"
+		}
+	}
+
+	for _, l := range lists {
+		lookup(l.field, l.value, "list", bn.lists)
+	}
+	for _, l := range labels {
+		lookup(l.field, l.value, "label", bn.labels)
+	}
+
+	if message != "" {
+		// the board may have changed since we cached it, ask again next time
+		names.forget(g.BoardID)
+		return errors.New("configuration file " + configLocation + " has names that can't be resolved:\n" + message)
+	}
+
+	return nil
+}
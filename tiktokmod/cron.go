@@ -3,6 +3,7 @@ package tiktokmod
 // Manages CRON job calls to functions
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -34,21 +35,58 @@ func localLoad(tiktok *TikTokConf, teamID string) (opts Config, err error) {
 func HolidayTroll(tiktok *TikTokConf, teamID string, job string, dummy bool) {
 	var attachments Attachment
 
+	lock, ok := lockCron(tiktok, "holidays", teamID)
+	if !ok {
+		return
+	}
+	defer lock.release()
+
+	run := startCronRun(tiktok, "holidays", teamID)
+
 	opts, err := localLoad(tiktok, teamID)
 	if err != nil {
+		run.finish(tiktok, false, err)
 		errTrap(tiktok, "CRON ISSUE: Error Loading teamID `"+teamID+"` in HolidayTroll in `cron.go`", err)
 		return
 	}
 
-	// Check for Holiday
-	isHoliday, holiday := IsHoliday(tiktok, time.Now())
-	if isHoliday && opts.General.HolidaySupport {
+	// Check for Holiday, company wide or the team's own.  Half days are still working days
+	holiday, isHoliday := TeamCalendar(tiktok, opts).Holiday(time.Now())
+	if isHoliday && !holiday.HalfDay {
 		if strings.ToLower(holiday.Name) == "saas off-site" {
-			Wrangler(tiktok.Config.SlackHook, "I'm at the SaaS Off-Site today so I'm not doing my regular routine. "+holiday.Message, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+			TeamWrangler(tiktok, opts, "I'm at the SaaS Off-Site today so I'm not doing my regular routine. "+holiday.Message, opts.General.ComplaintChannel, attachments)
+		} else if holiday.Team != "" {
+			TeamWrangler(tiktok, opts, "I'm not working today, it's a team Holiday! "+holiday.Message, opts.General.ComplaintChannel, attachments)
 		} else {
-			Wrangler(tiktok.Config.SlackHook, "I'm not working today, it's a company Holiday! "+holiday.Message, opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+			TeamWrangler(tiktok, opts, "I'm not working today, it's a company Holiday! "+holiday.Message, opts.General.ComplaintChannel, attachments)
 		}
 	}
+
+	run.finish(tiktok, false, nil)
+}
+
+// cronRun - a cron execution being counted in /metrics and recorded to tiktok_cron_runs
+type cronRun struct {
+	id     int64
+	action string
+	board  string
+}
+
+func startCronRun(tiktok *TikTokConf, action string, board string) cronRun {
+	cronRuns.Inc(action, board)
+
+	// a DB outage shouldn't stop the job itself from running
+	id, _ := tiktok.DB.StartCronRun(action, board)
+
+	return cronRun{id: id, action: action, board: board}
+}
+
+func (run cronRun) finish(tiktok *TikTokConf, holidaySkip bool, err error) {
+	if err != nil {
+		cronFailures.Inc(run.action, run.board)
+	}
+
+	tiktok.DB.FinishCronRun(run.id, holidaySkip, err)
 }
 
 // StandardCron - Execute requested cron job
@@ -58,19 +96,29 @@ func StandardCron(tiktok *TikTokConf, teamID string, job string, holiday bool) {
 	var returnMsg string
 	var opts Config
 
+	lock, ok := lockCron(tiktok, job, teamID)
+	if !ok {
+		return
+	}
+	defer lock.release()
+
+	run := startCronRun(tiktok, job, teamID)
+
 	opts, err = localLoad(tiktok, teamID)
 	if err != nil {
+		run.finish(tiktok, false, err)
 		errTrap(tiktok, "CRON ISSUE: Error Loading teamID `"+teamID+"` in `"+job+"` in `cron.go`", err)
 		return
 	}
 
 	if holiday {
-		isHoliday, holiday := IsHoliday(tiktok, time.Now())
-		if isHoliday && opts.General.HolidaySupport {
+		holiday, isHoliday := TeamCalendar(tiktok, opts).Holiday(time.Now())
+		if isHoliday && !holiday.HalfDay {
 			if tiktok.Config.LogToSlack {
 				LogToSlack("Today is Holiday, skipping cron job `"+job+"`. ("+holiday.Name+")", tiktok, attachments)
 			}
 
+			run.finish(tiktok, true, nil)
 			return
 		}
 	}
@@ -79,48 +127,57 @@ func StandardCron(tiktok *TikTokConf, teamID string, job string, holiday bool) {
 		LogToSlack("Executing CRON `"+job+"` on team *"+teamID+"*", tiktok, attachments)
 	}
 
-	// Go runs faster then 1 second on cron jobs, so multiple runs happen per second.  this sleeps 1 sec
-	time.Sleep(1000 * time.Millisecond)
-
 	switch job {
 	case "troll":
-		returnMsg, err = AlertRunner(opts, tiktok)
-		SkippedPR(tiktok, opts)
+		returnMsg, err = AlertRunner(opts, tiktok, tiktok.Trello)
+		SkippedPR(tiktok, tiktok.Trello, opts)
+		DoneChecklists(tiktok, tiktok.Trello, opts)
+		BlockerCheck(tiktok, tiktok.Trello, opts)
 	case "pr-summary":
-		returnMsg, err = PRSummary(opts, tiktok)
+		returnMsg, err = PRSummary(opts, tiktok, tiktok.Trello)
 	case "templatecheck":
-		TemplateCard(tiktok, opts)
+		TemplateCard(tiktok, tiktok.Trello, opts)
 	case "retroaction":
-		CheckActionCards(tiktok, opts, teamID)
+		CheckActionCards(tiktok, tiktok.Trello, opts, teamID)
 	case "chapter-count":
-		err = RecordChapters(tiktok, teamID, "backlog")
+		err = RecordChapters(tiktok, tiktok.Trello, teamID, "backlog")
 	case "count-cards":
-		_, err = CountCards(opts, tiktok, teamID)
+		_, err = CountCards(opts, tiktok, tiktok.Trello, teamID)
 	case "record-pts":
-		sOpts, err := GetDBSprint(tiktok, teamID)
+		var sOpts SprintData
+		sOpts, err = tiktok.DB.GetSprint(teamID)
 		if err != nil {
+			run.finish(tiktok, false, err)
 			errTrap(tiktok, "CRON ISSUE: SQL error in `GetDBSprint` in `cron.go`", err)
 			return
 		}
-		_, _ = GetAllPoints(tiktok, opts, sOpts)
+		if _, valid := GetAllPoints(tiktok, tiktok.Trello, opts, sOpts); !valid {
+			err = errors.New("GetAllPoints could not record points for sprint " + sOpts.SprintName)
+		}
 	case "sprint":
-		returnMsg, err = Sprint(opts, tiktok, false)
+		returnMsg, err = Sprint(opts, tiktok, tiktok.Trello, false)
 	case "pr-alert":
-		returnMsg, err = StalePRcards(opts, tiktok)
+		returnMsg, err = StalePRcards(opts, tiktok, tiktok.Trello)
+	case "due-dates":
+		returnMsg, err = DueDates(tiktok, tiktok.Trello, opts, teamID)
+	case "capacity":
+		returnMsg, err = CapacityCheck(tiktok, tiktok.Trello, opts)
+	case "backup":
+		returnMsg, err = BackupBoard(tiktok, opts, teamID)
 	case "points":
-		returnMsg = PointCleanup(opts, tiktok, teamID)
+		returnMsg = PointCleanup(opts, tiktok, tiktok.Trello, teamID)
 	case "archive":
-		returnMsg, err = CleanDone(opts, tiktok)
+		returnMsg, err = CleanDone(opts, tiktok, tiktok.Trello)
 	case "":
-		err = CleanBackLog(opts, tiktok)
+		err = CleanBackLog(opts, tiktok, tiktok.Trello)
 	case "backlogarchive":
-		err = ArchiveBacklog(tiktok, opts)
+		err = ArchiveBacklog(tiktok, tiktok.Trello, opts)
 	case "critical-bug":
-		_ = CheckBugs(opts, tiktok)
+		_ = CheckBugs(opts, tiktok, tiktok.Trello)
 	case "epic-links":
-		EpicLink(tiktok, opts)
+		EpicLink(tiktok, tiktok.Trello, opts)
 	case "cardloader":
-		CardPlay(tiktok, opts, "", teamID, false)
+		CardPlay(tiktok, tiktok.Trello, opts, "", teamID, false)
 	case "standupalert":
 		SendAlert(tiktok, opts, "standup")
 	case "demoalert":
@@ -134,6 +191,7 @@ func StandardCron(tiktok *TikTokConf, teamID string, job string, holiday bool) {
 	if tiktok.Config.LogToSlack {
 		LogToSlack("Cron job "+job+" returned message "+returnMsg, tiktok, attachments)
 	}
+	run.finish(tiktok, false, err)
 	if err != nil {
 		errTrap(tiktok, "Error returned running Cron job `"+job+"` function in cron.go for team "+teamID, err)
 	}
@@ -177,6 +235,12 @@ func CronLoad(tiktok *TikTokConf) (cronjobs *Cronjobs, c *cron.Cron, err error)
 			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "wdwalert", true))
 		case "pr-alert":
 			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "pr-alert", true))
+		case "due-dates":
+			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "due-dates", true))
+		case "capacity":
+			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "capacity", true))
+		case "backup":
+			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "backup", false))
 		case "troll":
 			c.AddFunc(j.Timing, newCron(StandardCron, tiktok, j.Config, "troll", true))
 		case "sprint":
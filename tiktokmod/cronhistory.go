@@ -0,0 +1,201 @@
+package tiktokmod
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// CronRun - one execution of a cron job from tiktok_cron_runs
+type CronRun struct {
+	ID          int64
+	Action      string
+	Board       string
+	Started     time.Time
+	Finished    time.Time
+	HolidaySkip bool
+	Outcome     string
+	Error       string
+}
+
+// StartCronRun - record that a cron job has started, returns the run ID to finish it with
+func (r *Repo) StartCronRun(action string, board string) (id int64, err error) {
+	if err := r.ready(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	now := time.Now().Local()
+	res, err := r.db.ExecContext(ctx, "INSERT tiktok_cron_runs SET action=?,board=?,started=?,finished=?,holidayskip=0,outcome='running'", action, board, now, now)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error recording cron start in `StartCronRun` in `cronhistory.go`", err)
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// FinishCronRun - record how a cron job ended.  runErr is nil on success
+func (r *Repo) FinishCronRun(id int64, holidaySkip bool, runErr error) error {
+	var errText string
+
+	if id == 0 {
+		return nil
+	}
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	outcome := "success"
+	switch {
+	case runErr != nil:
+		outcome = "failed"
+		errText = runErr.Error()
+	case holidaySkip:
+		outcome = "skipped"
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE tiktok_cron_runs SET finished=?,holidayskip=?,outcome=?,error=? WHERE id=?", time.Now().Local(), holidaySkip, outcome, errText, id)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error recording cron finish in `FinishCronRun` in `cronhistory.go`", err)
+	}
+
+	return err
+}
+
+// GetCronRuns - cron runs for a board since a given time, newest first
+func (r *Repo) GetCronRuns(board string, since time.Time) (runs []CronRun, err error) {
+	if err := r.ready(); err != nil {
+		return runs, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id,action,board,started,finished,holidayskip,outcome,error FROM tiktok_cron_runs WHERE board=? AND started>=? ORDER BY started DESC", board, since)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetCronRuns` in `cronhistory.go`", err)
+		return runs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run CronRun
+
+		if err := rows.Scan(&run.ID, &run.Action, &run.Board, &run.Started, &run.Finished, &run.HolidaySkip, &run.Outcome, &run.Error); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetCronRuns` in `cronhistory.go`", err)
+			return runs, err
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// cronJobName - the job name StandardCron records for a crons.toml action
+func cronJobName(action string) string {
+	if action == "clean-backlog" {
+		return "backlogarchive"
+	}
+	return action
+}
+
+// lastDue - the most recent time a cron schedule should have fired at or before now, zero if not in the last 35 days
+func lastDue(timing string, now time.Time) time.Time {
+	var due time.Time
+
+	sched, err := cron.Parse(timing)
+	if err != nil {
+		return due
+	}
+
+	for t := sched.Next(now.AddDate(0, 0, -35)); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		due = t
+	}
+
+	return due
+}
+
+// CronHistory - slack formatted summary of a board's recent cron runs.  Flags any scheduled job without a successful
+// (or holiday skipped) run since it was last due
+func CronHistory(tiktok *TikTokConf, cronjobs *Cronjobs, board string, days int) (message string, overdue int, err error) {
+	now := time.Now().Local()
+
+	runs, err := tiktok.DB.GetCronRuns(board, now.AddDate(0, 0, -days))
+	if err != nil {
+		return message, 0, err
+	}
+
+	// jobs get 15 minutes to finish before they count as late
+	grace := 15 * time.Minute
+
+	if cronjobs != nil {
+		for _, j := range cronjobs.Cronjob {
+			if j.Config != board {
+				continue
+			}
+
+			action := cronJobName(j.Action)
+			due := lastDue(j.Timing, now.Add(-grace))
+			if due.IsZero() {
+				continue
+			}
+
+			ok := false
+			for _, run := range runs {
+				if run.Action == action && !run.Started.Before(due.Add(-time.Minute)) && (run.Outcome == "success" || run.Outcome == "skipped") {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				overdue++
+				message = message + ":warning: `" + action + "` was due " + due.Format("01/02 15:04") + " and has not succeeded since\n"
+			}
+		}
+	}
+
+	if len(runs) == 0 {
+		return message + "No cron runs recorded for `" + board + "` in the last " + strconv.Itoa(days) + " days\n", overdue, nil
+	}
+
+	seen := make(map[string]bool)
+	message = message + "```"
+	for _, run := range runs {
+		if seen[run.Action] {
+			continue
+		}
+		seen[run.Action] = true
+
+		total := 0
+		failed := 0
+		for _, r := range runs {
+			if r.Action == run.Action {
+				total++
+				if r.Outcome == "failed" {
+					failed++
+				}
+			}
+		}
+
+		line := run.Action + " - last " + run.Started.Format("01/02 15:04") + " " + run.Outcome
+		if run.Outcome != "running" {
+			line = line + " (" + run.Finished.Sub(run.Started).Round(time.Second).String() + ")"
+		}
+		line = line + " - " + strconv.Itoa(total) + " runs, " + strconv.Itoa(failed) + " failed"
+		if run.Error != "" {
+			line = line + "\n    " + run.Error
+		}
+		message = message + line + "\n"
+	}
+	message = message + "```"
+
+	return message, overdue, nil
+}
@@ -0,0 +1,172 @@
+package tiktokmod
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// cronLeaseTTL - how long a DB lease lasts without being renewed.  A crashed instance's lease frees up after this
+	cronLeaseTTL = 5 * time.Minute
+	// cronSettle - how long a finished job stays locked so duplicate firings and clock skew between instances don't
+	// run it twice for the same tick
+	cronSettle = 30 * time.Second
+)
+
+// cronLocker - in-process lock per action/board
+type cronLocker struct {
+	mu      sync.Mutex
+	running map[string]bool
+	settled map[string]time.Time
+}
+
+var cronLocks = cronLocker{running: make(map[string]bool), settled: make(map[string]time.Time)}
+
+// tryLock - false if the job is already running or only just finished
+func (l *cronLocker) tryLock(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[key] || time.Now().Before(l.settled[key]) {
+		return false
+	}
+	l.running[key] = true
+
+	return true
+}
+
+func (l *cronLocker) unlock(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.running, key)
+	l.settled[key] = time.Now().Add(cronSettle)
+}
+
+var instanceOnce sync.Once
+var instanceID string
+
+// InstanceID - who holds a DB lease.  InstanceID in tiktok.toml, or hostname and pid
+func InstanceID(tiktok *TikTokConf) string {
+	instanceOnce.Do(func() {
+		instanceID = tiktok.Config.InstanceID
+		if instanceID == "" {
+			host, _ := os.Hostname()
+			instanceID = host + "-" + strconv.Itoa(os.Getpid())
+		}
+	})
+	return instanceID
+}
+
+// AcquireLease - take or renew the named lease if it's free, expired or already ours.  Uses the DB clock so instances
+// don't need synced clocks
+func (r *Repo) AcquireLease(name string, holder string, ttl time.Duration) (bool, error) {
+	var current string
+
+	if err := r.ready(); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	// MySQL runs ON DUPLICATE KEY UPDATE assignments left to right and later ones see the new values of earlier ones.
+	// holder has to be set first, so expires only moves when holder is now ours.  Swapping them would let a standby push
+	// out a lease it doesn't hold, or take one without extending it
+	secs := int(ttl.Seconds())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO tiktok_cron_leases (name,holder,expires) VALUES (?,?,DATE_ADD(NOW(), INTERVAL ? SECOND)) "+
+		"ON DUPLICATE KEY UPDATE holder=IF(expires<NOW() OR holder=?, VALUES(holder), holder), expires=IF(holder=?, VALUES(expires), expires)",
+		name, holder, secs, holder, holder)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error taking lease `"+name+"` in `AcquireLease` in `cronlock.go`", err)
+		return false, err
+	}
+
+	err = r.db.QueryRowContext(ctx, "SELECT holder FROM tiktok_cron_leases WHERE name=?", name).Scan(&current)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error reading lease `"+name+"` in `AcquireLease` in `cronlock.go`", err)
+		return false, err
+	}
+
+	return current == holder, nil
+}
+
+// ReleaseLease - let a lease go once it has lingered a little longer, only if it's still ours
+func (r *Repo) ReleaseLease(name string, holder string, linger time.Duration) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE tiktok_cron_leases SET expires=DATE_ADD(NOW(), INTERVAL ? SECOND) WHERE name=? AND holder=?", int(linger.Seconds()), name, holder)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error releasing lease `"+name+"` in `ReleaseLease` in `cronlock.go`", err)
+	}
+
+	return err
+}
+
+// cronLock - a held in-process lock and DB lease for one cron job
+type cronLock struct {
+	tiktok *TikTokConf
+	key    string
+	stop   chan struct{}
+}
+
+// lockCron - lock an action/board in-process and across instances.  False means skip this run, the reason has been
+// logged
+func lockCron(tiktok *TikTokConf, action string, board string) (*cronLock, bool) {
+	var attachments Attachment
+
+	key := action + ":" + board
+
+	if !cronLocks.tryLock(key) {
+		cronSkipped.Inc(action, board, "overlap")
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Skipping cron `"+action+"` on team *"+board+"*, the last run is still going or only just finished", tiktok, attachments)
+		}
+		return nil, false
+	}
+
+	// fail closed, a standby replica must never run a job it can't prove it owns
+	ok, err := tiktok.DB.AcquireLease(key, InstanceID(tiktok), cronLeaseTTL)
+	if err != nil || !ok {
+		cronLocks.unlock(key)
+		cronSkipped.Inc(action, board, "lease")
+		if tiktok.Config.LogToSlack && err == nil {
+			LogToSlack("Skipping cron `"+action+"` on team *"+board+"*, another instance holds the lease", tiktok, attachments)
+		}
+		return nil, false
+	}
+
+	lock := &cronLock{tiktok: tiktok, key: key, stop: make(chan struct{})}
+	go lock.renew()
+
+	return lock, true
+}
+
+// renew - keep the lease alive while a long job (sprint, cardloader) runs
+func (lock *cronLock) renew() {
+	ticker := time.NewTicker(cronLeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lock.tiktok.DB.AcquireLease(lock.key, InstanceID(lock.tiktok), cronLeaseTTL)
+		case <-lock.stop:
+			return
+		}
+	}
+}
+
+// release - stop renewing and free the lease after cronSettle
+func (lock *cronLock) release() {
+	close(lock.stop)
+	lock.tiktok.DB.ReleaseLease(lock.key, InstanceID(lock.tiktok), cronSettle)
+	cronLocks.unlock(lock.key)
+}
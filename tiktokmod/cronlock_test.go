@@ -0,0 +1,77 @@
+package tiktokmod
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// skipped - how many times a cron job has been skipped for a reason
+func skipped(action string, board string, reason string) float64 {
+	cronSkipped.mu.Lock()
+	defer cronSkipped.mu.Unlock()
+
+	return cronSkipped.values[strings.Join([]string{action, board, reason}, "\xff")]
+}
+
+func TestLockCron(t *testing.T) {
+	tests := []struct {
+		name   string
+		holder func(tt *testTeam) string
+		dbErr  error
+		want   bool
+	}{
+		// the DB only hands an expired or free lease to whoever asks, here it decided we won
+		{name: "expired lease taken over", holder: func(tt *testTeam) string { return InstanceID(tt.tiktok) }, want: true},
+		{name: "held by another instance", holder: func(tt *testTeam) string { return "standby-1234" }},
+		{name: "fails closed on a DB error", dbErr: errors.New("db is down")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			board := strings.Replace(tc.name, " ", "-", -1)
+			if tc.holder != nil {
+				tt.db.on("SELECT holder FROM tiktok_cron_leases", []string{"holder"}, []driver.Value{tc.holder(tt)})
+			}
+			if tc.dbErr != nil {
+				tt.db.fail("INSERT INTO tiktok_cron_leases", tc.dbErr)
+			}
+			before := skipped("sprint", board, "lease")
+
+			lock, ok := lockCron(tt.tiktok, "sprint", board)
+			if ok != tc.want {
+				t.Fatalf("lockCron() = %v, want %v", ok, tc.want)
+			}
+
+			upserts := tt.db.ran("INSERT INTO tiktok_cron_leases")
+			if len(upserts) != 1 || !strings.Contains(upserts[0], "sprint:"+board+" "+InstanceID(tt.tiktok)) {
+				t.Errorf("lease statements = %q, want one for sprint:%s held by %s", upserts, board, InstanceID(tt.tiktok))
+			}
+			// holder has to be assigned before expires reads it, see AcquireLease
+			if len(upserts) == 1 && strings.Index(upserts[0], "holder=IF(") > strings.Index(upserts[0], "expires=IF(") {
+				t.Errorf("expires is assigned before holder in %q", upserts[0])
+			}
+
+			if ok {
+				lock.release()
+				if len(tt.db.ran("UPDATE tiktok_cron_leases")) != 1 {
+					t.Errorf("lease wasn't released")
+				}
+				return
+			}
+
+			if got := skipped("sprint", board, "lease") - before; got != 1 {
+				t.Errorf("skipped for the lease %v times, want 1", got)
+			}
+			// the in-process lock is given back, only the settle time keeps the next firing out
+			cronLocks.mu.Lock()
+			running := cronLocks.running["sprint:"+board]
+			cronLocks.mu.Unlock()
+			if running {
+				t.Errorf("in-process lock is still held after the lease was refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package tiktokmod
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/dialers/mysql"
+)
+
+// ErrNoDB - returned by every Repo call when tiktok could not open its database at startup
+var ErrNoDB = errors.New("no database connection available")
+
+// Repo - every query tiktok makes against its SQL DB.  One Repo is created at startup and shared so all callers
+// use the same connection pool
+type Repo struct {
+	db      *sql.DB
+	tiktok  *TikTokConf
+	timeout time.Duration
+}
+
+// OpenDB - open the pooled connection to the "bot" DB using the pool limits in tiktok.toml
+func OpenDB(tiktok *TikTokConf) (db *sql.DB, err error) {
+	if tiktok.Config.UseGCP {
+		cfg := mysql.Cfg(tiktok.Config.SQLHost, tiktok.Config.DBUser, tiktok.Config.DBPassword)
+		cfg.DBName = tiktok.Config.SQLDBName
+		cfg.AllowNativePasswords = tiktok.Config.AllowNativePasswords
+		cfg.AllowCleartextPasswords = tiktok.Config.AllowCleartextPasswords
+		cfg.AllowAllFiles = tiktok.Config.AllowAllFiles
+		cfg.ParseTime = tiktok.Config.ParseTime
+
+		db, err = mysql.DialCfg(cfg)
+	} else {
+		myConn := tiktok.Config.DBUser + ":" + tiktok.Config.DBPassword + "@tcp(" + tiktok.Config.SQLHost + ":" + tiktok.Config.SQLPort + ")/" + tiktok.Config.SQLDBName
+		myParams := "?allowAllFiles=" + strconv.FormatBool(tiktok.Config.AllowAllFiles)
+		myParams = myParams + "&allowCleartextPasswords=" + strconv.FormatBool(tiktok.Config.AllowCleartextPasswords)
+		myParams = myParams + "&allowNativePasswords=" + strconv.FormatBool(tiktok.Config.AllowNativePasswords)
+		myParams = myParams + "&parseTime=" + strconv.FormatBool(tiktok.Config.ParseTime)
+
+		db, err = sql.Open("mysql", myConn+myParams)
+	}
+	if err != nil {
+		return db, err
+	}
+
+	maxOpen := tiktok.Config.DBMaxOpenConns
+	if maxOpen == 0 {
+		maxOpen = 10
+	}
+	maxIdle := tiktok.Config.DBMaxIdleConns
+	if maxIdle == 0 {
+		maxIdle = 5
+	}
+	lifetime := tiktok.Config.DBConnMaxLifetime
+	if lifetime == 0 {
+		lifetime = 5
+	}
+
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(time.Duration(lifetime) * time.Minute)
+
+	return db, nil
+}
+
+// NewRepo - open the DB and wrap it in a Repo.  If the DB can't be opened the Repo is still returned so every call
+// fails with ErrNoDB instead of panicking
+func NewRepo(tiktok *TikTokConf) (*Repo, error) {
+	timeout := tiktok.Config.DBQueryTimeout
+	if timeout == 0 {
+		timeout = 15
+	}
+
+	r := &Repo{tiktok: tiktok, timeout: time.Duration(timeout) * time.Second}
+
+	db, err := OpenDB(tiktok)
+	if err != nil {
+		return r, err
+	}
+	r.db = db
+
+	return r, r.Ping()
+}
+
+// ctx - context every query runs under so a hung DB can't hang a cron job
+func (r *Repo) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), r.timeout)
+}
+
+// ready - is there a DB to talk to
+func (r *Repo) ready() error {
+	if r == nil || r.db == nil {
+		return ErrNoDB
+	}
+	return nil
+}
+
+// Ping - health check the DB connection
+func (r *Repo) Ping() error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	return r.db.PingContext(ctx)
+}
+
+// Stats - connection pool stats
+func (r *Repo) Stats() sql.DBStats {
+	if r.ready() != nil {
+		return sql.DBStats{}
+	}
+	return r.db.Stats()
+}
+
+// Close - close the pool on shutdown
+func (r *Repo) Close() error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+	return r.db.Close()
+}
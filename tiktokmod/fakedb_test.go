@@ -0,0 +1,358 @@
+package tiktokmod
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB - a scripted database/sql driver for tests.  A query gets the rows of the newest rule whose match is in its
+// SQL, or no rows at all, and every statement that runs is logged
+type fakeDB struct {
+	mu     sync.Mutex
+	rules  []fakeRule
+	log    []string
+	execs  []fakeExec
+	lastID int64
+}
+
+// fakeExec - a statement that ran and its arguments, for tests that feed what was written back in as rows
+type fakeExec struct {
+	query string
+	args  []interface{}
+}
+
+type fakeRule struct {
+	match string
+	cols  []string
+	rows  [][]driver.Value
+	err   error
+}
+
+// on - answer queries containing match with these rows
+func (f *fakeDB) on(match string, cols []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = append([]fakeRule{{match: match, cols: cols, rows: rows}}, f.rules...)
+}
+
+// fail - fail queries and statements containing match
+func (f *fakeDB) fail(match string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = append([]fakeRule{{match: match, err: err}}, f.rules...)
+}
+
+// ran - logged statements containing match
+func (f *fakeDB) ran(match string) (found []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, l := range f.log {
+		if strings.Contains(l, match) {
+			found = append(found, l)
+		}
+	}
+
+	return found
+}
+
+// written - arguments of the statements containing match that ran
+func (f *fakeDB) written(match string) (found [][]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, e := range f.execs {
+		if strings.Contains(e.query, match) {
+			found = append(found, e.args)
+		}
+	}
+
+	return found
+}
+
+func (f *fakeDB) rule(query string) (fakeRule, bool) {
+	for _, r := range f.rules {
+		if strings.Contains(query, r.match) {
+			return r, true
+		}
+	}
+
+	return fakeRule{}, false
+}
+
+func (f *fakeDB) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log = append(f.log, query+" "+fmt.Sprint(namedValues(args)))
+	f.execs = append(f.execs, fakeExec{query: query, args: namedValues(args)})
+	if r, ok := f.rule(query); ok && r.err != nil {
+		return nil, r.err
+	}
+	f.lastID++
+
+	return fakeResult(f.lastID), nil
+}
+
+func (f *fakeDB) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log = append(f.log, query+" "+fmt.Sprint(namedValues(args)))
+	r, ok := f.rule(query)
+	if !ok {
+		return &fakeRows{}, nil
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &fakeRows{cols: r.cols, rows: r.rows}, nil
+}
+
+func namedValues(args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+
+	return values
+}
+
+// Connect - fakeDB is its own driver.Connector so it can be handed straight to sql.OpenDB
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+
+// Driver - see Connect
+func (f *fakeDB) Driver() driver.Driver {
+	return fakeDriver{f}
+}
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{db: d.db}, nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.db.exec(query, args)
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query, args)
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.db.exec(s.query, named(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.db.query(s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	n := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		n[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+
+	return n
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	if len(dest) != len(r.rows[r.next]) {
+		return errors.New("fake row has the wrong number of columns")
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+
+	return nil
+}
+
+// testTeam - a tiktok wired to a fake DB, a FakeTrello loaded from testdata/board.json and a local slack that
+// records every message posted to it
+type testTeam struct {
+	tiktok *TikTokConf
+	opts   Config
+	db     *fakeDB
+	trello *FakeTrello
+	slack  *testSlack
+}
+
+// testSlack - captures webhook and chat.postMessage payloads
+type testSlack struct {
+	mu       sync.Mutex
+	messages []BotDMPayload
+}
+
+// sent - every message posted to a channel, including its attachment text
+func (s *testSlack) sent(channel string) (texts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.messages {
+		if m.Channel == channel {
+			text := m.Text
+			for _, a := range m.Attachments {
+				text = text + "\n" + a.Text
+			}
+			texts = append(texts, text)
+		}
+	}
+
+	return texts
+}
+
+func newTestTeam(t *testing.T) *testTeam {
+	t.Helper()
+
+	tt := &testTeam{db: &fakeDB{}, slack: &testSlack{}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m BotDMPayload
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		tt.slack.mu.Lock()
+		tt.slack.messages = append(tt.slack.messages, m)
+		tt.slack.mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	oldURL := chatPostMessageURL
+	chatPostMessageURL = server.URL
+	t.Cleanup(func() { chatPostMessageURL = oldURL })
+
+	tt.tiktok = &TikTokConf{}
+	tt.tiktok.Config.BotName = "tiktok"
+	tt.tiktok.Config.BotTrelloID = "tiktokbot"
+	tt.tiktok.Config.SlackHook = server.URL
+	tt.tiktok.Config.SlackToken = "xoxb-test"
+	tt.tiktok.Config.PointsPowerUpID = "59d4ef8cfea15a55b0086614"
+	tt.tiktok.DB = &Repo{db: sql.OpenDB(tt.db), tiktok: tt.tiktok, timeout: 5 * time.Second}
+	t.Cleanup(func() { _ = tt.tiktok.DB.Close() })
+
+	trello, err := LoadFakeTrello(tt.tiktok, "testdata/board.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt.trello = trello
+	tt.tiktok.Trello = trello
+
+	g := &tt.opts.General
+	g.TeamName = "Example Team"
+	g.Sprintname = "Example"
+	g.TrelloOrg = "example"
+	g.MaxPoints = 13
+	g.BackLogDays = 180
+	g.RetroActionDays = 5
+	g.SprintDuration = 14
+	g.TimeZone = "UTC"
+	g.BoardID = "5c8a0e1f2b3c4d5e6f708091"
+	g.BacklogID = "5c8a0e1f2b3c4d5e6f7000a1"
+	g.Upcoming = "5c8a0e1f2b3c4d5e6f7000a2"
+	g.Scoped = "5c8a0e1f2b3c4d5e6f7000a3"
+	g.NextsprintID = "5c8a0e1f2b3c4d5e6f7000a4"
+	g.ReadyForWork = "5c8a0e1f2b3c4d5e6f7000a5"
+	g.Working = "5c8a0e1f2b3c4d5e6f7000a6"
+	g.ReadyForReview = "5c8a0e1f2b3c4d5e6f7000a7"
+	g.Done = "5c8a0e1f2b3c4d5e6f7000a8"
+	g.ROLabelID = "5c8a0e1f2b3c4d5e6f70b001"
+	g.CfsprintID = "5c8a0e1f2b3c4d5e6f70c001"
+	g.CfpointsID = "5c8a0e1f2b3c4d5e6f70c002"
+	g.SprintChannel = "#sprint"
+	g.ComplaintChannel = "#complaints"
+	g.RetroChannel = "#retro"
+
+	return tt
+}
+
+// card - the fixture card as it is now
+func (tt *testTeam) card(t *testing.T, cardID string) BoardCard {
+	t.Helper()
+
+	c, err := tt.trello.GetCard(cardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// calls - FakeTrello writes starting with method
+func (tt *testTeam) calls(method string) (found []string) {
+	for _, c := range tt.trello.Calls {
+		if strings.HasPrefix(c, method+" ") {
+			found = append(found, c)
+		}
+	}
+
+	return found
+}
+
+// sprintRow - a tiktok_main row for GetSprint
+func sprintRow(teamID string, start time.Time, name string) []driver.Value {
+	return []driver.Value{int64(1), teamID, start, int64(14), "", name, float64(10), nil}
+}
+
+var sprintCols = []string{"v2id", "teamid", "sprintstart", "duration", "retroid", "sprintname", "workingdays", "committedpts"}
+
+// userRow - a tiktok_users row for GetUsers
+func userRow(name string, slackID string, trelloName string) []driver.Value {
+	return []driver.Value{int64(1), name, slackID, trelloName, "", ""}
+}
+
+var userCols = []string{"id", "name", "slackid", "trello", "github", "email"}
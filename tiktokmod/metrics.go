@@ -0,0 +1,313 @@
+package tiktokmod
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// counterVec - a prometheus counter split by label values
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name string, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc - add one for these label values, given in the same order as the labels
+func (c *counterVec) Inc(values ...string) {
+	c.mu.Lock()
+	c.values[strings.Join(values, "\xff")]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b.WriteString("# HELP " + c.name + " " + c.help + "\n# TYPE " + c.name + " counter\n")
+	for _, key := range sortedKeys(c.values) {
+		b.WriteString(c.name + labelString(c.labels, key, "") + " " + formatFloat(c.values[key]) + "\n")
+	}
+}
+
+// histogramVec - a prometheus histogram split by label values
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogramVec(name string, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// Observe - record one value for these label values
+func (h *histogramVec) Observe(v float64, values ...string) {
+	key := strings.Join(values, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, le := range h.buckets {
+		if v <= le {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("# HELP " + h.name + " " + h.help + "\n# TYPE " + h.name + " histogram\n")
+	for _, key := range keys {
+		s := h.series[key]
+		for i, le := range h.buckets {
+			b.WriteString(h.name + "_bucket" + labelString(h.labels, key, formatFloat(le)) + " " + strconv.FormatUint(s.counts[i], 10) + "\n")
+		}
+		b.WriteString(h.name + "_bucket" + labelString(h.labels, key, "+Inf") + " " + strconv.FormatUint(s.count, 10) + "\n")
+		b.WriteString(h.name + "_sum" + labelString(h.labels, key, "") + " " + formatFloat(s.sum) + "\n")
+		b.WriteString(h.name + "_count" + labelString(h.labels, key, "") + " " + strconv.FormatUint(s.count, 10) + "\n")
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelString - {a="x",b="y"} for a series key, with an le label on the end for histogram buckets
+func labelString(labels []string, key string, le string) string {
+	var pairs []string
+
+	if len(labels) > 0 {
+		values := strings.Split(key, "\xff")
+		for i, l := range labels {
+			v := ""
+			if i < len(values) {
+				v = values[i]
+			}
+			pairs = append(pairs, l+"=\""+escapeLabel(v)+"\"")
+		}
+	}
+	if le != "" {
+		pairs = append(pairs, "le=\""+le+"\"")
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabel(v string) string {
+	v = strings.Replace(v, "\\", "\\\\", -1)
+	v = strings.Replace(v, "\"", "\\\"", -1)
+	return strings.Replace(v, "\n", "\\n", -1)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	cronRuns          = newCounterVec("tiktok_cron_runs_total", "Cron job executions.", "action", "board")
+	cronFailures      = newCounterVec("tiktok_cron_failures_total", "Cron job executions that returned an error.", "action", "board")
+	cronSkipped       = newCounterVec("tiktok_cron_skipped_total", "Cron job firings skipped because the job was locked.", "action", "board", "reason")
+	apiCalls          = newCounterVec("tiktok_api_requests_total", "Outbound API calls by service and HTTP status code.", "service", "code")
+	apiLatency        = newHistogramVec("tiktok_api_request_duration_seconds", "Outbound API call latency.", []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "service")
+	commandsHandled   = newCounterVec("tiktok_commands_total", "Chat commands handled.", "command")
+	boardCacheLookups = newCounterVec("tiktok_board_cache_lookups_total", "Board snapshot cache lookups by result.", "result")
+	trelloHookEvents  = newCounterVec("tiktok_trello_webhook_events_total", "Trello webhook card events handled by kind.", "kind")
+)
+
+// apiService - which API an outbound request is for, blank for anything we don't track
+func apiService(host string) string {
+	switch {
+	case host == "trello.com" || strings.HasSuffix(host, ".trello.com"):
+		return "trello"
+	case host == "slack.com" || strings.HasSuffix(host, ".slack.com"):
+		return "slack"
+	case host == "github.com" || strings.HasSuffix(host, ".github.com"):
+		return "github"
+	}
+	return ""
+}
+
+// apiTransport - counts and times every Trello, Slack and GitHub call made through the default transport
+type apiTransport struct {
+	base http.RoundTripper
+}
+
+func (t *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service := apiService(req.URL.Hostname())
+	if service == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	apiLatency.Observe(time.Since(start).Seconds(), service)
+
+	if err != nil {
+		apiCalls.Inc(service, "error")
+	} else {
+		apiCalls.Inc(service, strconv.Itoa(resp.StatusCode))
+	}
+
+	return resp, err
+}
+
+var instrumentOnce sync.Once
+
+// InstrumentHTTP - wrap http.DefaultTransport so API calls from every client library show up in /metrics
+func InstrumentHTTP() {
+	instrumentOnce.Do(func() {
+		http.DefaultTransport = &apiTransport{base: http.DefaultTransport}
+	})
+}
+
+// runtimeHealth - live state the transports and main loop report for /healthz
+type runtimeHealth struct {
+	mu             sync.Mutex
+	slackConnected bool
+	cronState      string
+}
+
+var health runtimeHealth
+
+// SetSlackConnected - record whether the slack transport is connected
+func SetSlackConnected(connected bool) {
+	health.mu.Lock()
+	health.slackConnected = connected
+	health.mu.Unlock()
+}
+
+// SetCronState - record the cron state the main loop is tracking
+func SetCronState(state string) {
+	health.mu.Lock()
+	health.cronState = state
+	health.mu.Unlock()
+}
+
+// HealthReport - body of /healthz
+type HealthReport struct {
+	Healthy bool   `json:"healthy"`
+	Version string `json:"version"`
+	Slack   bool   `json:"slack_connected"`
+	DB      bool   `json:"db_reachable"`
+	DBError string `json:"db_error,omitempty"`
+	Cron    string `json:"cron"`
+}
+
+// CheckHealth - is slack connected, the DB reachable and cron not halted
+func CheckHealth(tiktok *TikTokConf) (report HealthReport) {
+	health.mu.Lock()
+	report.Slack = health.slackConnected
+	report.Cron = health.cronState
+	health.mu.Unlock()
+
+	report.Version = tiktok.Config.Version
+
+	if err := tiktok.DB.Ping(); err != nil {
+		report.DBError = err.Error()
+	} else {
+		report.DB = true
+	}
+
+	// -nocron is a deliberate choice so only a halted cron counts against us
+	report.Healthy = report.Slack && report.DB && report.Cron != "Halted"
+
+	return report
+}
+
+// WriteMetrics - everything we count in prometheus text format
+func WriteMetrics(tiktok *TikTokConf) string {
+	var b strings.Builder
+
+	cronRuns.write(&b)
+	cronFailures.write(&b)
+	cronSkipped.write(&b)
+	apiCalls.write(&b)
+	apiLatency.write(&b)
+	commandsHandled.write(&b)
+	boardCacheLookups.write(&b)
+	trelloHookEvents.write(&b)
+
+	stats := tiktok.DB.Stats()
+	b.WriteString("# HELP tiktok_db_open_connections Open connections in the DB pool.\n# TYPE tiktok_db_open_connections gauge\n")
+	b.WriteString("tiktok_db_open_connections " + strconv.Itoa(stats.OpenConnections) + "\n")
+	b.WriteString("# HELP tiktok_db_in_use_connections DB connections currently in use.\n# TYPE tiktok_db_in_use_connections gauge\n")
+	b.WriteString("tiktok_db_in_use_connections " + strconv.Itoa(stats.InUse) + "\n")
+
+	return b.String()
+}
+
+// StartStatusServer - serve /healthz and /metrics on StatusPort from tiktok.toml.  Does nothing if the port isn't set
+func StartStatusServer(tiktok *TikTokConf) {
+	if tiktok.Config.StatusPort == 0 {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		report := CheckHealth(tiktok)
+
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(WriteMetrics(tiktok)))
+	})
+
+	listen := ":" + strconv.Itoa(tiktok.Config.StatusPort)
+	go func() {
+		err := http.ListenAndServe(listen, mux)
+		errTrap(tiktok, "Status server on "+listen+" stopped in `StartStatusServer` in `metrics.go`", err)
+	}()
+
+	if tiktok.Config.DEBUG {
+		fmt.Println("Serving /healthz and /metrics on " + listen)
+	}
+}
@@ -0,0 +1,263 @@
+package tiktokmod
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Migration - one versioned change to the "bot" DB schema.  Never edit a migration once it has shipped, add a new one.
+// MySQL commits every DDL statement as it runs, so a migration that fails part way leaves its earlier statements
+// applied.  Every step must be safe to run again (`create table if not exists`, `addColumn`) so a rerun can finish it
+type Migration struct {
+	Version int
+	Name    string
+	Apply   func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations - every schema change in order.  The last Version is what `sql.go` expects
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline schema",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_theme_count (id int not null primary key auto_increment, countdate datetime, team varchar(100), sprintname varchar(100), trellocolumn varchar(100), labelname varchar(100), qty int)",
+				"create table if not exists tiktok_holidays (holidayid int not null primary key auto_increment, name varchar(255), holiday date, message varchar(200))",
+				"create table if not exists tiktok_chapters (id int not null primary key auto_increment, boardid varchar(100), chaptername varchar(100), labelid varchar(200))",
+				"create table if not exists tiktok_squad_peeps (id int not null primary key auto_increment, sprint varchar(100), userID int, squad varchar(100))",
+				"create table if not exists tiktok_squad_deliverables (id int not null primary key auto_increment, sprint varchar(100), squadID int, deliverable varchar(255), description varchar(400))",
+				"create table if not exists tiktok_bug_label (id int not null primary key auto_increment, boardid varchar(100), buglevel varchar(100), labelid varchar(100))",
+				"create table if not exists tiktok_chapter_cards (id int not null primary key auto_increment, timestamp datetime, chaptername varchar(200), listname varchar(200), cards int)",
+				"create table if not exists tiktok_sprint_squad_points (sprintname varchar(100), squadname varchar(255), squadpoints int, workingdays int)",
+				"create table if not exists tiktok_cardtracker (cardid varchar(100), cardtitle varchar(255), points int, cardurl varchar(255), list varchar(100), startedinworking datetime, startedinpr datetime, entereddone datetime, owners varchar(255), team varchar(255))",
+				"create table if not exists tiktok_users (id int not null primary key auto_increment, name varchar(255), slackid varchar(100), trello varchar(100), github varchar(100), email varchar(255))",
+				"create table if not exists tiktok_main (v2id int not null primary key auto_increment, teamid varchar(50), sprintstart date, duration int, retroid varchar(100), sprintname varchar(100))",
+				"create table if not exists tiktok_burndown (id int not null primary key auto_increment, pointdate datetime, team varchar(100), totalpoints int, rfwpts int, wkgpts int, uatpts int, dnepts int, numcards int)",
+				"create table if not exists tiktok_squads (id int not null primary key auto_increment, boardid varchar(100), squadname varchar(255), labelid varchar(255))",
+				"create table if not exists tiktok_label_ignore (uid int not null primary key auto_increment, boardid varchar(100), labelid varchar(100))",
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "tiktok_main workingdays",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumn(ctx, tx, "tiktok_main", "workingdays", "int")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "tiktok_chapter_cards team",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumn(ctx, tx, "tiktok_chapter_cards", "team", "varchar(255)")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "tiktok_cron_runs",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_cron_runs (id int not null primary key auto_increment, action varchar(100), board varchar(100), started datetime, finished datetime, holidayskip tinyint(1) not null default 0, outcome varchar(20), error text not null, index board_started (board, started))",
+			)
+		},
+	},
+	{
+		Version: 5,
+		Name:    "tiktok_cron_leases",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_cron_leases (name varchar(200) not null primary key, holder varchar(255) not null, expires datetime not null)",
+			)
+		},
+	},
+	{
+		Version: 6,
+		Name:    "tiktok_sprint_reports",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_sprint_reports (id int not null primary key auto_increment, team varchar(100), sprintname varchar(100), closed datetime, committed int, completed int, rolledcards int, rolledpts int, backlogcards int, backlogpts int, report text not null, index team_closed (team, closed))",
+			)
+		},
+	},
+	{
+		Version: 7,
+		Name:    "tiktok_sprint_rollovers and tiktok_sprint_journal",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_sprint_rollovers (id int not null primary key auto_increment, boardid varchar(100), team varchar(100), started datetime, oldsprint varchar(100), newsprint varchar(100) not null default '', retroid varchar(100) not null default '', undone datetime null, index boardid_started (boardid, started))",
+				"create table if not exists tiktok_sprint_journal (id int not null primary key auto_increment, rollover int not null, cardid varchar(100), action varchar(100), state text not null, index rollover (rollover))",
+			)
+		},
+	},
+	{
+		Version: 8,
+		Name:    "tiktok_sprint_runs",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_sprint_runs (boardid varchar(100) not null, sprintname varchar(100) not null, phase varchar(50) not null, state text not null, updated datetime, primary key (boardid, sprintname))",
+			)
+		},
+	},
+	{
+		Version: 9,
+		Name:    "team and half-day holidays, fractional working days and ideal burndown",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			if err := addColumn(ctx, tx, "tiktok_holidays", "team", "varchar(100) not null default ''"); err != nil {
+				return err
+			}
+			if err := addColumn(ctx, tx, "tiktok_holidays", "halfday", "tinyint(1) not null default 0"); err != nil {
+				return err
+			}
+			if err := addColumn(ctx, tx, "tiktok_burndown", "idealpts", "int"); err != nil {
+				return err
+			}
+			return execAll(ctx, tx,
+				"alter table tiktok_main modify workingdays decimal(5,1)",
+			)
+		},
+	},
+	{
+		Version: 10,
+		Name:    "tiktok_availability",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return execAll(ctx, tx,
+				"create table if not exists tiktok_availability (userid int not null, team varchar(100) not null, sprintstart date not null, ptodays decimal(4,1) not null default 0, parttime int not null default 100, oncall tinyint(1) not null default 0, updated datetime, primary key (userid, team, sprintstart))",
+			)
+		},
+	},
+	{
+		Version: 11,
+		Name:    "tiktok_main committedpts",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumn(ctx, tx, "tiktok_main", "committedpts", "int")
+		},
+	},
+}
+
+// SchemaVersion - the schema version this build of tiktok expects
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// execAll - run statements in order, stop at the first error
+func execAll(ctx context.Context, tx *sql.Tx, statements ...string) error {
+	for _, s := range statements {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn - add a column unless it's already there.  Some DB's had columns added by hand before migrations existed
+func addColumn(ctx context.Context, tx *sql.Tx, table string, column string, definition string) error {
+	var count int
+
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND COLUMN_NAME=?", table, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+column+" "+definition)
+	return err
+}
+
+// GetSchemaVersion - version the DB is at, 0 if it has never been migrated
+func (r *Repo) GetSchemaVersion() (version int, err error) {
+	var count int
+
+	if err := r.ready(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME='tiktok_schema_version'").Scan(&count)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetSchemaVersion` in `migrations.go`", err)
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	err = r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version),0) FROM tiktok_schema_version").Scan(&version)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetSchemaVersion` in `migrations.go`", err)
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// CheckSchema - error if the DB is older than what this build expects
+func (r *Repo) CheckSchema() error {
+	version, err := r.GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if version < SchemaVersion() {
+		return errors.New("database schema is at version " + strconv.Itoa(version) + " but tiktok needs version " + strconv.Itoa(SchemaVersion()) + ", run tiktok -migrate")
+	}
+
+	return nil
+}
+
+// Migrate - apply every migration newer than the DB in order, stopping at the first one that fails.  The transaction
+// around each one only covers its DML and the tiktok_schema_version row, DDL isn't rolled back, which is why migrations
+// have to be idempotent
+func (r *Repo) Migrate() (applied []string, err error) {
+	if err := r.ready(); err != nil {
+		return applied, err
+	}
+
+	version, err := r.GetSchemaVersion()
+	if err != nil {
+		return applied, err
+	}
+
+	ctx := context.Background()
+
+	_, err = r.db.ExecContext(ctx, "create table if not exists tiktok_schema_version (version int not null primary key, name varchar(255), applied datetime)")
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error creating tiktok_schema_version in `Migrate` in `migrations.go`", err)
+		return applied, err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, err
+		}
+
+		err = m.Apply(ctx, tx)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, "INSERT tiktok_schema_version SET version=?,name=?,applied=?", m.Version, m.Name, time.Now().Local())
+		}
+		if err != nil {
+			tx.Rollback()
+			errTrap(r.tiktok, "Migration "+strconv.Itoa(m.Version)+" ("+m.Name+") failed in `Migrate` in `migrations.go`", err)
+			return applied, err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, strconv.Itoa(m.Version)+" - "+m.Name)
+	}
+
+	return applied, nil
+}
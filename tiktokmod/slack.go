@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -25,6 +26,9 @@ const (
 	channelUnArchiveURL string = "https://slack.com/api/channels.unarchive"
 )
 
+// chatPostMessageURL - where bot messages and DMs are posted, tests point it at a local server
+var chatPostMessageURL = "https://slack.com/api/chat.postMessage"
+
 // Field - struct
 type Field struct {
 	Title string `json:"title"`
@@ -236,7 +240,7 @@ func Send(webhookURL string, proxy string, payload Payload) []error {
 
 // WranglerDM - Send chat.Post API DM messages "as the bot"
 func WranglerDM(tiktok *TikTokConf, payload BotDMPayload) error {
-	url := "https://slack.com/api/chat.postMessage"
+	url := chatPostMessageURL
 
 	payload.Token = tiktok.Config.SlackToken
 	payload.AsUser = true
@@ -267,22 +271,114 @@ func WranglerDM(tiktok *TikTokConf, payload BotDMPayload) error {
 
 }
 
-// Wrangler - wrangle slack calls
-func Wrangler(webhookURL string, message string, myChannel string, emojiName string, attachments Attachment) {
+// SlackDest - which slack app a message goes out through and what it looks like.  A webhook wins over a bot token
+type SlackDest struct {
+	Hook     string
+	Token    string
+	Username string
+	Emoji    string
+	IconURL  string
+}
 
-	payload := Payload{
-		Text:        message,
-		Username:    "TikTokConf",
+// slackSecret - team TOML secrets can be given as $ENV_VAR so they don't have to be committed
+func slackSecret(value string) string {
+	if strings.HasPrefix(value, "$") {
+		return os.Getenv(strings.TrimPrefix(value, "$"))
+	}
+	return value
+}
+
+// TeamSlack - slack destination for a team's board.  Anything the team TOML leaves blank falls back to tiktok.toml
+func TeamSlack(tiktok *TikTokConf, opts Config) SlackDest {
+	dest := SlackDest{Hook: tiktok.Config.SlackHook, Emoji: tiktok.Config.SlackEmoji}
+
+	hook := slackSecret(opts.General.SlackHook)
+	token := slackSecret(opts.General.SlackToken)
+	if hook != "" || token != "" {
+		dest.Hook = hook
+		dest.Token = token
+	}
+	if opts.General.SlackUsername != "" {
+		dest.Username = opts.General.SlackUsername
+	}
+	if opts.General.SlackEmoji != "" {
+		dest.Emoji = opts.General.SlackEmoji
+	}
+	if opts.General.SlackIconURL != "" {
+		dest.IconURL = opts.General.SlackIconURL
+		dest.Emoji = ""
+	}
+
+	return dest
+}
+
+// TeamWrangler - post a board's alerts and notices through that team's own slack app
+func TeamWrangler(tiktok *TikTokConf, opts Config, message string, myChannel string, attachments Attachment) {
+	WranglerTo(TeamSlack(tiktok, opts), message, myChannel, attachments)
+}
+
+// WranglerTo - send a message through a webhook, or chat.postMessage with a bot token
+func WranglerTo(dest SlackDest, message string, myChannel string, attachments Attachment) {
+	if dest.Hook != "" || dest.Token == "" {
+		payload := Payload{
+			Text:        message,
+			Username:    dest.Username,
+			Channel:     myChannel,
+			IconEmoji:   dest.Emoji,
+			IconURL:     dest.IconURL,
+			Attachments: []Attachment{attachments},
+		}
+		err := Send(dest.Hook, "", payload)
+		if len(err) > 0 {
+			fmt.Printf("Slack Messaging Error in WranglerTo function in slack.go: %s\n", err)
+		}
+		return
+	}
+
+	var slackResp BasicSlackPayload
+
+	payload := BotDMPayload{
 		Channel:     myChannel,
-		IconEmoji:   emojiName,
+		Text:        message,
+		Username:    dest.Username,
+		IconEmoji:   dest.Emoji,
+		IconURL:     dest.IconURL,
 		Attachments: []Attachment{attachments},
 	}
-	err := Send(webhookURL, "", payload)
-	if len(err) > 0 {
-		fmt.Printf("Slack Messaging Error in Wrangler function in slack.go: %s\n", err)
+
+	jsonStr, err := json.Marshal(&payload)
+	if err != nil {
+		fmt.Printf("Slack Messaging Error in WranglerTo function in slack.go: %s\n", err)
+		return
+	}
+
+	req, err := http.NewRequest("POST", chatPostMessageURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		fmt.Printf("Slack Messaging Error in WranglerTo function in slack.go: %s\n", err)
+		return
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+dest.Token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("Slack Messaging Error in WranglerTo function in slack.go: %s\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	json.NewDecoder(resp.Body).Decode(&slackResp)
+	if !slackResp.Ok {
+		fmt.Printf("Slack Messaging Error in WranglerTo function in slack.go: %s\n", slackResp.Error)
 	}
 }
 
+// Wrangler - wrangle slack calls
+func Wrangler(webhookURL string, message string, myChannel string, emojiName string, attachments Attachment) {
+	WranglerTo(SlackDest{Hook: webhookURL, Emoji: emojiName}, message, myChannel, attachments)
+}
+
 //LogToSlack - Dump Logs to a Slack Channel
 func LogToSlack(message string, tiktok *TikTokConf, attachments Attachment) {
 	now := time.Now().Local()
@@ -0,0 +1,399 @@
+package tiktokmod
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nlopes/slack"
+)
+
+// ErrBadSignature - an Events API request that wasn't signed with our signing secret
+var ErrBadSignature = errors.New("slack request signature does not match")
+
+// ErrStaleRequest - an Events API request older than five minutes, possibly a replay
+var ErrStaleRequest = errors.New("slack request timestamp is too old")
+
+// ErrQueueFull - the handler is too far behind to take another event, it was dropped
+var ErrQueueFull = errors.New("slack event queue is full")
+
+// eventCallback - the envelope slack wraps every Events API event in, over HTTP and Socket Mode alike
+type eventCallback struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// eventMessage - the bits of an app_mention or message event we need on top of slack.Msg
+type eventMessage struct {
+	Type        string `json:"type"`
+	ChannelType string `json:"channel_type"`
+}
+
+// eventQueue - hands Events API messages to the handler one at a time, the way the RTM loop always has
+type eventQueue struct {
+	incoming chan *slack.MessageEvent
+	done     chan struct{}
+	mu       sync.Mutex
+	seen     map[string]time.Time
+}
+
+func newEventQueue() *eventQueue {
+	return &eventQueue{
+		incoming: make(chan *slack.MessageEvent, 100),
+		done:     make(chan struct{}),
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// push - queue the message inside an event callback.  Slack retries events it thinks we missed so duplicates are dropped.
+// Never blocks, if the queue is full the event is dropped with ErrQueueFull and forgotten so a retry of it is queued
+func (q *eventQueue) push(cb eventCallback) error {
+	var kind eventMessage
+	var msg slack.Msg
+
+	if cb.Type != "event_callback" {
+		return nil
+	}
+
+	if err := json.Unmarshal(cb.Event, &kind); err != nil {
+		return err
+	}
+
+	// mentions come in as app_mention, DM's as message.  Channel messages are ignored so mentions aren't handled twice
+	if kind.Type != "app_mention" && !(kind.Type == "message" && kind.ChannelType == "im") {
+		return nil
+	}
+
+	if err := json.Unmarshal(cb.Event, &msg); err != nil {
+		return err
+	}
+	msg.Type = "message"
+
+	q.mu.Lock()
+	if cb.EventID != "" {
+		if _, ok := q.seen[cb.EventID]; ok {
+			q.mu.Unlock()
+			return nil
+		}
+		for id, at := range q.seen {
+			if time.Since(at) > time.Hour {
+				delete(q.seen, id)
+			}
+		}
+		q.seen[cb.EventID] = time.Now()
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.incoming <- &slack.MessageEvent{Msg: msg}:
+	case <-q.done:
+	default:
+		q.mu.Lock()
+		delete(q.seen, cb.EventID)
+		q.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	return nil
+}
+
+// serve - run the handler over queued messages until stop is called
+func (q *eventQueue) serve(handle MessageHandler, out Messenger) {
+	for {
+		select {
+		case ev := <-q.incoming:
+			handle(ev, out)
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// stop - stop serving, further events are dropped
+func (q *eventQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-q.done:
+	default:
+		close(q.done)
+	}
+}
+
+// SlackSignature - the X-Slack-Signature header slack sends for a request body signed with secret
+func SlackSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySlackRequest - check an Events API request came from slack in the last five minutes
+func VerifySlackRequest(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+
+	age := now.Unix() - ts
+	if age > 300 || age < -300 {
+		return ErrStaleRequest
+	}
+
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(SlackSignature(secret, timestamp, body))) {
+		return ErrBadSignature
+	}
+
+	return nil
+}
+
+// EventsTransport - receive app_mention and DM events on an HTTP endpoint slack posts to
+type EventsTransport struct {
+	tiktok *TikTokConf
+	api    *slack.Client
+	queue  *eventQueue
+	Now    func() time.Time
+}
+
+// NewEventsTransport - Events API transport using SlackSigningSecret and EventsListen/EventsPath from tiktok.toml
+func NewEventsTransport(tiktok *TikTokConf, api *slack.Client) *EventsTransport {
+	return &EventsTransport{tiktok: tiktok, api: api, queue: newEventQueue(), Now: time.Now}
+}
+
+// ServeHTTP - verify, acknowledge and queue one Events API request
+func (t *EventsTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var cb eventCallback
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := VerifySlackRequest(t.tiktok.Config.SlackSigningSecret, r.Header, body, t.Now()); err != nil {
+		if t.tiktok.Config.DEBUG {
+			fmt.Println("Rejected Events API request: " + err.Error())
+		}
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := json.Unmarshal(body, &cb); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if cb.Type == "url_verification" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(cb.Challenge))
+		return
+	}
+
+	err = t.queue.push(cb)
+	if err == ErrQueueFull {
+		// slack retries a request that fails, by then the handler may have caught up
+		errTrap(t.tiktok, "Dropped Events API event "+cb.EventID+" in `ServeHTTP` in `slackevents.go`, slack will retry it", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		errTrap(t.tiktok, "Unable to decode Events API event in `ServeHTTP` in `slackevents.go`", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Serve - run the handler over received events without starting a listener, for use behind any http server
+func (t *EventsTransport) Serve(handle MessageHandler) {
+	t.queue.serve(handle, &WebMessenger{tiktok: t.tiktok, api: t.api})
+}
+
+// Stop - stop Serve
+func (t *EventsTransport) Stop() {
+	t.queue.stop()
+}
+
+// Run - look ourselves up then listen for events until the http server dies
+func (t *EventsTransport) Run(handle MessageHandler) error {
+	if err := whoAmI(t.tiktok, t.api); err != nil {
+		return err
+	}
+
+	listen := t.tiktok.Config.EventsListen
+	if listen == "" {
+		listen = ":3000"
+	}
+	path := t.tiktok.Config.EventsPath
+	if path == "" {
+		path = "/slack/events"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, t)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- http.ListenAndServe(listen, mux)
+		SetSlackConnected(false)
+		t.Stop()
+	}()
+
+	fmt.Println("Listening for slack events on " + listen + path)
+	SetSlackConnected(true)
+	t.Serve(handle)
+
+	return <-errs
+}
+
+// socketEnvelope - one Socket Mode frame
+type socketEnvelope struct {
+	EnvelopeID string        `json:"envelope_id"`
+	Type       string        `json:"type"`
+	Reason     string        `json:"reason"`
+	Payload    eventCallback `json:"payload"`
+}
+
+// SocketTransport - receive app_mention and DM events over a Socket Mode websocket, no public endpoint needed
+type SocketTransport struct {
+	tiktok *TikTokConf
+	api    *slack.Client
+	queue  *eventQueue
+	URL    string
+}
+
+// NewSocketTransport - Socket Mode transport using the SlackAppToken app level token
+func NewSocketTransport(tiktok *TikTokConf, api *slack.Client) *SocketTransport {
+	return &SocketTransport{tiktok: tiktok, api: api, queue: newEventQueue(), URL: "https://slack.com/api/apps.connections.open"}
+}
+
+// openSocket - ask slack for a websocket URL to connect to
+func (t *SocketTransport) openSocket() (string, error) {
+	var opened struct {
+		Ok    bool   `json:"ok"`
+		URL   string `json:"url"`
+		Error string `json:"error"`
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.tiktok.Config.SlackAppToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&opened); err != nil {
+		return "", err
+	}
+	if !opened.Ok {
+		return "", errors.New("apps.connections.open failed: " + opened.Error)
+	}
+
+	return opened.URL, nil
+}
+
+// listen - read one websocket connection until slack asks us to reconnect or it drops
+func (t *SocketTransport) listen(wsURL string) error {
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for {
+		var env socketEnvelope
+
+		if err := conn.ReadJSON(&env); err != nil {
+			return err
+		}
+
+		var pushErr error
+		if env.Type == "events_api" {
+			pushErr = t.queue.push(env.Payload)
+		}
+
+		// every envelope has to be acknowledged or slack will resend it.  An event there was no room to queue isn't, so
+		// slack redelivers it the way it retries an Events API request we answered with a 503
+		if env.EnvelopeID != "" && pushErr != ErrQueueFull {
+			if err := conn.WriteJSON(map[string]string{"envelope_id": env.EnvelopeID}); err != nil {
+				return err
+			}
+		}
+
+		switch env.Type {
+		case "hello":
+			SetSlackConnected(true)
+			if t.tiktok.Config.DEBUG {
+				fmt.Println("Socket Mode connected")
+			}
+		case "disconnect":
+			if t.tiktok.Config.DEBUG {
+				fmt.Println("Socket Mode disconnect requested: " + env.Reason)
+			}
+			return nil
+		case "events_api":
+			if pushErr == ErrQueueFull {
+				errTrap(t.tiktok, "Dropped Socket Mode event "+env.Payload.EventID+" without acknowledging it in `listen` in `slackevents.go`", pushErr)
+			} else if pushErr != nil {
+				errTrap(t.tiktok, "Unable to decode Socket Mode event in `listen` in `slackevents.go`", pushErr)
+			}
+		}
+	}
+}
+
+// Run - look ourselves up then keep a Socket Mode connection open forever, reconnecting with backoff
+func (t *SocketTransport) Run(handle MessageHandler) error {
+	if err := whoAmI(t.tiktok, t.api); err != nil {
+		return err
+	}
+
+	go func() {
+		backoff := time.Second
+
+		for {
+			wsURL, err := t.openSocket()
+			if err == nil {
+				backoff = time.Second
+				err = t.listen(wsURL)
+			}
+			SetSlackConnected(false)
+			if err != nil {
+				errTrap(t.tiktok, "Socket Mode connection error in `Run` in `slackevents.go`, reconnecting in "+backoff.String(), err)
+				time.Sleep(backoff)
+				if backoff < time.Minute {
+					backoff = backoff * 2
+				}
+			}
+		}
+	}()
+
+	t.queue.serve(handle, &WebMessenger{tiktok: t.tiktok, api: t.api})
+
+	return errors.New("socket mode transport stopped")
+}
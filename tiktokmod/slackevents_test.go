@@ -0,0 +1,193 @@
+package tiktokmod
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedEvent - an Events API request for body, signed with secret as slack would at signedAt
+func signedEvent(secret string, body string, signedAt time.Time) *http.Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", SlackSignature(secret, timestamp, []byte(body)))
+
+	return req
+}
+
+func mentionEvent(eventID string) string {
+	return `{"type":"event_callback","event_id":"` + eventID + `","event":{"type":"app_mention","user":"U0001","text":"<@UBOT> help","channel":"C0001","ts":"1552570000.000100"}}`
+}
+
+func TestEventsTransport(t *testing.T) {
+	now := time.Unix(1552570000, 0)
+
+	tests := []struct {
+		name       string
+		requests   []*http.Request
+		wantStatus int
+		wantQueued int
+		wantBody   string
+	}{
+		{
+			name:       "valid signature",
+			requests:   []*http.Request{signedEvent(testSigningSecret, mentionEvent("Ev01"), now)},
+			wantStatus: http.StatusOK,
+			wantQueued: 1,
+		},
+		{
+			name:       "bad signature",
+			requests:   []*http.Request{signedEvent("not-the-secret", mentionEvent("Ev01"), now)},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "stale timestamp",
+			requests:   []*http.Request{signedEvent(testSigningSecret, mentionEvent("Ev01"), now.Add(-10*time.Minute))},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "duplicate event_id",
+			requests: []*http.Request{
+				signedEvent(testSigningSecret, mentionEvent("Ev01"), now),
+				signedEvent(testSigningSecret, mentionEvent("Ev01"), now),
+			},
+			wantStatus: http.StatusOK,
+			wantQueued: 1,
+		},
+		{
+			name:       "url verification",
+			requests:   []*http.Request{signedEvent(testSigningSecret, `{"type":"url_verification","challenge":"abc123"}`, now)},
+			wantStatus: http.StatusOK,
+			wantBody:   "abc123",
+		},
+		{
+			name:       "channel messages are ignored",
+			requests:   []*http.Request{signedEvent(testSigningSecret, `{"type":"event_callback","event_id":"Ev02","event":{"type":"message","channel_type":"channel","text":"hi"}}`, now)},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tiktok := &TikTokConf{}
+			tiktok.Config.SlackSigningSecret = testSigningSecret
+			events := NewEventsTransport(tiktok, nil)
+			events.Now = func() time.Time { return now }
+
+			var rec *httptest.ResponseRecorder
+			for _, req := range tc.requests {
+				rec = httptest.NewRecorder()
+				events.ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if got := len(events.queue.incoming); got != tc.wantQueued {
+				t.Errorf("queued %d events, want %d", got, tc.wantQueued)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestEventsTransportQueueFull(t *testing.T) {
+	now := time.Unix(1552570000, 0)
+
+	tiktok := &TikTokConf{}
+	tiktok.Config.SlackSigningSecret = testSigningSecret
+	events := NewEventsTransport(tiktok, nil)
+	events.Now = func() time.Time { return now }
+
+	for i := 0; i < cap(events.queue.incoming); i++ {
+		rec := httptest.NewRecorder()
+		events.ServeHTTP(rec, signedEvent(testSigningSecret, mentionEvent("Ev"+strconv.Itoa(i)), now))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("event %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		events.ServeHTTP(rec, signedEvent(testSigningSecret, mentionEvent("EvFull"), now))
+		done <- rec.Code
+	}()
+
+	select {
+	case code := <-done:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeHTTP blocked on a full queue")
+	}
+
+	// once the handler catches up slack's retry of the dropped event is queued, not taken for a duplicate
+	<-events.queue.incoming
+	rec := httptest.NewRecorder()
+	events.ServeHTTP(rec, signedEvent(testSigningSecret, mentionEvent("EvFull"), now))
+	if rec.Code != http.StatusOK || len(events.queue.incoming) != cap(events.queue.incoming) {
+		t.Errorf("retry: status = %d with %d queued, want 200 and a full queue", rec.Code, len(events.queue.incoming))
+	}
+}
+
+// a Socket Mode event there's no room to queue isn't acknowledged, so slack sends it again
+func TestSocketTransportQueueFull(t *testing.T) {
+	tiktok := &TikTokConf{}
+	events := NewSocketTransport(tiktok, nil)
+	room := cap(events.queue.incoming)
+
+	acked := make(chan []string, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		for i := 0; i <= room; i++ {
+			payload := mentionEvent("Ev" + strconv.Itoa(i))
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"envelope_id":"env`+strconv.Itoa(i)+`","type":"events_api","payload":`+payload+`}`))
+		}
+		_ = conn.WriteJSON(map[string]string{"type": "disconnect", "reason": "refresh_requested"})
+
+		var ids []string
+		for {
+			var ack map[string]string
+			if err := conn.ReadJSON(&ack); err != nil {
+				break
+			}
+			ids = append(ids, ack["envelope_id"])
+		}
+		acked <- ids
+	}))
+	defer server.Close()
+
+	if err := events.listen("ws" + strings.TrimPrefix(server.URL, "http")); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := <-acked
+	if len(ids) != room {
+		t.Fatalf("acknowledged %d envelopes, want %d", len(ids), room)
+	}
+	for _, id := range ids {
+		if id == "env"+strconv.Itoa(room) {
+			t.Errorf("the event that didn't fit in the queue was acknowledged")
+		}
+	}
+}
@@ -1,54 +1,160 @@
 package tiktokmod
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Sprint - Verify sprint is acceptable to execute then do or do not
-func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err error) {
-	var countcards int
-	var countcardsbl int
-	var newsprintcount int
+// sprintRun - everything the phases of one sprint rollover share.  State is what's checkpointed between runs
+type sprintRun struct {
+	retroNo       bool
+	spOpts        SprintData
+	newSprintName string
+	allSquads     Squads
+	journal       *SprintJournal
+	State         SprintRunState
+}
+
+// sprintPhase - one named step of a sprint rollover
+type sprintPhase struct {
+	Name string
+	Run  func(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error
+}
+
+// sprintPhases - the steps of a sprint rollover in the order they run.  Each one is checkpointed in tiktok_sprint_runs
+// when it finishes, so a rollover that fails part way through picks up at the phase that failed when it's run again.
+// The journal starts before the closing report is written so `undo last sprint` takes the report back out too
+var sprintPhases = []sprintPhase{
+	{"record", sprintRecord},
+	{"journal", sprintJournal},
+	{"report", sprintReport},
+	{"close", sprintClose},
+	{"start", sprintStart},
+	{"retro", sprintRetro},
+	{"demo", sprintDemo},
+	{"db", sprintDB},
+	{"announce", sprintAnnounce},
+}
+
+// sprintPhaseIndex - where a phase is in sprintPhases
+func sprintPhaseIndex(name string) int {
+	for i, p := range sprintPhases {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sprint - Verify sprint is acceptable to execute then do or do not.  If the board's last rollover stopped part way it's
+// resumed under the sprint name it started with, skipping the phases already checkpointed.  Otherwise a new sprint is
+// named for today, and a rerun on the day a rollover finished does nothing
+func Sprint(opts Config, tiktok *TikTokConf, trello TrelloAPI, retroNo bool) (message string, err error) {
 	var attachments Attachment
-	var weHaveSpike bool
-	var sOpts SprintData
-	var rboardID string
-	var workingDays int
-	var hush bool
-	var retroMessage string
-	var commentUpdate string
+	var unfinished bool
 
-	// Trello args maps for custom fields
-	var m map[string]string
-	m = make(map[string]string)
-	m["fields"] = "name"
-	m["customFieldItems"] = "true"
+	run := &sprintRun{retroNo: retroNo}
 
-	if tiktok.Config.DEBUG {
-		fmt.Println("Executing Sprint Setup for `" + opts.General.TeamName + "` board!")
+	run.newSprintName, run.State, unfinished, err = tiktok.DB.GetUnfinishedSprintRun(opts.General.BoardID, sprintPhases[len(sprintPhases)-1].Name)
+	if err == nil && !unfinished {
+		// create new sprint name off today's date for the team
+		rightnow := time.Now().In(TeamCalendar(tiktok, opts).Location)
+		run.newSprintName = NewSprintName(opts, rightnow)
+
+		run.State, err = tiktok.DB.GetSprintRun(opts.General.BoardID, run.newSprintName)
 	}
-	if tiktok.Config.LogToSlack {
-		LogToSlack("Executing Sprint Setup for `"+opts.General.TeamName+"` board!", tiktok, attachments)
+	if err != nil {
+		errTrap(tiktok, "Unable to load sprint checkpoints in `sprint.go` for `"+opts.General.TeamName+"` board", err)
+		return "Unable to load sprint checkpoints, nothing has been changed.  See logs.", err
+	}
+
+	next := sprintPhaseIndex(run.State.Phase) + 1
+	if next == len(sprintPhases) {
+		return "Sprint " + run.newSprintName + " is already set up for `" + opts.General.TeamName + "` board, nothing to do.\n", nil
+	}
+
+	if next == 0 {
+		if tiktok.Config.DEBUG {
+			fmt.Println("Executing Sprint Setup for `" + opts.General.TeamName + "` board!")
+		}
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Executing Sprint Setup for `"+opts.General.TeamName+"` board!", tiktok, attachments)
+		}
+	} else {
+		if tiktok.Config.DEBUG {
+			fmt.Println("Resuming Sprint Setup of " + run.newSprintName + " for `" + opts.General.TeamName + "` board at the " + sprintPhases[next].Name + " phase")
+		}
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Resuming Sprint Setup of "+run.newSprintName+" for `"+opts.General.TeamName+"` board at the *"+sprintPhases[next].Name+"* phase", tiktok, attachments)
+		}
 	}
 
 	// Grab current sprint info
-	spOpts, err := GetDBSprint(tiktok, strings.ToLower(opts.General.Sprintname))
+	run.spOpts, err = tiktok.DB.GetSprint(strings.ToLower(opts.General.Sprintname))
 	if err != nil {
 		errTrap(tiktok, "GetDBSprint Error: SQL error in function `sprintgo` in `sprint.go`", err)
 		return
 	}
-	_, _ = GetAllPoints(tiktok, opts, spOpts)
+
+	// Load Squad Information
+	run.allSquads, err = tiktok.DB.GetSquads(opts.General.BoardID)
+	if err != nil {
+		errTrap(tiktok, "Failed DB Call to get squad information in sprint.go func `sprintgo`", err)
+		return "Failed DB Call to get squad information", err
+	}
+
+	if run.State.RolloverID != 0 {
+		run.journal, err = ResumeSprintJournal(tiktok, run.State.RolloverID)
+		if err != nil {
+			errTrap(tiktok, "Unable to reopen the sprint journal in `sprint.go` for `"+opts.General.TeamName+"` board", err)
+			return "Unable to reopen the sprint journal, nothing has been changed.  See logs.", err
+		}
+	} else if next > sprintPhaseIndex("journal") {
+		// checkpointed by a build that journalled after the report, the journal still has to start before any cards move
+		if err = sprintJournal(tiktok, opts, trello, run); err != nil {
+			return "Unable to start the sprint journal, nothing has been changed.  See logs.", err
+		}
+	}
+
+	for _, p := range sprintPhases[next:] {
+		err = p.Run(tiktok, opts, trello, run)
+
+		// save the counts even when a phase fails so the rerun adds to them
+		if err == nil {
+			run.State.Phase = p.Name
+		}
+		if cerr := tiktok.DB.PutSprintRun(opts.General.BoardID, run.newSprintName, run.State); cerr != nil {
+			errTrap(tiktok, "Unable to checkpoint the *"+p.Name+"* phase in `sprint.go` for `"+opts.General.TeamName+"` board", cerr)
+			if err == nil {
+				err = cerr
+			}
+		}
+
+		if err != nil {
+			return "Sprint Setup for `" + opts.General.TeamName + "` board stopped in the *" + p.Name + "* phase: " + err.Error() + "\nFix the problem and start the sprint again, I'll pick up where I left off.\n", err
+		}
+	}
+
+	return "Done Executing Sprint Setup for `" + opts.General.TeamName + "` board\n", nil
+}
+
+// sprintRecord - record the outgoing sprint's points and card history
+func sprintRecord(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+
+	spOpts := run.spOpts
+	_, _ = GetAllPoints(tiktok, trello, opts, spOpts)
 
 	// Record current Sprint squad point data to SQLDB
-	squadTotals, nonPoints, err := SprintSquadPoints(tiktok, opts, spOpts.SprintName)
+	squadTotals, nonPoints, err := SprintSquadPoints(tiktok, trello, opts, spOpts.SprintName)
 	if err != nil {
 		errTrap(tiktok, "Failed to retrieve current sprint squad points for recording, check the logs. Continuing on...", err)
 	}
-	_ = RecordSquadSprintData(tiktok, squadTotals, spOpts.SprintName, nonPoints)
+	_ = tiktok.DB.RecordSquadSprintData(squadTotals, spOpts.SprintName, nonPoints)
 
 	// Dupe old cardtracker table to new table name for historical data
 	tN := strings.Replace(spOpts.SprintName, "-", "_", -1)
@@ -56,47 +162,71 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 	if tiktok.Config.LogToSlack {
 		LogToSlack("Duplicating tiktok_cardtracker to new table `"+tableName+"` for historical records...this may take a few...", tiktok, attachments)
 	}
-	err = DupeTable(tiktok, tableName, "tiktok_cardtracker")
+	err = tiktok.DB.DupeTable(tableName, "tiktok_cardtracker")
 	if err != nil {
 		errTrap(tiktok, "Error attempting to dupe table tiktok_cardtracker to "+tableName, err)
 	}
 
-	// create new sprint name
-	rightnow := time.Now().Local()
-	today := rightnow.Format("01-02-2006")
-	newSprintName := opts.General.Sprintname + "-" + today
-
-	// Load Squad Information
-	allSquads, err := GetDBSquads(tiktok, opts.General.BoardID)
-	if err != nil {
-		errTrap(tiktok, "Failed DB Call to get squad information in sprint.go func `sprintgo`", err)
-		return "Failed DB Call to get squad information", err
-	}
-
 	if tiktok.Config.DEBUG {
-		fmt.Println("Created a new Sprint Name for `" + opts.General.TeamName + "` board - " + newSprintName)
+		fmt.Println("Created a new Sprint Name for `" + opts.General.TeamName + "` board - " + run.newSprintName)
 	}
 	if tiktok.Config.LogToSlack {
-		LogToSlack("Created a new Sprint Name for `"+opts.General.TeamName+"` board - "+newSprintName, tiktok, attachments)
+		LogToSlack("Created a new Sprint Name for `"+opts.General.TeamName+"` board - "+run.newSprintName, tiktok, attachments)
 	}
 
+	return nil
+}
+
+// sprintReport - theme warnings for the next sprint and the closing report for the outgoing one, before any cards move
+func sprintReport(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+
 	// Complain if cards don't have Theme Labels
 	if tiktok.Config.LogToSlack {
 		LogToSlack("Checking Next Sprint list for Card Themes on `"+opts.General.TeamName+"` board", tiktok, attachments)
 	}
-	jmessage, _ := CheckThemes(tiktok, opts, opts.General.NextsprintID)
+	jmessage, _ := CheckThemes(tiktok, trello, opts, opts.General.NextsprintID)
 	if jmessage != "" {
 		attachments.Color = "#ff0000"
 		attachments.Text = jmessage
-		Wrangler(tiktok.Config.SlackHook, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "*WARNING*! The following cards do *not* have appropriate Theme Labels on them: ", opts.General.ComplaintChannel, attachments)
+	}
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll function `sprintgo` in `sprint.go` for `"+opts.General.TeamName+"` board", err)
+		return errors.New("error in RetrieveAll cards API query, see logs")
+	}
+
+	SprintClosingReport(tiktok, opts, run.spOpts, allTheThings, run.allSquads)
+
+	return nil
+}
+
+// sprintJournal - start the undo journal, every card is journalled before it changes so `undo last sprint` can put the
+// board back
+func sprintJournal(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) (err error) {
+	run.journal, err = StartSprintJournal(tiktok, opts, run.spOpts, run.newSprintName)
+	if err != nil {
+		errTrap(tiktok, "Unable to start the sprint journal in `sprint.go` for `"+opts.General.TeamName+"` board", err)
+		return errors.New("unable to start the sprint journal")
 	}
-	attachments.Text = ""
-	attachments.Color = ""
+	run.State.RolloverID = run.journal.rollover.ID
 
-	allTheThings, err := RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	return nil
+}
+
+// sprintClose - carry ROLL-OVER cards into Next Sprint and send the rest of the unfinished cards back to the backlog
+func sprintClose(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+	var commentUpdate string
+
+	failed := 0
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll function `sprintgo` in `sprint.go` for `"+opts.General.TeamName+"` board", err)
-		return "Error in RetrieveAll cards API query, see logs.", err
+		return errors.New("error in RetrieveAll cards API query, see logs")
 	}
 
 	for _, aTt := range allTheThings.Cards {
@@ -104,6 +234,11 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 
 			if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
 
+				if run.journal.Card(aTt, "close sprint") != nil {
+					failed++
+					continue
+				}
+
 				moveitmoveit := false
 				for _, l := range aTt.Labels {
 
@@ -117,19 +252,20 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 					commentUpdate = ""
 
 					// move card to next sprint
-					err := MoveCardList(tiktok, aTt.ID, opts.General.NextsprintID)
+					err := trello.MoveCardList(aTt.ID, opts.General.NextsprintID)
 
 					if err != nil {
 						errTrap(tiktok, "Error moving card `"+aTt.ID+"` to *Next Sprint* ... skipping", err)
+						failed++
 					} else {
 						if tiktok.Config.LogToSlack {
 							LogToSlack("Moving card _"+aTt.Name+"_ ("+aTt.ID+") to *Next Sprint* column on `"+opts.General.TeamName+"` board.", tiktok, attachments)
 						}
-						countcards++
+						run.State.Rolled++
 						commentUpdate = commentUpdate + "Moving incomplete card from current sprint, per WDW/planning discussions.\n"
 
 						// sort card to top of sprint
-						err := ReOrderCardInList(tiktok, aTt.ID, "top")
+						err := trello.ReOrderCardInList(aTt.ID, "top")
 						if err != nil {
 							errTrap(tiktok, "Couldn't not move card to top of list on card `"+aTt.Name+"` in `ReOrderCardInList` in `sprint.go`", err)
 						} else {
@@ -137,14 +273,14 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 						}
 
 						// remove ROLL-OVER Label from card
-						err = removeLabel(aTt.ID, opts.General.ROLabelID, tiktok)
+						err = trello.RemoveLabel(aTt.ID, opts.General.ROLabelID)
 						if err != nil {
 							errTrap(tiktok, "Couldn't remove Roll Over label on card `"+aTt.Name+"` in `ReOrderCardInList` in `sprint.go`", err)
 						} else {
 							commentUpdate = commentUpdate + "Removed ROLL-OVER label\n"
 						}
 						// add card comment
-						err = CommentCard(aTt.ID, commentUpdate, tiktok)
+						err = trello.CommentCard(aTt.ID, commentUpdate)
 						if err != nil {
 							errTrap(tiktok, "Couldn't put change comments on card `"+aTt.Name+"` in `ReOrderCardInList` in `sprint.go`", err)
 						}
@@ -152,20 +288,21 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 
 				} else {
 					// move card to backlog
-					err := MoveCardList(tiktok, aTt.ID, opts.General.BacklogID)
+					err := trello.MoveCardList(aTt.ID, opts.General.BacklogID)
 					if err != nil {
 						errTrap(tiktok, "Error moving card `"+aTt.ID+"` to *Backlog* ... skipping", err)
+						failed++
 					} else {
 						if tiktok.Config.LogToSlack {
 							LogToSlack("Moving card _"+aTt.Name+"_ ("+aTt.ID+") to *Backlog* column on `"+opts.General.TeamName+"` board.", tiktok, attachments)
 						}
-						countcardsbl++
+						run.State.Backlogged++
 
-						err = PutCustomField(aTt.ID, opts.General.CfsprintID, tiktok, "number", " ")
+						err = trello.PutCustomField(aTt.ID, opts.General.CfsprintID, "number", " ")
 						if err != nil {
 							errTrap(tiktok, "Trello error in PutCustomField `sprint.go` while moving card to backlog for `"+opts.General.TeamName+"` board", err)
 						}
-						err = CommentCard(aTt.ID, "Moving card to backlog from current sprint per WDW planning discussion.", tiktok)
+						err = trello.CommentCard(aTt.ID, "Moving card to backlog from current sprint per WDW planning discussion.")
 						if err != nil {
 							errTrap(tiktok, "Couldn't put change comments on card `"+aTt.Name+"` in `ReOrderCardInList` in `sprint.go`", err)
 						}
@@ -178,246 +315,294 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 
 	}
 
-	// Move all cards in "Next Sprint" column to "Ready for Work"
-	var totalPoints int
-	var points int
+	if failed > 0 {
+		return errors.New(strconv.Itoa(failed) + " cards could not be moved out of the sprint")
+	}
+
+	return nil
+}
+
+// sprintStart - Move all cards in "Next Sprint" column to "Ready for Work".  Cards an earlier run of this rollover
+// already journalled may have been held back, or the run may have stopped before moving them.  They're checked again
+// without another rename comment or alert, held back cards stay where they are and the rest are moved
+func sprintStart(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+
+	failed := 0
+	if run.State.SquadPoints == nil {
+		run.State.SquadPoints = make(map[string]int)
+	}
 
-	// re-read the board because we may have moved cards in the above function
-	allTheThings, err = RetrieveAll(tiktok, opts.General.BoardID, "visible")
+	started, err := run.journal.Journalled("start sprint")
+	if err != nil {
+		errTrap(tiktok, "Unable to read the sprint journal in `sprintStart` in `sprint.go` for `"+opts.General.TeamName+"` board", err)
+		return errors.New("unable to read the sprint journal")
+	}
+	// re-read the board because we may have moved cards in the close phase
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
 	if err != nil {
 		errTrap(tiktok, "Trello error in RetrieveAll function `sprintgo` in `sprint.go` for `"+opts.General.TeamName+"` board", err)
-		return "Error in RetrieveAll cards API query, see logs.", err
+		return errors.New("error in RetrieveAll cards API query, see logs")
 	}
 
 	for _, aTt := range allTheThings.Cards {
 		if !aTt.Closed {
 			if aTt.IDList == opts.General.NextsprintID {
 
-				// update custom field for sprint name
-				for _, cusval := range aTt.CustomFieldItems {
-					if cusval.IDCustomField == opts.General.CfsprintID {
-						oldSprintname := string(cusval.Value.Text)
-						commentUpdate := "Renaming sprint field from (" + oldSprintname + ") to " + newSprintName + "\n"
-						_ = CommentCard(aTt.ID, commentUpdate, tiktok)
-					}
+				points := CardPoints(tiktok, opts, aTt)
+
+				if !started[aTt.ID] && run.journal.Card(aTt, "start sprint") != nil {
+					failed++
+					continue
 				}
-				err = PutCustomField(aTt.ID, opts.General.CfsprintID, tiktok, "text", newSprintName)
-				if err != nil {
-					errTrap(tiktok, "Trello error in PutCustomField `sprint.go` for `"+opts.General.TeamName+"` board", err)
+				if !sprintStartCard(tiktok, opts, trello, run, aTt, points, started[aTt.ID]) {
+					continue
 				}
 
-				// update custom field burndown story points
-				pluginCard, _ := GetPowerUpField(aTt.ID, tiktok)
-
-				for _, p := range pluginCard {
-
-					if p.IDPlugin == tiktok.Config.PointsPowerUpID {
-
-						var plugins PointsHistory
-
-						pluginJSON := []byte(p.Value)
-						json.Unmarshal(pluginJSON, &plugins)
-						points = plugins.Points
-						totalPoints = totalPoints + points
-					}
+				// otherwise move card, silenced cards are always moved
+				if tiktok.Config.LogToSlack {
+					attachments.Color = ""
+					attachments.Text = ""
+					LogToSlack("Moving card _"+aTt.Name+"_ ("+aTt.ID+") to *Ready for Work* column on `"+opts.General.TeamName+"` board, for the next sprint.", tiktok, attachments)
 				}
-				spoints := strconv.Itoa(points)
-				err = PutCustomField(aTt.ID, opts.General.CfpointsID, tiktok, "text", spoints)
+				err = trello.MoveCardList(aTt.ID, opts.General.ReadyForWork)
 				if err != nil {
-					errTrap(tiktok, "Trello error in PutCustomField `sprint.go` trying to update burndown custom point field for `"+opts.General.TeamName+"` board", err)
+					errTrap(tiktok, "Error moving card `"+aTt.ID+"` to *Ready for Work* ... skipping", err)
+					failed++
+					continue
 				}
+				run.State.Started++
+				run.State.TotalPoints = run.State.TotalPoints + points
 
 				// update squad points
 				for _, labels := range aTt.Labels {
 
-					for s, squad := range allSquads {
+					for _, squad := range run.allSquads {
 						if opts.General.BoardID == squad.BoardID && squad.LabelID == labels.ID {
-							tPts := squad.SquadPts
-							allSquads[s].SquadPts = tPts + points
+							tPts := run.State.SquadPoints[squad.Squadname]
+							run.State.SquadPoints[squad.Squadname] = tPts + points
 							if tiktok.Config.DEBUG {
-								fmt.Println(squad.Squadname + " found so adding " + strconv.Itoa(points) + " to the existing " + strconv.Itoa(tPts) + " for total of " + strconv.Itoa(allSquads[s].SquadPts))
+								fmt.Println(squad.Squadname + " found so adding " + strconv.Itoa(points) + " to the existing " + strconv.Itoa(tPts) + " for total of " + strconv.Itoa(tPts+points))
 							}
 							if tiktok.Config.LogToSlack {
 								attachments.Color = ""
 								attachments.Text = ""
-								LogToSlack(squad.Squadname+" found so adding "+strconv.Itoa(points)+" to the existing "+strconv.Itoa(tPts)+" for total of "+strconv.Itoa(allSquads[s].SquadPts), tiktok, attachments)
+								LogToSlack(squad.Squadname+" found so adding "+strconv.Itoa(points)+" to the existing "+strconv.Itoa(tPts)+" for total of "+strconv.Itoa(tPts+points), tiktok, attachments)
 							}
 						}
 					}
 
 				}
+			}
+		}
+	}
 
-				hush = false
+	if failed > 0 {
+		return errors.New(strconv.Itoa(failed) + " cards could not be moved into the new sprint")
+	}
 
-				for _, labels := range aTt.Labels {
-					if labels.ID == opts.General.SilenceCardLabel {
-						hush = true
-					}
-				}
+	return nil
+}
 
-				if !hush {
+// sprintStartCard - rename, re-point and unassign a card going into the new sprint.  Cards with too many or no points
+// are alerted on and false is returned so they stay in Next Sprint.  A card an earlier run already started gets the
+// same changes, which are safe to make twice, without the rename comment or the alerts
+func sprintStartCard(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun, aTt BoardCard, points int, resumed bool) bool {
+	var attachments Attachment
 
-					attachments.Color = ""
-					attachments.Text = ""
+	// update custom field for sprint name
+	for _, cusval := range aTt.CustomFieldItems {
+		if cusval.IDCustomField == opts.General.CfsprintID && !resumed {
+			oldSprintname := string(cusval.Value.Text)
+			commentUpdate := "Renaming sprint field from (" + oldSprintname + ") to " + run.newSprintName + "\n"
+			_ = trello.CommentCard(aTt.ID, commentUpdate)
+		}
+	}
+	err := trello.PutCustomField(aTt.ID, opts.General.CfsprintID, "text", run.newSprintName)
+	if err != nil {
+		errTrap(tiktok, "Trello error in PutCustomField `sprint.go` for `"+opts.General.TeamName+"` board", err)
+	}
 
-					// Remove any members from the card
-					for _, m := range aTt.IDMembers {
-						err := RemoveHead(tiktok, aTt.ID, m)
-						if err != nil {
-							errTrap(tiktok, "Trello RemoveMember function error in SprintGo in `sprint.go`", err)
-						} else {
-							if tiktok.Config.LogToSlack {
-								LogToSlack("Removing "+m+" from card `"+aTt.Name+"`.", tiktok, attachments)
-							}
-						}
-					}
+	// update custom field burndown story points
+	spoints := strconv.Itoa(points)
+	if !PointsFromField(opts) {
+		err = trello.PutCustomField(aTt.ID, opts.General.CfpointsID, "text", spoints)
+		if err != nil {
+			errTrap(tiktok, "Trello error in PutCustomField `sprint.go` trying to update burndown custom point field for `"+opts.General.TeamName+"` board", err)
+		}
+	}
 
-					// verify if we have a {SPIKE} card or not
-					spikeText := Between(aTt.ID, "{", "}")
-					if strings.ToLower(spikeText) == "spike" {
-						weHaveSpike = true
-					} else {
-						weHaveSpike = false
-					}
+	if CardHushed(opts, aTt) {
+		return true
+	}
 
-					if points > opts.General.MaxPoints {
-						// send an alert and don't move the card
-						if tiktok.Config.LogToSlack {
-							LogToSlack("Found card greater than "+strconv.Itoa(opts.General.MaxPoints)+" points in `Next Sprint` column. Card will *not* be moved.  Sending an alert to "+opts.General.ComplaintChannel, tiktok, attachments)
-						}
+	// Remove any members from the card
+	for _, m := range aTt.IDMembers {
+		err := trello.RemoveHead(aTt.ID, m)
+		if err != nil {
+			errTrap(tiktok, "Trello RemoveMember function error in SprintGo in `sprint.go`", err)
+		} else {
+			if tiktok.Config.LogToSlack {
+				LogToSlack("Removing "+m+" from card `"+aTt.Name+"`.", tiktok, attachments)
+			}
+		}
+	}
 
-						amessage := "Card #" + strconv.Itoa(aTt.IDShort) + " contains _*" + spoints + "*_ points!\n"
-						amessage = amessage + "Please address it. - <" + aTt.ShortURL + "|" + aTt.Name + ">"
-						attachments.Color = "#ff0000"
-						attachments.Text = amessage
+	blocked := SprintBlocked(opts, aTt, points)
 
-						Wrangler(tiktok.Config.SlackHook, "<!here> *WARNING!* High Point Card Found!", opts.General.SprintChannel, tiktok.Config.SlackEmoji, attachments)
+	if resumed {
+		// alerted on by the run that started it
+		return blocked == ""
+	}
 
-					} else if spoints == "0" && !weHaveSpike {
-						// send an alert and don't move the card if points is 0 AND its not a {SPIKE}
-						if tiktok.Config.LogToSlack {
-							LogToSlack("Found card with *zero* points in `Next Sprint` column. Card will *not* be moved.  Sending an alert to "+opts.General.ComplaintChannel, tiktok, attachments)
-						}
+	if blocked != "" && points > opts.General.MaxPoints {
+		// send an alert and don't move the card
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Found card greater than "+strconv.Itoa(opts.General.MaxPoints)+" points in `Next Sprint` column. Card will *not* be moved.  Sending an alert to "+opts.General.ComplaintChannel, tiktok, attachments)
+		}
 
-						amessage := "Card <" + aTt.ShortURL + "|" + aTt.Name + "> contains _*NO*_ points!\n"
-						attachments.Color = "#ff0000"
-						attachments.Text = amessage
+		amessage := "Card #" + strconv.Itoa(aTt.IDShort) + " contains _*" + spoints + "*_ points!\n"
+		amessage = amessage + "Please address it. - <" + aTt.ShortURL + "|" + aTt.Name + ">"
+		attachments.Color = "#ff0000"
+		attachments.Text = amessage
 
-						Wrangler(tiktok.Config.SlackHook, "<!here> *WARNING!* Card with No Points!", opts.General.SprintChannel, tiktok.Config.SlackEmoji, attachments)
+		TeamWrangler(tiktok, opts, "<!here> *WARNING!* High Point Card Found!", opts.General.SprintChannel, attachments)
+		return false
 
-					} else {
-						// otherwise move card
-						if tiktok.Config.LogToSlack {
-							attachments.Color = ""
-							attachments.Text = ""
-							LogToSlack("Moving card _"+aTt.Name+"_ ("+aTt.ID+") to *Ready for Work* column on `"+opts.General.TeamName+"` board, for the next sprint.", tiktok, attachments)
-						}
-						_ = MoveCardList(tiktok, aTt.ID, opts.General.ReadyForWork)
-						newsprintcount++
-					}
-				} else { // card is silenced so still need to move it
-					if tiktok.Config.LogToSlack {
-						attachments.Color = ""
-						attachments.Text = ""
-						LogToSlack("Moving card _"+aTt.Name+"_ ("+aTt.ID+") to *Ready for Work* column on `"+opts.General.TeamName+"` board, for the next sprint.", tiktok, attachments)
-					}
-					_ = MoveCardList(tiktok, aTt.ID, opts.General.ReadyForWork)
-					newsprintcount++
-				}
-			}
+	} else if blocked != "" {
+		// send an alert and don't move the card if points is 0 AND its not a {SPIKE}
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Found card with *zero* points in `Next Sprint` column. Card will *not* be moved.  Sending an alert to "+opts.General.ComplaintChannel, tiktok, attachments)
 		}
+
+		amessage := "Card <" + aTt.ShortURL + "|" + aTt.Name + "> contains _*NO*_ points!\n"
+		attachments.Color = "#ff0000"
+		attachments.Text = amessage
+
+		TeamWrangler(tiktok, opts, "<!here> *WARNING!* Card with No Points!", opts.General.SprintChannel, attachments)
+		return false
 	}
 
-	// Create Retro Board for next sprint
-	if retroNo {
-		attachments.Color = ""
-		attachments.Text = ""
+	return true
+}
 
+// sprintRetro - Create Retro Board for next sprint.  A board made by an earlier run that failed is reused
+func sprintRetro(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+	var retroMessage string
+
+	if run.retroNo {
 		if tiktok.Config.DEBUG {
 			fmt.Println("Supressing creation of Retroboard due to suppress command being given.")
 		}
 		if tiktok.Config.LogToSlack {
 			LogToSlack("Suppressing creation of Retro Board for `"+opts.General.TeamName+" on the next sprint retro due to over-ride! command being given.", tiktok, attachments)
 		}
+		return nil
+	}
+
+	boardName := "Retro: " + run.newSprintName
+	rboardID := run.State.RetroID
+	if rboardID != "" {
+		if tiktok.Config.LogToSlack {
+			LogToSlack("Retro board "+rboardID+" was already created for `"+opts.General.TeamName+"` on an earlier run, reusing it.", tiktok, attachments)
+		}
 	} else {
-		boardName := "Retro: " + newSprintName
-		trellout, err := CreateBoard(boardName, opts.General.TrelloOrg, tiktok)
+		trellout, err := trello.CreateBoard(boardName, opts.General.TrelloOrg)
 		if err != nil {
 			errTrap(tiktok, "Trello error in CreateBoard `sprint.go` for `"+opts.General.TeamName+"` board", err)
-			return "Trello error in CreateBoard `sprint.go` for `" + opts.General.TeamName + "` board", err
+			return errors.New("trello error in CreateBoard")
 		}
 		rboardID = trellout.ID
+		run.State.RetroID = rboardID
+		run.journal.Retro(rboardID)
+	}
 
-		// Create lists on new board.  Create in reverse order you want them to display in
-		err = CreateList(rboardID, "Completed", tiktok)
-		err = CreateList(rboardID, "Action Items", tiktok)
-		err = CreateList(rboardID, "Vent", tiktok)
-		err = CreateList(rboardID, "Stop Doing", tiktok)
-		err = CreateList(rboardID, "Start Doing", tiktok)
-		err = CreateList(rboardID, "What Needs Improvement", tiktok)
-		err = CreateList(rboardID, "What Went Well", tiktok)
-
-		if tiktok.Config.DEBUG {
-			fmt.Println("Creating Sprint Retro Board: " + boardName)
+	// Create lists on new board, skipping any an earlier run made.  Create in reverse order you want them to display in
+	existing, err := trello.GetLists(rboardID)
+	if err != nil {
+		errTrap(tiktok, "Trello error in GetLists `sprint.go` for `"+opts.General.TeamName+"` retro board", err)
+		return errors.New("trello error in GetLists")
+	}
+	for _, listName := range []string{"Completed", "Action Items", "Vent", "Stop Doing", "Start Doing", "What Needs Improvement", "What Went Well"} {
+		made := false
+		for _, l := range existing {
+			if l.Name == listName {
+				made = true
+			}
 		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Created next sprint Retro Board _"+boardName+"_ for `"+opts.General.TeamName+"`, for the next sprint retro.", tiktok, attachments)
+		if made {
+			continue
 		}
-
-		// Assign new board to RETRO collections
-		if opts.General.RetroCollectionID != "" {
-			out := AssignCollection(rboardID, opts.General.RetroCollectionID, tiktok)
-
-			if tiktok.Config.DEBUG {
-				fmt.Println(out)
-			}
-			if tiktok.Config.LogToSlack {
-				LogToSlack(out+" for Retro board _"+boardName+"_ for `"+opts.General.TeamName+"`", tiktok, attachments)
-			}
-		} else {
-			if tiktok.Config.LogToSlack {
-				LogToSlack("No Trello `Collection` specified in config for Retro board _"+boardName+"_ for `"+opts.General.TeamName+"`", tiktok, attachments)
-			}
+		err = trello.CreateList(rboardID, listName)
+		if err != nil {
+			errTrap(tiktok, "Trello error in CreateList `sprint.go` adding `"+listName+"` to the `"+opts.General.TeamName+"` retro board", err)
+			return errors.New("trello error in CreateList")
 		}
+	}
 
-		// Add team members to the board
-		attachments.Color = ""
-		attachments.Text = ""
-		retroMessage = ""
-
-		retroUsers, err := GetDBUsers(tiktok)
+	if tiktok.Config.DEBUG {
+		fmt.Println("Creating Sprint Retro Board: " + boardName)
+	}
+	if tiktok.Config.LogToSlack {
+		LogToSlack("Created next sprint Retro Board _"+boardName+"_ for `"+opts.General.TeamName+"`, for the next sprint retro.", tiktok, attachments)
+	}
 
-		for _, u := range retroUsers {
-			err = AddBoardMember(tiktok, rboardID, u.Trello)
-			if err != nil {
-				errTrap(tiktok, "Error adding member "+u.Name+" to new Retro Board.  Trello error in AddBoardMember `sprint.go`", err)
-			}
+	// Assign new board to RETRO collections
+	if opts.General.RetroCollectionID != "" {
+		out := trello.AssignCollection(rboardID, opts.General.RetroCollectionID)
 
-			retroMessage = retroMessage + "Member " + u.Name + " (" + u.Trello + ") \n"
+		if tiktok.Config.DEBUG {
+			fmt.Println(out)
 		}
-
 		if tiktok.Config.LogToSlack {
-			attachments.Color = "#0000ff"
-			attachments.Text = retroMessage
-			LogToSlack("Following users added to new Retro board "+boardName+" ("+rboardID+")", tiktok, attachments)
+			LogToSlack(out+" for Retro board _"+boardName+"_ for `"+opts.General.TeamName+"`", tiktok, attachments)
+		}
+	} else {
+		if tiktok.Config.LogToSlack {
+			LogToSlack("No Trello `Collection` specified in config for Retro board _"+boardName+"_ for `"+opts.General.TeamName+"`", tiktok, attachments)
+		}
+	}
+
+	// Add team members to the board
+	retroUsers, err := tiktok.DB.GetUsers()
+	if err != nil {
+		errTrap(tiktok, "DB error in GetUsers `sprint.go` adding members to the `"+opts.General.TeamName+"` retro board", err)
+		return errors.New("unable to get users for the retro board")
+	}
+
+	for _, u := range retroUsers {
+		err = trello.AddBoardMember(rboardID, u.Trello)
+		if err != nil {
+			errTrap(tiktok, "Error adding member "+u.Name+" to new Retro Board.  Trello error in AddBoardMember `sprint.go`", err)
 		}
 
-		// Output
-		attachments.Color = "#00aaff"
-		attachments.Text = "I created this sprints Retro board and its called " + boardName + "!\n https://trello.com/b/" + rboardID + "/"
-		Wrangler(tiktok.Config.SlackHook, "*Notice!*", opts.General.RetroChannel, tiktok.Config.SlackEmoji, attachments)
+		retroMessage = retroMessage + "Member " + u.Name + " (" + u.Trello + ") \n"
+	}
 
+	if tiktok.Config.LogToSlack {
+		attachments.Color = "#0000ff"
+		attachments.Text = retroMessage
+		LogToSlack("Following users added to new Retro board "+boardName+" ("+rboardID+")", tiktok, attachments)
 	}
 
-	attachments.Color = ""
-	attachments.Text = ""
+	// Output
+	attachments.Color = "#00aaff"
+	attachments.Text = "I created this sprints Retro board and its called " + boardName + "!\n https://trello.com/b/" + rboardID + "/"
+	TeamWrangler(tiktok, opts, "*Notice!*", opts.General.RetroChannel, attachments)
+
+	return nil
+}
+
+// sprintDemo - Add Demo card list to demo board if it exists
+func sprintDemo(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
 
-	// Add Demo card list to demo board if it exists
 	if opts.General.DemoBoardID != "" {
-		listName := "DEMO: Sprint " + newSprintName
-		aTt, _ := RetrieveAll(tiktok, opts.General.DemoBoardID, "visible")
+		listName := "DEMO: Sprint " + run.newSprintName
+		aTt, _ := trello.RetrieveAll(opts.General.DemoBoardID, "visible")
 		demoBoardID := aTt.ID
-		err = CreateList(demoBoardID, listName, tiktok)
+		err := trello.CreateList(demoBoardID, listName)
 		if err != nil {
 			errTrap(tiktok, "Error attempting to add list called `"+listName+"` to Demo board `"+opts.General.DemoBoardID+"` in `sprint.go`", err)
 		} else {
@@ -431,79 +616,74 @@ func Sprint(opts Config, tiktok *TikTokConf, retroNo bool) (message string, err
 		}
 	}
 
-	sprintStartTime := time.Now().Local()
-	sprintStartTime.Format("2006-01-02 15:04:05")
+	return nil
+}
+
+// sprintDB - Update SQL DB with Sprint Data
+func sprintDB(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+	var sOpts SprintData
+
+	sprintStartTime := time.Now()
 
 	// Figure out working days in sprint accounting for holidays
 	if tiktok.Config.LogToSlack {
 		LogToSlack("Calculating working days next sprint based on known Holidays", tiktok, attachments)
 	}
-	oneDay := int64(86400)
-	startDate := int64(sprintStartTime.Unix())
-	eD := startDate + (oneDay * int64(opts.General.SprintDuration))
-	endDate := int64(eD)
-
-	workingDays = 0
-	for timestamp := startDate; timestamp < endDate; timestamp += oneDay {
-		valid, holiday := IsHoliday(tiktok, time.Unix(timestamp, 0))
-		if !valid {
-			workingDays = workingDays + 1
-		} else {
-			if tiktok.Config.LogToSlack {
-				LogToSlack("Holiday found `"+holiday.Name+"` skipping as a work day.", tiktok, attachments)
-			}
-		}
-	}
-
-	// estimate # of weekends based on sprint length (divide by 7 basically)
-	totalWeekendDays := (float64(opts.General.SprintDuration) / float64(7)) * 2
-
-	//subtract 4 days for weekends
-	wDays := workingDays - int(totalWeekendDays)
+	wDays, totalWeekendDays := SprintWorkingDays(tiktok, opts, sprintStartTime)
 
 	if tiktok.Config.LogToSlack {
-		LogToSlack("Based on upcoming Holidays and "+strconv.Itoa(int(totalWeekendDays))+" weekend days this will make "+strconv.Itoa(wDays)+" working days this next sprint", tiktok, attachments)
+		LogToSlack("Based on upcoming Holidays and "+strconv.Itoa(totalWeekendDays)+" weekend days this will make "+strconv.FormatFloat(wDays, 'f', -1, 64)+" working days this next sprint", tiktok, attachments)
 	}
 
-	// Update SQL DB with Sprint Data
-	sOpts.SprintStart = sprintStartTime
+	sOpts.SprintStart = TeamCalendar(tiktok, opts).DBDate(sprintStartTime)
 	sOpts.Duration = opts.General.SprintDuration
-	sOpts.RetroID = rboardID
-	sOpts.SprintName = newSprintName
+	sOpts.RetroID = run.State.RetroID
+	sOpts.SprintName = run.newSprintName
 	sOpts.TeamID = strings.ToLower(opts.General.Sprintname)
 	sOpts.WorkingDays = wDays
+	// what the start phase moved in is what the sprint committed to, the burndowns recorded today can still be the old
+	// sprint's
+	sOpts.CommittedPts = sql.NullInt64{Int64: int64(run.State.TotalPoints), Valid: true}
 
-	err = PutDBSprint(tiktok, sOpts)
+	err := tiktok.DB.PutSprint(sOpts)
 	if err != nil {
 		errTrap(tiktok, "Error writing sprint data to SQL DB via func `PutDBSprint` in `sprint.go`", err)
+		return errors.New("unable to write the new sprint to the DB")
 	}
 
 	// Re-record points for new sprint
-	_, _ = GetAllPoints(tiktok, opts, sOpts)
-
-	// Update slack with goodness
-	hmessage := "*New Sprint Active* - (<https://trello.com/b/" + opts.General.BoardID + "|" + newSprintName + ">)"
-	amessage := "Total cards moved from current sprint to next sprint: " + strconv.Itoa(countcards) + "\n"
-	amessage = amessage + "Total cards moved to Backlog: " + strconv.Itoa(countcardsbl) + "\n"
-	amessage = amessage + "Total cards in Next Sprint: " + strconv.Itoa(newsprintcount) + "\n\n"
-	for _, s := range allSquads {
+	_, _ = GetAllPoints(tiktok, trello, opts, sOpts)
+
+	return nil
+}
+
+// sprintAnnounce - Update slack with goodness
+func sprintAnnounce(tiktok *TikTokConf, opts Config, trello TrelloAPI, run *sprintRun) error {
+	var attachments Attachment
+
+	hmessage := "*New Sprint Active* - (<https://trello.com/b/" + opts.General.BoardID + "|" + run.newSprintName + ">)"
+	amessage := "Total cards moved from current sprint to next sprint: " + strconv.Itoa(run.State.Rolled) + "\n"
+	amessage = amessage + "Total cards moved to Backlog: " + strconv.Itoa(run.State.Backlogged) + "\n"
+	amessage = amessage + "Total cards in Next Sprint: " + strconv.Itoa(run.State.Started) + "\n\n"
+	for _, s := range run.allSquads {
 		if opts.General.BoardID == s.BoardID {
-			amessage = amessage + "Total `" + s.Squadname + "` Points: " + strconv.Itoa(s.SquadPts) + "\n"
+			amessage = amessage + "Total `" + s.Squadname + "` Points: " + strconv.Itoa(run.State.SquadPoints[s.Squadname]) + "\n"
 		}
 	}
-	amessage = amessage + "Total points added for this Sprint: " + strconv.Itoa(totalPoints) + "\n"
+	amessage = amessage + "Total points added for this Sprint: " + strconv.Itoa(run.State.TotalPoints) + "\n"
 
 	attachments.Color = "#00ba2b"
 	attachments.Text = amessage
 
-	Wrangler(tiktok.Config.SlackHook, hmessage, opts.General.SprintChannel, tiktok.Config.SlackEmoji, attachments)
+	TeamWrangler(tiktok, opts, hmessage, opts.General.SprintChannel, attachments)
 
 	if tiktok.Config.DEBUG {
-		fmt.Println("Total Cards moved from Sprint to Sprint: " + strconv.Itoa(countcards))
-		fmt.Println("Total Cards moved to Backlog: " + strconv.Itoa(countcardsbl))
-		fmt.Println("Total Cards moved into new Sprint: " + strconv.Itoa(newsprintcount))
-		fmt.Println("Total Points aded for this Sprint: " + strconv.Itoa(totalPoints))
+		fmt.Println("Total Cards moved from Sprint to Sprint: " + strconv.Itoa(run.State.Rolled))
+		fmt.Println("Total Cards moved to Backlog: " + strconv.Itoa(run.State.Backlogged))
+		fmt.Println("Total Cards moved into new Sprint: " + strconv.Itoa(run.State.Started))
+		fmt.Println("Total Points aded for this Sprint: " + strconv.Itoa(run.State.TotalPoints))
 	}
 
-	return "Done Executing Sprint Setup for `" + opts.General.TeamName + "` board\n", nil
+	return nil
 }
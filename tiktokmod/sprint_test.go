@@ -0,0 +1,447 @@
+package tiktokmod
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	rollCard = "5c8a0e2a2b3c4d5e6f700001"
+	doneCard = "5c8a0e2a2b3c4d5e6f700002"
+	nextCard = "5c8a0e2a2b3c4d5e6f700003"
+)
+
+func TestSprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		retroNo  bool
+		setup    func(tt *testTeam)
+		wantErr  bool
+		wantMsg  string
+		wantList map[string]string
+		wantDB   []string
+		noDB     []string
+		retro    bool
+		slack    []string
+	}{
+		{
+			name:    "fresh rollover",
+			wantMsg: "Done Executing Sprint Setup",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a5",
+				doneCard: "5c8a0e1f2b3c4d5e6f7000a8",
+				nextCard: "5c8a0e1f2b3c4d5e6f7000a5",
+			},
+			wantDB: []string{"CREATE TABLE tiktok_Example_01_01_2019", "INSERT tiktok_sprint_rollovers", "INSERT tiktok_main", "tiktok_sprint_journal"},
+			retro:  true,
+			slack:  []string{"Total cards moved from current sprint to next sprint: 1", "Total cards in Next Sprint: 2", "Total points added for this Sprint: 8"},
+		},
+		{
+			name:    "retro board suppressed",
+			retroNo: true,
+			wantMsg: "Done Executing Sprint Setup",
+			wantDB:  []string{"INSERT tiktok_main"},
+			retro:   false,
+		},
+		{
+			name: "high point card stays in next sprint",
+			setup: func(tt *testTeam) {
+				tt.trello.Fixture.PluginData[nextCard][0].Value = `{"points":21}`
+			},
+			wantMsg: "Done Executing Sprint Setup",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a5",
+				nextCard: "5c8a0e1f2b3c4d5e6f7000a4",
+			},
+			retro: true,
+			slack: []string{"High Point Card Found", "Total cards in Next Sprint: 1"},
+		},
+		{
+			name: "already finished",
+			setup: func(tt *testTeam) {
+				tt.db.on("FROM tiktok_sprint_runs WHERE boardid=? AND sprintname=?", []string{"state"}, []driver.Value{`{"phase":"announce"}`})
+			},
+			wantMsg: "nothing to do",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a6",
+				nextCard: "5c8a0e1f2b3c4d5e6f7000a4",
+			},
+			noDB: []string{"INSERT tiktok_main", "INSERT tiktok_sprint_rollovers"},
+		},
+		{
+			name: "resumes after the report phase",
+			setup: func(tt *testTeam) {
+				tt.db.on("FROM tiktok_sprint_runs WHERE boardid=? AND phase<>?", runCols, []driver.Value{"Example-03-01-2019", `{"phase":"report","rolloverId":7}`})
+				tt.db.on("FROM tiktok_sprint_rollovers", rolloverCols, rolloverRow(7))
+			},
+			wantMsg: "Done Executing Sprint Setup",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a5",
+				nextCard: "5c8a0e1f2b3c4d5e6f7000a5",
+			},
+			wantDB: []string{"INSERT tiktok_sprint_journal SET rollover=?,cardid=?,action=?,state=? [7 ", "INSERT tiktok_main", "Example-03-01-2019 "},
+			noDB:   []string{"CREATE TABLE", "INSERT tiktok_sprint_rollovers", "INSERT tiktok_sprint_reports"},
+			retro:  true,
+		},
+		{
+			name: "checkpoint without a journal starts one before moving cards",
+			setup: func(tt *testTeam) {
+				tt.db.on("FROM tiktok_sprint_runs WHERE boardid=? AND phase<>?", runCols, []driver.Value{"Example-03-01-2019", `{"phase":"report"}`})
+			},
+			wantMsg: "Done Executing Sprint Setup",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a5",
+			},
+			wantDB: []string{"INSERT tiktok_sprint_rollovers", "INSERT tiktok_sprint_journal"},
+			noDB:   []string{"INSERT tiktok_sprint_reports"},
+			retro:  true,
+		},
+		{
+			name: "journal failure leaves cards alone",
+			setup: func(tt *testTeam) {
+				tt.db.fail("INSERT tiktok_sprint_journal", errors.New("journal is down"))
+			},
+			wantErr: true,
+			wantMsg: "stopped in the *close* phase",
+			wantList: map[string]string{
+				rollCard: "5c8a0e1f2b3c4d5e6f7000a6",
+				nextCard: "5c8a0e1f2b3c4d5e6f7000a4",
+			},
+			noDB: []string{"INSERT tiktok_main"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+			tt.db.on("FROM tiktok_users", userCols, userRow("Example Person", "U0001", "exampleperson"))
+			if tc.setup != nil {
+				tc.setup(tt)
+			}
+
+			msg, err := Sprint(tt.opts, tt.tiktok, tt.trello, tc.retroNo)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Sprint() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !strings.Contains(msg, tc.wantMsg) {
+				t.Errorf("Sprint() message = %q, want it to contain %q", msg, tc.wantMsg)
+			}
+
+			for cardID, listID := range tc.wantList {
+				if got := tt.card(t, cardID).IDList; got != listID {
+					t.Errorf("card %s is in list %s, want %s", cardID, got, listID)
+				}
+			}
+			for _, q := range tc.wantDB {
+				if len(tt.db.ran(q)) == 0 {
+					t.Errorf("expected a DB statement containing %q", q)
+				}
+			}
+			for _, q := range tc.noDB {
+				if len(tt.db.ran(q)) != 0 {
+					t.Errorf("unexpected DB statement containing %q", q)
+				}
+			}
+
+			if got := len(tt.calls("CreateBoard")) == 1; got != tc.retro {
+				t.Errorf("retro board created = %v, want %v", got, tc.retro)
+			}
+			if tc.retro && len(tt.calls("CreateList")) != 7 {
+				t.Errorf("retro board has %d lists, want 7", len(tt.calls("CreateList")))
+			}
+
+			sent := strings.Join(tt.slack.sent("#sprint"), "\n")
+			for _, s := range tc.slack {
+				if !strings.Contains(sent, s) {
+					t.Errorf("#sprint messages %q don't contain %q", sent, s)
+				}
+			}
+		})
+	}
+}
+
+var runCols = []string{"sprintname", "state"}
+
+var rolloverCols = []string{"id", "boardid", "team", "started", "oldsprint", "newsprint", "retroid", "undone"}
+
+// rolloverRow - a tiktok_sprint_rollovers row for GetRollover, started an hour ago and not undone
+func rolloverRow(id int64) []driver.Value {
+	return []driver.Value{id, "5c8a0e1f2b3c4d5e6f708091", "example", time.Now().Add(-time.Hour), "Example-01-01-2019", "", "", false}
+}
+
+// flakyTrello - FakeTrello with CreateList failing, for the retro phase
+type flakyTrello struct {
+	*FakeTrello
+	createListErr error
+}
+
+func (f *flakyTrello) CreateList(boardID string, listName string) error {
+	if f.createListErr != nil {
+		return f.createListErr
+	}
+	return f.FakeTrello.CreateList(boardID, listName)
+}
+
+// a rerun picks up the board's unfinished rollover and only redoes what it didn't finish
+func TestSprintResume(t *testing.T) {
+	const retroBoard = "5c8a0e1f2b3c4d5e6f709001"
+
+	tests := []struct {
+		name          string
+		state         string
+		journalled    bool
+		highPoints    bool
+		createListErr error
+		usersErr      error
+		wantErr       bool
+		wantMsg       string
+		wantList      map[string]string
+		noComment     bool
+		wantBoards    int
+		wantLists     int
+	}{
+		{
+			name:       "held back card isn't renamed or alerted on again",
+			state:      `{"phase":"close","rolloverId":7}`,
+			journalled: true,
+			highPoints: true,
+			wantMsg:    "Done Executing Sprint Setup",
+			wantList:   map[string]string{nextCard: "5c8a0e1f2b3c4d5e6f7000a4"},
+			noComment:  true,
+			wantBoards: 1,
+			wantLists:  7,
+		},
+		{
+			// the earlier run journalled the card and stopped before moving it
+			name:       "started card that wasn't moved is moved",
+			state:      `{"phase":"close","rolloverId":7}`,
+			journalled: true,
+			wantMsg:    "Done Executing Sprint Setup",
+			wantList:   map[string]string{nextCard: "5c8a0e1f2b3c4d5e6f7000a5"},
+			noComment:  true,
+			wantBoards: 1,
+			wantLists:  7,
+		},
+		{
+			name:      "retro board is reused and only missing lists are made",
+			state:     `{"phase":"start","rolloverId":7,"retroId":"` + retroBoard + `"}`,
+			wantMsg:   "Done Executing Sprint Setup",
+			wantLists: 5,
+		},
+		{
+			name:          "retro list failure stops the rollover",
+			state:         `{"phase":"start","rolloverId":7}`,
+			createListErr: errors.New("trello is down"),
+			wantErr:       true,
+			wantMsg:       "stopped in the *retro* phase",
+			wantBoards:    1,
+		},
+		{
+			name:       "retro user lookup failure stops the rollover",
+			state:      `{"phase":"start","rolloverId":7}`,
+			usersErr:   errors.New("db is down"),
+			wantErr:    true,
+			wantMsg:    "stopped in the *retro* phase",
+			wantBoards: 1,
+			wantLists:  7,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+			tt.db.on("FROM tiktok_users", userCols, userRow("Example Person", "U0001", "exampleperson"))
+			tt.db.on("FROM tiktok_sprint_runs WHERE boardid=? AND phase<>?", runCols, []driver.Value{"Example-03-01-2019", tc.state})
+			tt.db.on("FROM tiktok_sprint_rollovers", rolloverCols, rolloverRow(7))
+			if tc.journalled {
+				tt.db.on("FROM tiktok_sprint_journal", []string{"id", "cardid", "action", "state"}, []driver.Value{int64(1), nextCard, "start sprint", "{}"})
+			}
+			if tc.usersErr != nil {
+				tt.db.fail("FROM tiktok_users", tc.usersErr)
+			}
+			if tc.highPoints {
+				tt.trello.Fixture.PluginData[nextCard][0].Value = `{"points":21}`
+			}
+
+			var retro BoardData
+			retro.ID = retroBoard
+			tt.trello.Fixture.Boards = append(tt.trello.Fixture.Boards, retro)
+			// an earlier run got as far as the first two lists
+			for i, name := range []string{"Completed", "Action Items"} {
+				l := make(ListData, 1)
+				l[0].ID = retroBoard[:22] + "0" + strconv.Itoa(i)
+				l[0].Name = name
+				l[0].IDBoard = retroBoard
+				tt.trello.Fixture.Lists = append(tt.trello.Fixture.Lists, l...)
+			}
+
+			msg, err := Sprint(tt.opts, tt.tiktok, &flakyTrello{tt.trello, tc.createListErr}, false)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Sprint() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !strings.Contains(msg, tc.wantMsg) {
+				t.Errorf("Sprint() message = %q, want it to contain %q", msg, tc.wantMsg)
+			}
+
+			for cardID, listID := range tc.wantList {
+				if got := tt.card(t, cardID).IDList; got != listID {
+					t.Errorf("card %s is in list %s, want %s", cardID, got, listID)
+				}
+			}
+			if tc.noComment && len(tt.calls("CommentCard "+nextCard)) != 0 {
+				t.Errorf("card %s was commented on again: %q", nextCard, tt.calls("CommentCard "+nextCard))
+			}
+			if tc.journalled && strings.Contains(strings.Join(tt.slack.sent("#sprint"), "\n"), "High Point Card Found") {
+				t.Errorf("card %s was alerted on again", nextCard)
+			}
+			if got := len(tt.calls("CreateBoard")); got != tc.wantBoards {
+				t.Errorf("created %d retro boards, want %d", got, tc.wantBoards)
+			}
+			if got := len(tt.calls("CreateList")); got != tc.wantLists {
+				t.Errorf("created %d retro lists, want %d", got, tc.wantLists)
+			}
+			if len(tt.db.ran("Example-03-01-2019")) == 0 {
+				t.Errorf("checkpoint wasn't written under the unfinished run's sprint name")
+			}
+		})
+	}
+}
+
+// the closing report has to be written after the rollover started, UndoRollover only deletes reports closed since then
+func TestSprintJournalsBeforeReport(t *testing.T) {
+	tt := newTestTeam(t)
+	tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+
+	if _, err := Sprint(tt.opts, tt.tiktok, tt.trello, true); err != nil {
+		t.Fatal(err)
+	}
+
+	rollover, report := -1, -1
+	for i, l := range tt.db.log {
+		if strings.HasPrefix(l, "INSERT tiktok_sprint_rollovers") {
+			rollover = i
+		}
+		if strings.HasPrefix(l, "INSERT tiktok_sprint_reports") {
+			report = i
+		}
+	}
+	if rollover == -1 || report == -1 || report < rollover {
+		t.Errorf("rollover recorded at statement %d, closing report at %d, want the rollover first", rollover, report)
+	}
+}
+
+func TestSprintRollsOverCardFields(t *testing.T) {
+	tt := newTestTeam(t)
+	tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+
+	if _, err := Sprint(tt.opts, tt.tiktok, tt.trello, true); err != nil {
+		t.Fatal(err)
+	}
+
+	c := tt.card(t, rollCard)
+	for _, l := range c.Labels {
+		if l.ID == tt.opts.General.ROLabelID {
+			t.Errorf("ROLL-OVER label is still on the card")
+		}
+	}
+	if len(c.IDMembers) != 0 {
+		t.Errorf("card still has members %v", c.IDMembers)
+	}
+	_, sprintName := fieldValue(c.CustomFieldItems, tt.opts.General.CfsprintID)
+	if sprintName != NewSprintName(tt.opts, time.Now().UTC()) {
+		t.Errorf("sprint field = %q, want the new sprint name", sprintName)
+	}
+}
+
+// the dry run has to add up to what the rollover announces, cards held back in Next Sprint aren't in the sprint
+func TestPlanSprintMatchesRollover(t *testing.T) {
+	setup := func(t *testing.T) *testTeam {
+		tt := newTestTeam(t)
+		tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+		tt.trello.Fixture.PluginData[nextCard][0].Value = `{"points":21}`
+		return tt
+	}
+
+	dry := setup(t)
+	plan, err := PlanSprint(dry.opts, dry.tiktok, dry.trello, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.BlockedPoints != 21 || len(plan.Blocked) != 1 {
+		t.Errorf("blocked %d points on %d cards, want 21 points on 1 card", plan.BlockedPoints, len(plan.Blocked))
+	}
+	if plan.PlannedPoints() != plan.TotalPoints+21 {
+		t.Errorf("planned points = %d, want the sprint total plus the blocked points", plan.PlannedPoints())
+	}
+
+	tt := setup(t)
+	if _, err := Sprint(tt.opts, tt.tiktok, tt.trello, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "Total points added for this Sprint: " + strconv.Itoa(plan.TotalPoints) + "\n"
+	if sent := strings.Join(tt.slack.sent("#sprint"), "\n"); !strings.Contains(sent, want) {
+		t.Errorf("dry run total %d doesn't match the rollover announcement %q", plan.TotalPoints, sent)
+	}
+	if !strings.Contains(plan.Summary(), "Points blocked in Next Sprint: 21") {
+		t.Errorf("summary doesn't show the blocked points:\n%s", plan.Summary())
+	}
+}
+
+// on rollover day the outgoing sprint's last burndown is recorded after the new sprint's start date, it mustn't be taken
+// for either sprint's committed points
+func TestSprintCommittedPoints(t *testing.T) {
+	tt := newTestTeam(t)
+	outgoing := sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019")
+	outgoing[7] = int64(13)
+	tt.db.on("FROM tiktok_main", sprintCols, outgoing)
+	tt.db.on("FROM tiktok_burndown", []string{"totalpoints"}, []driver.Value{int64(40)})
+
+	if _, err := Sprint(tt.opts, tt.tiktok, tt.trello, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent := strings.Join(tt.slack.sent("#sprint"), "\n"); !strings.Contains(sent, "*Committed:* 13 points") {
+		t.Errorf("closing report doesn't use the outgoing sprint's committed points: %q", sent)
+	}
+	if got := tt.db.ran("INSERT tiktok_main"); len(got) != 1 || !strings.HasSuffix(got[0], " 8]") {
+		t.Errorf("new sprint stored as %q, want 8 committed points", got)
+	}
+	// the outgoing sprint's last burndown, then the new sprint's first
+	if got := tt.db.ran("INSERT tiktok_burndown"); len(got) != 2 || !strings.HasSuffix(got[1], " 8]") {
+		t.Errorf("burndowns stored as %q, want the new sprint's ideal line to start from 8 points", got)
+	}
+}
+
+func TestGetCommittedPoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed sql.NullInt64
+		want      int
+	}{
+		{name: "kept at rollover", committed: sql.NullInt64{Int64: 8, Valid: true}, want: 8},
+		{name: "nothing moved in at rollover", committed: sql.NullInt64{Valid: true}, want: 0},
+		{name: "sprint from before it was kept", want: 40},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTestTeam(t)
+			tt.db.on("FROM tiktok_burndown", []string{"totalpoints"}, []driver.Value{int64(40)})
+
+			got, err := tt.tiktok.DB.GetCommittedPoints(SprintData{TeamID: "example", SprintStart: time.Now(), CommittedPts: tc.committed})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("GetCommittedPoints() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tiktokmod
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// SprintRunState - how far a sprint rollover has got, checkpointed to tiktok_sprint_runs after every phase
+type SprintRunState struct {
+	Phase       string         `json:"phase"`
+	RolloverID  int64          `json:"rolloverId"`
+	RetroID     string         `json:"retroId"`
+	Rolled      int            `json:"rolled"`
+	Backlogged  int            `json:"backlogged"`
+	Started     int            `json:"started"`
+	TotalPoints int            `json:"totalPoints"`
+	SquadPoints map[string]int `json:"squadPoints"`
+}
+
+// GetSprintRun - checkpointed state of a board's rollover to a new sprint, empty if it hasn't been started
+func (r *Repo) GetSprintRun(boardID string, sprintName string) (state SprintRunState, err error) {
+	var raw string
+
+	if err := r.ready(); err != nil {
+		return state, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT state FROM tiktok_sprint_runs WHERE boardid=? AND sprintname=?", boardID, sprintName).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return state, nil
+	case err != nil:
+		errTrap(r.tiktok, "DB Query Error in `GetSprintRun` in `sprintcheckpoint.go`", err)
+		return state, err
+	}
+
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		errTrap(r.tiktok, "Bad checkpoint for sprint "+sprintName+" in `GetSprintRun` in `sprintcheckpoint.go`", err)
+		return state, err
+	}
+
+	return state, nil
+}
+
+// GetUnfinishedSprintRun - the newest rollover on a board that stopped before finishing, and the sprint name it was
+// started under.  found is false if every rollover on the board finished
+func (r *Repo) GetUnfinishedSprintRun(boardID string, lastPhase string) (sprintName string, state SprintRunState, found bool, err error) {
+	var raw string
+
+	if err := r.ready(); err != nil {
+		return sprintName, state, false, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT sprintname,state FROM tiktok_sprint_runs WHERE boardid=? AND phase<>? ORDER BY updated DESC LIMIT 1", boardID, lastPhase).Scan(&sprintName, &raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return sprintName, state, false, nil
+	case err != nil:
+		errTrap(r.tiktok, "DB Query Error in `GetUnfinishedSprintRun` in `sprintcheckpoint.go`", err)
+		return sprintName, state, false, err
+	}
+
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		errTrap(r.tiktok, "Bad checkpoint for sprint "+sprintName+" in `GetUnfinishedSprintRun` in `sprintcheckpoint.go`", err)
+		return sprintName, state, false, err
+	}
+
+	return sprintName, state, true, nil
+}
+
+// PutSprintRun - checkpoint a board's rollover to a new sprint
+func (r *Repo) PutSprintRun(boardID string, sprintName string, state SprintRunState) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO tiktok_sprint_runs (boardid,sprintname,phase,state,updated) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE phase=VALUES(phase),state=VALUES(state),updated=VALUES(updated)", boardID, sprintName, state.Phase, string(raw), time.Now().Local())
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `PutSprintRun` in `sprintcheckpoint.go`", err)
+	}
+
+	return err
+}
@@ -0,0 +1,449 @@
+package tiktokmod
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// CardState - a card's list, position, labels, members and custom field values, journalled before `Sprint` changes it
+type CardState struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	IDList  string            `json:"idList"`
+	Pos     int               `json:"pos"`
+	Labels  []string          `json:"labels"`
+	Members []string          `json:"members"`
+	Fields  []CustomFieldItem `json:"fields"`
+}
+
+// Rollover - one run of `Sprint` on a board, from tiktok_sprint_rollovers
+type Rollover struct {
+	ID        int64
+	BoardID   string
+	Team      string
+	Started   time.Time
+	OldSprint string
+	NewSprint string
+	RetroID   string
+}
+
+// JournalEntry - one card change in a rollover, with the card as it was just before
+type JournalEntry struct {
+	ID     int64
+	CardID string
+	Action string
+	State  CardState
+}
+
+// SprintJournal - writes the undo journal of a sprint rollover as it happens
+type SprintJournal struct {
+	tiktok   *TikTokConf
+	rollover Rollover
+}
+
+// cardState - snapshot of the parts of a card `Sprint` changes
+func cardState(card BoardCard) CardState {
+	state := CardState{ID: card.ID, Name: card.Name, IDList: card.IDList, Pos: card.Pos, Members: card.IDMembers, Fields: card.CustomFieldItems}
+	for _, l := range card.Labels {
+		state.Labels = append(state.Labels, l.ID)
+	}
+	return state
+}
+
+// StartSprintJournal - open the journal for a rollover of the board, nothing on trello should change until this works
+func StartSprintJournal(tiktok *TikTokConf, opts Config, sOpts SprintData, newSprintName string) (*SprintJournal, error) {
+	j := &SprintJournal{tiktok: tiktok}
+	j.rollover = Rollover{BoardID: opts.General.BoardID, Team: sOpts.TeamID, Started: time.Now().Local(), OldSprint: sOpts.SprintName, NewSprint: newSprintName}
+
+	id, err := tiktok.DB.StartRollover(j.rollover)
+	if err != nil {
+		return nil, err
+	}
+	j.rollover.ID = id
+
+	return j, nil
+}
+
+// ResumeSprintJournal - reopen the journal of a rollover that's being resumed
+func ResumeSprintJournal(tiktok *TikTokConf, rolloverID int64) (*SprintJournal, error) {
+	ro, found, err := tiktok.DB.GetRollover(rolloverID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("sprint rollover " + strconv.FormatInt(rolloverID, 10) + " has been undone or doesn't exist")
+	}
+
+	return &SprintJournal{tiktok: tiktok, rollover: ro}, nil
+}
+
+// Card - journal a card before it's changed.  Don't touch the card if this fails, it couldn't be undone
+func (j *SprintJournal) Card(card BoardCard, action string) error {
+	state, err := json.Marshal(cardState(card))
+	if err == nil {
+		err = j.tiktok.DB.RecordJournal(j.rollover.ID, card.ID, action, string(state))
+	}
+	if err != nil {
+		errTrap(j.tiktok, "Unable to journal card `"+card.Name+"` before `"+action+"`, leaving it alone. In `Card` in `sprintjournal.go`", err)
+	}
+	return err
+}
+
+// Journalled - IDs of the cards already journalled for action, by this run or an earlier one of the same rollover
+func (j *SprintJournal) Journalled(action string) (map[string]bool, error) {
+	cards := make(map[string]bool)
+
+	entries, err := j.tiktok.DB.GetJournal(j.rollover.ID)
+	if err != nil {
+		return cards, err
+	}
+	for _, e := range entries {
+		if e.Action == action {
+			cards[e.CardID] = true
+		}
+	}
+
+	return cards, nil
+}
+
+// Retro - journal the retro board created for the new sprint
+func (j *SprintJournal) Retro(boardID string) {
+	j.rollover.RetroID = boardID
+	if err := j.tiktok.DB.UpdateRollover(j.rollover); err != nil {
+		errTrap(j.tiktok, "Unable to journal retro board "+boardID+" in `Retro` in `sprintjournal.go`", err)
+	}
+}
+
+// fieldValue - type and value of a custom field on a card, blank if it isn't set
+func fieldValue(items []CustomFieldItem, fieldID string) (valueType string, value string) {
+	for _, i := range items {
+		if i.IDCustomField == fieldID {
+			if i.Value.Number != "" {
+				return "number", i.Value.Number
+			}
+			return "text", i.Value.Text
+		}
+	}
+	return "", ""
+}
+
+// restoreCard - put a card back the way it was journalled.  Only what's different is changed so it's safe to run again
+func restoreCard(trello TrelloAPI, opts Config, state CardState) error {
+	card, err := trello.GetCard(state.ID)
+	if err != nil {
+		return err
+	}
+
+	if card.IDList != state.IDList {
+		if err := trello.MoveCardList(card.ID, state.IDList); err != nil {
+			return err
+		}
+	}
+	if card.IDList != state.IDList || card.Pos != state.Pos {
+		if err := trello.ReOrderCardInList(card.ID, strconv.Itoa(state.Pos)); err != nil {
+			return err
+		}
+	}
+
+	have := make(map[string]bool)
+	want := make(map[string]bool)
+	for _, l := range card.Labels {
+		have[l.ID] = true
+	}
+	for _, l := range state.Labels {
+		want[l] = true
+		if !have[l] {
+			if err := trello.AddLabel(card.ID, l); err != nil {
+				return err
+			}
+		}
+	}
+	for l := range have {
+		if !want[l] {
+			if err := trello.RemoveLabel(card.ID, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	have = make(map[string]bool)
+	want = make(map[string]bool)
+	for _, m := range card.IDMembers {
+		have[m] = true
+	}
+	for _, m := range state.Members {
+		want[m] = true
+		if !have[m] {
+			if err := trello.AddHead(card.ID, m); err != nil {
+				return err
+			}
+		}
+	}
+	for m := range have {
+		if !want[m] {
+			if err := trello.RemoveHead(card.ID, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	fields := []string{opts.General.CfsprintID, opts.General.CfpointsID}
+	for _, f := range state.Fields {
+		fields = append(fields, f.IDCustomField)
+	}
+	done := make(map[string]bool)
+	for _, f := range fields {
+		if done[f] {
+			continue
+		}
+		done[f] = true
+
+		valueType, value := fieldValue(state.Fields, f)
+		_, current := fieldValue(card.CustomFieldItems, f)
+		if value == current {
+			continue
+		}
+		// a blank is cleared the same way `Sprint` clears the sprint field on cards going back to the backlog
+		if value == "" {
+			valueType, value = "number", " "
+		}
+		if err := trello.PutCustomField(card.ID, f, valueType, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UndoLastSprint - undo the last sprint rollover on a board from its journal.  Cards are put back newest change first
+// using what they looked like before the rollover first touched them, the retro board is archived and the new sprint is
+// taken back out of the DB.  If any card fails the rollover stays on the books so it can be run again
+func UndoLastSprint(tiktok *TikTokConf, trello TrelloAPI, opts Config) (message string, err error) {
+	r, found, err := tiktok.DB.GetLastRollover(opts.General.BoardID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", errors.New("there's no sprint rollover to undo on the " + opts.General.TeamName + " board")
+	}
+
+	entries, err := tiktok.DB.GetJournal(r.ID)
+	if err != nil {
+		return "", err
+	}
+
+	first := make(map[string]int)
+	for i := len(entries) - 1; i >= 0; i-- {
+		first[entries[i].CardID] = i
+	}
+
+	restored := 0
+	failed := ""
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if first[e.CardID] != i {
+			continue
+		}
+		if err := restoreCard(trello, opts, e.State); err != nil {
+			errTrap(tiktok, "Unable to restore card `"+e.State.Name+"` in `UndoLastSprint` in `sprintjournal.go`", err)
+			failed = failed + e.State.Name + " (" + e.CardID + ")\n"
+			continue
+		}
+		restored++
+	}
+
+	message = "Put " + strconv.Itoa(restored) + " cards back the way they were before sprint " + r.OldSprint + " was closed.\n"
+
+	if failed != "" {
+		message = message + "I couldn't restore these cards, run `undo last sprint` again to retry:\n" + failed
+		return message, errors.New("some cards could not be restored")
+	}
+
+	if r.RetroID != "" {
+		if err := trello.CloseBoard(r.RetroID); err != nil {
+			message = message + "I couldn't archive the retro board https://trello.com/b/" + r.RetroID + " please do it by hand.\n"
+		} else {
+			message = message + "Archived the retro board for sprint " + r.NewSprint + ".\n"
+		}
+	}
+
+	if err := tiktok.DB.UndoRollover(r); err != nil {
+		message = message + "I couldn't take sprint " + r.NewSprint + " out of the DB, check the logs.\n"
+		return message, err
+	}
+	if r.NewSprint != "" {
+		message = message + "Sprint " + r.OldSprint + " is the current sprint again.\n"
+	}
+
+	return message, nil
+}
+
+// StartRollover - record that a sprint rollover has started, returns its ID for the journal
+func (r *Repo) StartRollover(ro Rollover) (id int64, err error) {
+	if err := r.ready(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "INSERT tiktok_sprint_rollovers SET boardid=?,team=?,started=?,oldsprint=?,newsprint=?", ro.BoardID, ro.Team, ro.Started, ro.OldSprint, ro.NewSprint)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `StartRollover` in `sprintjournal.go`", err)
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// UpdateRollover - record the retro board of a rollover
+func (r *Repo) UpdateRollover(ro Rollover) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE tiktok_sprint_rollovers SET retroid=? WHERE id=?", ro.RetroID, ro.ID)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `UpdateRollover` in `sprintjournal.go`", err)
+	}
+
+	return err
+}
+
+// RecordJournal - add a card change to a rollover's journal
+func (r *Repo) RecordJournal(rollover int64, cardID string, action string, state string) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_sprint_journal SET rollover=?,cardid=?,action=?,state=?", rollover, cardID, action, state)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `RecordJournal` in `sprintjournal.go`", err)
+	}
+
+	return err
+}
+
+// GetLastRollover - the newest rollover on a board that hasn't been undone.  Only the last one can be undone, so an
+// older one is never returned once a newer one exists
+func (r *Repo) GetLastRollover(boardID string) (ro Rollover, found bool, err error) {
+	return r.queryRollover("GetLastRollover", "WHERE boardid=? ORDER BY started DESC, id DESC LIMIT 1", boardID)
+}
+
+// GetRollover - a rollover by ID, not found once it's been undone
+func (r *Repo) GetRollover(id int64) (ro Rollover, found bool, err error) {
+	return r.queryRollover("GetRollover", "WHERE id=?", id)
+}
+
+// queryRollover - one row of tiktok_sprint_rollovers, found is false if there isn't one or it's been undone
+func (r *Repo) queryRollover(caller string, where string, args ...interface{}) (ro Rollover, found bool, err error) {
+	var undone bool
+
+	if err := r.ready(); err != nil {
+		return ro, false, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT id,boardid,team,started,oldsprint,newsprint,retroid,undone IS NOT NULL FROM tiktok_sprint_rollovers "+where, args...).Scan(
+		&ro.ID,
+		&ro.BoardID,
+		&ro.Team,
+		&ro.Started,
+		&ro.OldSprint,
+		&ro.NewSprint,
+		&ro.RetroID,
+		&undone)
+	switch {
+	case err == sql.ErrNoRows:
+		return ro, false, nil
+	case err != nil:
+		errTrap(r.tiktok, "DB Query Error in `"+caller+"` in `sprintjournal.go`", err)
+		return ro, false, err
+	}
+
+	return ro, !undone, nil
+}
+
+// GetJournal - every card change in a rollover, oldest first
+func (r *Repo) GetJournal(rollover int64) (entries []JournalEntry, err error) {
+	if err := r.ready(); err != nil {
+		return entries, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id,cardid,action,state FROM tiktok_sprint_journal WHERE rollover=? ORDER BY id", rollover)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetJournal` in `sprintjournal.go`", err)
+		return entries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e JournalEntry
+		var state string
+		if err := rows.Scan(&e.ID, &e.CardID, &e.Action, &state); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetJournal` in `sprintjournal.go`", err)
+			return entries, err
+		}
+		if err := json.Unmarshal([]byte(state), &e.State); err != nil {
+			errTrap(r.tiktok, "Bad journal entry "+strconv.FormatInt(e.ID, 10)+" in `GetJournal` in `sprintjournal.go`", err)
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// UndoRollover - take a rollover's new sprint, closing report, squad points, checkpoints and burndowns back out of the DB
+// and mark it undone, all in one transaction
+func (r *Repo) UndoRollover(ro Rollover) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, q := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{"DELETE FROM tiktok_main WHERE teamid=? AND sprintname=? AND sprintstart>=DATE(?)", []interface{}{ro.Team, ro.NewSprint, ro.Started}},
+		{"DELETE FROM tiktok_sprint_reports WHERE team=? AND sprintname=? AND closed>=?", []interface{}{ro.Team, ro.OldSprint, ro.Started}},
+		{"DELETE FROM tiktok_sprint_squad_points WHERE sprintname=?", []interface{}{ro.OldSprint}},
+		{"DELETE FROM tiktok_sprint_runs WHERE boardid=? AND sprintname=?", []interface{}{ro.BoardID, ro.NewSprint}},
+		// the new sprint's burndowns would otherwise still count towards committed points and velocity
+		{"DELETE FROM tiktok_burndown WHERE team=? AND pointdate>=?", []interface{}{ro.Team, ro.Started}},
+		{"UPDATE tiktok_sprint_rollovers SET undone=? WHERE id=?", []interface{}{time.Now().Local(), ro.ID}},
+	} {
+		if _, err = tx.ExecContext(ctx, q.query, q.args...); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		errTrap(r.tiktok, "SQL Error in `UndoRollover` in `sprintjournal.go`", err)
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,155 @@
+package tiktokmod
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingLabels - FakeTrello that can't add labels back, for an undo that only gets part way
+type failingLabels struct {
+	*FakeTrello
+	err error
+}
+
+func (f *failingLabels) AddLabel(cardID string, labelID string) error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.FakeTrello.AddLabel(cardID, labelID)
+}
+
+// rolledOver - roll the test team's board over, then hand the rollover and its journal back to the fake DB the way
+// UndoLastSprint will read them.  Returns every card as it was before the rollover
+func rolledOver(t *testing.T, tt *testTeam) map[string]CardState {
+	t.Helper()
+
+	tt.db.on("FROM tiktok_main", sprintCols, sprintRow("example", time.Now().AddDate(0, 0, -14), "Example-01-01-2019"))
+	tt.db.on("FROM tiktok_users", userCols, userRow("Example Person", "U0001", "exampleperson"))
+
+	before := make(map[string]CardState)
+	for _, b := range tt.trello.Fixture.Boards {
+		for _, c := range b.Cards {
+			before[c.ID] = cardState(c)
+		}
+	}
+
+	started := time.Now().Add(-time.Second)
+	if _, err := Sprint(tt.opts, tt.tiktok, tt.trello, false); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := tt.db.written("INSERT tiktok_sprint_journal")
+	if len(journal) == 0 {
+		t.Fatal("the rollover journalled nothing")
+	}
+	var rows [][]driver.Value
+	for i, j := range journal {
+		rows = append(rows, []driver.Value{int64(i + 1), j[1], j[2], j[3]})
+	}
+	tt.db.on("FROM tiktok_sprint_journal", []string{"id", "cardid", "action", "state"}, rows...)
+
+	retro := ""
+	for _, b := range tt.trello.Fixture.Boards {
+		if strings.HasPrefix(b.Name, "Retro: ") {
+			retro = b.ID
+		}
+	}
+	ro := rolloverRow(journal[0][0].(int64))
+	ro[3] = started
+	ro[5] = NewSprintName(tt.opts, time.Now().UTC())
+	ro[6] = retro
+	tt.db.on("FROM tiktok_sprint_rollovers", rolloverCols, ro)
+
+	return before
+}
+
+// sameCard - what UndoLastSprint promises to put back, compared regardless of order and custom field item IDs
+func sameCard(t *testing.T, opts Config, got BoardCard, want CardState) {
+	t.Helper()
+
+	have := cardState(got)
+	if have.IDList != want.IDList || have.Pos != want.Pos {
+		t.Errorf("card %s is at %s/%d, want %s/%d", want.ID, have.IDList, have.Pos, want.IDList, want.Pos)
+	}
+	for _, s := range [][]string{have.Labels, want.Labels, have.Members, want.Members} {
+		sort.Strings(s)
+	}
+	if len(have.Labels)+len(want.Labels) > 0 && !reflect.DeepEqual(have.Labels, want.Labels) {
+		t.Errorf("card %s has labels %v, want %v", want.ID, have.Labels, want.Labels)
+	}
+	if len(have.Members)+len(want.Members) > 0 && !reflect.DeepEqual(have.Members, want.Members) {
+		t.Errorf("card %s has members %v, want %v", want.ID, have.Members, want.Members)
+	}
+	for _, f := range []string{opts.General.CfsprintID, opts.General.CfpointsID} {
+		_, gotValue := fieldValue(have.Fields, f)
+		_, wantValue := fieldValue(want.Fields, f)
+		if gotValue != wantValue {
+			t.Errorf("card %s field %s = %q, want %q", want.ID, f, gotValue, wantValue)
+		}
+	}
+}
+
+func TestUndoLastSprint(t *testing.T) {
+	tt := newTestTeam(t)
+	before := rolledOver(t, tt)
+
+	// make sure the rollover changed what the undo has to put back
+	if tt.card(t, rollCard).IDList == before[rollCard].IDList || len(tt.card(t, rollCard).IDMembers) != 0 {
+		t.Fatalf("roll over card wasn't moved and unassigned: %+v", tt.card(t, rollCard))
+	}
+
+	msg, err := UndoLastSprint(tt.tiktok, tt.trello, tt.opts)
+	if err != nil {
+		t.Fatalf("UndoLastSprint() error = %v: %s", err, msg)
+	}
+
+	for id, want := range before {
+		sameCard(t, tt.opts, tt.card(t, id), want)
+	}
+	if len(tt.calls("CloseBoard")) != 1 {
+		t.Errorf("retro board wasn't archived")
+	}
+	for _, q := range []string{"DELETE FROM tiktok_main", "DELETE FROM tiktok_sprint_reports", "DELETE FROM tiktok_burndown", "UPDATE tiktok_sprint_rollovers SET undone"} {
+		if len(tt.db.ran(q)) != 1 {
+			t.Errorf("expected one DB statement containing %q", q)
+		}
+	}
+	if !strings.Contains(msg, "Sprint Example-01-01-2019 is the current sprint again") {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+func TestUndoLastSprintRerun(t *testing.T) {
+	tt := newTestTeam(t)
+	before := rolledOver(t, tt)
+
+	// the ROLL-OVER label can't be put back, so that card stays half done and the rollover stays on the books
+	flaky := &failingLabels{FakeTrello: tt.trello, err: errors.New("trello is down")}
+	msg, err := UndoLastSprint(tt.tiktok, flaky, tt.opts)
+	if err == nil {
+		t.Fatalf("UndoLastSprint() succeeded with trello failing: %s", msg)
+	}
+	if !strings.Contains(msg, "Roll me over ("+rollCard+")") {
+		t.Errorf("message %q doesn't name the card that wasn't restored", msg)
+	}
+	if len(tt.db.ran("UPDATE tiktok_sprint_rollovers SET undone")) != 0 || len(tt.calls("CloseBoard")) != 0 {
+		t.Errorf("rollover was taken off the books with a card still to restore")
+	}
+
+	flaky.err = nil
+	msg, err = UndoLastSprint(tt.tiktok, flaky, tt.opts)
+	if err != nil {
+		t.Fatalf("rerun of UndoLastSprint() error = %v: %s", err, msg)
+	}
+	for id, want := range before {
+		sameCard(t, tt.opts, tt.card(t, id), want)
+	}
+	if len(tt.db.ran("UPDATE tiktok_sprint_rollovers SET undone")) != 1 {
+		t.Errorf("rollover wasn't marked undone on the rerun")
+	}
+}
@@ -0,0 +1,243 @@
+package tiktokmod
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlanCard - a card and what a sprint run would do with it
+type PlanCard struct {
+	ID       string
+	Name     string
+	ShortURL string
+	Points   int
+	Reason   string
+}
+
+// SprintPlan - everything Sprint() would do to a board, worked out without changing anything
+type SprintPlan struct {
+	TeamName      string
+	NewSprintName string
+	RetroBoard    string
+	RollOver      []PlanCard
+	Backlog       []PlanCard
+	ReadyForWork  []PlanCard
+	Blocked       []PlanCard
+	Squads        Squads
+	NonSquadPts   int
+	TotalPoints   int
+	BlockedPoints int
+	WeekendDays   int
+	WorkingDays   float64
+	Capacity      SprintCapacity
+}
+
+// NewSprintName - name of the sprint that starts on a given day, start should be in the team's time zone
+func NewSprintName(opts Config, start time.Time) string {
+	return opts.General.Sprintname + "-" + start.Format("01-02-2006")
+}
+
+// SprintWorkingDays - working days in a sprint starting on a given day off the team's business calendar.  weekendDays
+// is how many weekend days were taken out, none unless the team has IgnoreWeekends set
+func SprintWorkingDays(tiktok *TikTokConf, opts Config, start time.Time) (wDays float64, weekendDays int) {
+	var attachments Attachment
+
+	cal := TeamCalendar(tiktok, opts)
+
+	day := cal.dayStart(start)
+	for i := 0; i < opts.General.SprintDuration; i++ {
+		if cal.Weekend(day) {
+			weekendDays++
+		} else if holiday, ok := cal.Holiday(day); ok {
+			if tiktok.Config.LogToSlack {
+				if holiday.HalfDay {
+					LogToSlack("Holiday found `"+holiday.Name+"` counting it as half a work day.", tiktok, attachments)
+				} else {
+					LogToSlack("Holiday found `"+holiday.Name+"` skipping as a work day.", tiktok, attachments)
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return cal.WorkingDays(start, opts.General.SprintDuration), weekendDays
+}
+
+// SprintBlocked - why a Next Sprint card will not be moved to Ready for Work, blank if it will be
+func SprintBlocked(opts Config, card BoardCard, points int) string {
+	for _, labels := range card.Labels {
+		if labels.ID == opts.General.SilenceCardLabel {
+			return ""
+		}
+	}
+
+	// verify if we have a {SPIKE} card or not
+	weHaveSpike := strings.ToLower(Between(card.ID, "{", "}")) == "spike"
+
+	if points > opts.General.MaxPoints {
+		return "more than " + strconv.Itoa(opts.General.MaxPoints) + " points"
+	}
+	if points == 0 && !weHaveSpike {
+		return "no points"
+	}
+
+	return ""
+}
+
+// PlanSprint - work out what Sprint() would do to a board.  Only reads from trello and the DB
+func PlanSprint(opts Config, tiktok *TikTokConf, trello TrelloAPI, retroNo bool) (plan SprintPlan, err error) {
+	var nextSprint []BoardCard
+
+	plan.TeamName = opts.General.TeamName
+
+	cal := TeamCalendar(tiktok, opts)
+	rightnow := time.Now().In(cal.Location)
+	plan.NewSprintName = NewSprintName(opts, rightnow)
+	if !retroNo {
+		plan.RetroBoard = "Retro: " + plan.NewSprintName
+	}
+
+	allSquads, err := tiktok.DB.GetSquads(opts.General.BoardID)
+	if err != nil {
+		errTrap(tiktok, "Failed DB Call to get squad information in `PlanSprint` in `sprintplan.go`", err)
+		return plan, err
+	}
+
+	allTheThings, err := trello.RetrieveAll(opts.General.BoardID, "visible")
+	if err != nil {
+		errTrap(tiktok, "Trello error in RetrieveAll function `PlanSprint` in `sprintplan.go` for `"+opts.General.TeamName+"` board", err)
+		return plan, err
+	}
+
+	// current sprint cards either roll over into Next Sprint or go to the Backlog
+	for _, aTt := range allTheThings.Cards {
+		if aTt.Closed {
+			continue
+		}
+
+		if aTt.IDList == opts.General.NextsprintID {
+			nextSprint = append(nextSprint, aTt)
+			continue
+		}
+
+		if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview {
+			rollOver := false
+			for _, l := range aTt.Labels {
+				if l.ID == opts.General.ROLabelID {
+					rollOver = true
+				}
+			}
+
+			card := PlanCard{ID: aTt.ID, Name: aTt.Name, ShortURL: aTt.ShortURL}
+			if rollOver {
+				plan.RollOver = append(plan.RollOver, card)
+				nextSprint = append(nextSprint, aTt)
+			} else {
+				plan.Backlog = append(plan.Backlog, card)
+			}
+		}
+	}
+
+	// everything now in Next Sprint is pointed and either moves to Ready for Work or gets stuck.  Like the rollover only
+	// the cards that move count towards the sprint and squad totals
+	for _, aTt := range nextSprint {
+		points := CardPoints(tiktok, opts, aTt)
+
+		card := PlanCard{ID: aTt.ID, Name: aTt.Name, ShortURL: aTt.ShortURL, Points: points}
+		card.Reason = SprintBlocked(opts, aTt, points)
+		if card.Reason != "" {
+			plan.Blocked = append(plan.Blocked, card)
+			plan.BlockedPoints = plan.BlockedPoints + points
+			continue
+		}
+		plan.ReadyForWork = append(plan.ReadyForWork, card)
+		plan.TotalPoints = plan.TotalPoints + points
+
+		squadFound := false
+		for _, labels := range aTt.Labels {
+			for s, squad := range allSquads {
+				if opts.General.BoardID == squad.BoardID && squad.LabelID == labels.ID {
+					allSquads[s].SquadPts = allSquads[s].SquadPts + points
+					squadFound = true
+				}
+			}
+		}
+		if !squadFound {
+			plan.NonSquadPts = plan.NonSquadPts + points
+		}
+	}
+
+	for _, s := range allSquads {
+		if opts.General.BoardID == s.BoardID {
+			plan.Squads = append(plan.Squads, s)
+		}
+	}
+
+	plan.WorkingDays, plan.WeekendDays = SprintWorkingDays(tiktok, opts, rightnow)
+
+	// capacity is only advice, a plan without it is still a plan
+	plan.Capacity, err = TeamCapacity(tiktok, opts, cal, NextSprintStart(tiktok, opts, cal))
+	if err != nil {
+		errTrap(tiktok, "Unable to work out sprint capacity in `PlanSprint` in `sprintplan.go` for `"+opts.General.TeamName+"` board", err)
+	}
+
+	return plan, nil
+}
+
+// PlannedPoints - points of everything in Next Sprint, including the cards held back, for comparing with capacity
+func (plan SprintPlan) PlannedPoints() int {
+	return plan.TotalPoints + plan.BlockedPoints
+}
+
+// planCardList - one line per card for a sprint plan
+func planCardList(cards []PlanCard, withPoints bool) (message string) {
+	if len(cards) == 0 {
+		return "_none_\n"
+	}
+
+	for _, c := range cards {
+		message = message + "• <" + c.ShortURL + "|" + c.Name + ">"
+		if withPoints {
+			message = message + " (" + strconv.Itoa(c.Points) + " pts)"
+		}
+		if c.Reason != "" {
+			message = message + " - " + c.Reason
+		}
+		message = message + "\n"
+	}
+
+	return message
+}
+
+// Summary - slack formatted sprint plan
+func (plan SprintPlan) Summary() (message string) {
+	message = "*New sprint name:* " + plan.NewSprintName + "\n"
+	if plan.RetroBoard != "" {
+		message = message + "*Retro board:* " + plan.RetroBoard + "\n"
+	} else {
+		message = message + "*Retro board:* _suppressed_\n"
+	}
+	message = message + "*Working days:* " + strconv.FormatFloat(plan.WorkingDays, 'f', -1, 64) + " (" + strconv.Itoa(plan.WeekendDays) + " weekend days taken out)\n\n"
+
+	message = message + "*Rolling over to next sprint (" + strconv.Itoa(len(plan.RollOver)) + "):*\n" + planCardList(plan.RollOver, false) + "\n"
+	message = message + "*Moving to Backlog (" + strconv.Itoa(len(plan.Backlog)) + "):*\n" + planCardList(plan.Backlog, false) + "\n"
+	message = message + "*Moving to Ready for Work (" + strconv.Itoa(len(plan.ReadyForWork)) + "):*\n" + planCardList(plan.ReadyForWork, true) + "\n"
+	message = message + "*Blocked for points, staying in Next Sprint (" + strconv.Itoa(len(plan.Blocked)) + "):*\n" + planCardList(plan.Blocked, true) + "\n"
+
+	for _, s := range plan.Squads {
+		message = message + "Total `" + s.Squadname + "` Points: " + strconv.Itoa(s.SquadPts) + "\n"
+	}
+	message = message + "Total Points not assigned to a squad: " + strconv.Itoa(plan.NonSquadPts) + "\n"
+	message = message + "Total points for this Sprint: " + strconv.Itoa(plan.TotalPoints) + "\n"
+	message = message + "Points blocked in Next Sprint: " + strconv.Itoa(plan.BlockedPoints) + "\n"
+	if len(plan.Capacity.History) > 0 {
+		message = message + "Sprint capacity: " + strconv.Itoa(plan.Capacity.Points()) + " points"
+		if plan.PlannedPoints() > plan.Capacity.Points() {
+			message = message + " - *" + strconv.Itoa(plan.PlannedPoints()-plan.Capacity.Points()) + " points over capacity*"
+		}
+		message = message + "\n"
+	}
+
+	return message
+}
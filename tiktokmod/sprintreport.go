@@ -0,0 +1,262 @@
+package tiktokmod
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultVelocitySprints - how many earlier sprints the closing report compares velocity with when the team TOML
+// doesn't set VelocitySprints
+const DefaultVelocitySprints = 3
+
+// SprintReport - closing numbers for one sprint, kept in tiktok_sprint_reports
+type SprintReport struct {
+	Team          string
+	SprintName    string
+	Closed        time.Time
+	Committed     int
+	Completed     int
+	RolledCards   int
+	RolledPoints  int
+	BacklogCards  int
+	BacklogPoints int
+	Report        string
+}
+
+// cardSprintName - value of the card's sprint name custom field, blank if it doesn't have one
+func cardSprintName(opts Config, card BoardCard) string {
+	for _, cusval := range card.CustomFieldItems {
+		if cusval.IDCustomField == opts.General.CfsprintID {
+			return cusval.Value.Text
+		}
+	}
+	return ""
+}
+
+// percentOf - whole percent of part in total, 0 if there's no total
+func percentOf(part int, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
+
+// BuildSprintReport - tally the outgoing sprint off the board before `Sprint` moves anything.  Unfinished cards with the
+// ROLL-OVER label are carried over, the rest are headed back to the backlog.  squadDone is Done points by squad name,
+// cards without a squad label count against "Non-Squad" like `RecordSquadSprintData` does
+func BuildSprintReport(tiktok *TikTokConf, opts Config, sOpts SprintData, board BoardData, squads Squads) (report SprintReport, squadDone map[string]int) {
+	squadDone = make(map[string]int)
+
+	report.Team = sOpts.TeamID
+	report.SprintName = sOpts.SprintName
+	report.Closed = time.Now().Local()
+
+	for _, aTt := range board.Cards {
+		if aTt.Closed {
+			continue
+		}
+
+		switch aTt.IDList {
+		case opts.General.Done:
+			if cardSprintName(opts, aTt) != sOpts.SprintName {
+				continue
+			}
+			points := CardPoints(tiktok, opts, aTt)
+			report.Completed = report.Completed + points
+
+			checker := false
+			for _, labels := range aTt.Labels {
+				for _, squad := range squads {
+					if opts.General.BoardID == squad.BoardID && squad.LabelID == labels.ID {
+						squadDone[squad.Squadname] = squadDone[squad.Squadname] + points
+						checker = true
+					}
+				}
+			}
+			if !checker {
+				squadDone["Non-Squad"] = squadDone["Non-Squad"] + points
+			}
+
+		case opts.General.ReadyForWork, opts.General.Working, opts.General.ReadyForReview:
+			points := CardPoints(tiktok, opts, aTt)
+			rollover := false
+			for _, l := range aTt.Labels {
+				if l.ID == opts.General.ROLabelID {
+					rollover = true
+				}
+			}
+			if rollover {
+				report.RolledCards++
+				report.RolledPoints = report.RolledPoints + points
+			} else {
+				report.BacklogCards++
+				report.BacklogPoints = report.BacklogPoints + points
+			}
+		}
+	}
+
+	return report, squadDone
+}
+
+// SprintReportText - the closing report as posted to slack.  history is the team's earlier reports, newest first
+func SprintReportText(report SprintReport, squadTotals TotalSprint, squadDone map[string]int, history []SprintReport) (message string) {
+	if report.Committed > 0 {
+		message = "*Committed:* " + strconv.Itoa(report.Committed) + " points at sprint start\n"
+		message = message + "*Completed:* " + strconv.Itoa(report.Completed) + " points reached Done (" + strconv.Itoa(percentOf(report.Completed, report.Committed)) + "% of committed)\n"
+	} else {
+		message = "*Committed:* unknown, no burndown was recorded for this sprint\n"
+		message = message + "*Completed:* " + strconv.Itoa(report.Completed) + " points reached Done\n"
+	}
+	message = message + "*Rolled over:* " + strconv.Itoa(report.RolledCards) + " cards (" + strconv.Itoa(report.RolledPoints) + " points) carried into the next sprint\n"
+	message = message + "*Sent to backlog:* " + strconv.Itoa(report.BacklogCards) + " cards (" + strconv.Itoa(report.BacklogPoints) + " points)\n"
+
+	if len(squadTotals) > 0 {
+		message = message + "\n*Squads*\n"
+		for _, s := range squadTotals {
+			if s.SprintPoints == 0 && squadDone[s.SquadName] == 0 {
+				continue
+			}
+			message = message + s.SquadName + ": " + strconv.Itoa(squadDone[s.SquadName]) + " of " + strconv.Itoa(s.SprintPoints) + " points done (" + strconv.Itoa(percentOf(squadDone[s.SquadName], s.SprintPoints)) + "%)\n"
+		}
+	}
+
+	message = message + "\n*Velocity*\n"
+	if len(history) == 0 {
+		return message + "No earlier sprint reports to compare with yet.\n"
+	}
+
+	total := 0
+	for _, h := range history {
+		total = total + h.Completed
+	}
+	avg := float64(total) / float64(len(history))
+	message = message + strconv.Itoa(report.Completed) + " points this sprint against an average of " + strconv.FormatFloat(avg, 'f', 1, 64) + " over the last " + strconv.Itoa(len(history)) + " sprints"
+	if avg > 0 {
+		change := (float64(report.Completed) - avg) / avg * 100
+		sign := ""
+		if change >= 0 {
+			sign = "+"
+		}
+		message = message + " (" + sign + strconv.FormatFloat(change, 'f', 0, 64) + "%)"
+	}
+	message = message + "\n"
+	for _, h := range history {
+		message = message + "    " + h.SprintName + ": " + strconv.Itoa(h.Completed) + " points\n"
+	}
+
+	return message
+}
+
+// SprintClosingReport - build the outgoing sprint's report, post it to SprintChannel and keep it in the DB.  board must
+// be read before `Sprint` moves any cards
+func SprintClosingReport(tiktok *TikTokConf, opts Config, sOpts SprintData, board BoardData, squads Squads) {
+	var attachments Attachment
+
+	report, squadDone := BuildSprintReport(tiktok, opts, sOpts, board, squads)
+
+	committed, err := tiktok.DB.GetCommittedPoints(sOpts)
+	if err != nil {
+		errTrap(tiktok, "Unable to get committed points for sprint `"+sOpts.SprintName+"` in `SprintClosingReport` in `sprintreport.go`. Continuing on...", err)
+	}
+	report.Committed = committed
+
+	squadTotals, err := tiktok.DB.GetPreviousSprintPoints(strings.ToLower(sOpts.SprintName))
+	if err != nil {
+		errTrap(tiktok, "Unable to get squad points for sprint `"+sOpts.SprintName+"` in `SprintClosingReport` in `sprintreport.go`. Continuing on...", err)
+	}
+
+	n := opts.General.VelocitySprints
+	if n <= 0 {
+		n = DefaultVelocitySprints
+	}
+	history, err := tiktok.DB.GetSprintReports(sOpts.TeamID, n)
+	if err != nil {
+		errTrap(tiktok, "Unable to get earlier sprint reports in `SprintClosingReport` in `sprintreport.go`. Continuing on...", err)
+	}
+
+	report.Report = SprintReportText(report, squadTotals, squadDone, history)
+
+	if err := tiktok.DB.RecordSprintReport(report); err != nil {
+		errTrap(tiktok, "Unable to record sprint report for `"+sOpts.SprintName+"` in `SprintClosingReport` in `sprintreport.go`", err)
+	}
+
+	attachments.Color = "#0000ff"
+	attachments.Text = report.Report
+	TeamWrangler(tiktok, opts, "*Sprint "+sOpts.SprintName+" Closing Report* for *"+opts.General.TeamName+"*", opts.General.SprintChannel, attachments)
+}
+
+// GetCommittedPoints - points moved into a sprint when it started.  Sprints from before tiktok_main kept them fall back to
+// the first burndown recorded on or after the sprint started, 0 if there isn't one.  On the rollover day that can be the
+// outgoing sprint's last burndown, which is why the rollover keeps the committed points now
+func (r *Repo) GetCommittedPoints(sOpts SprintData) (points int, err error) {
+	if sOpts.CommittedPts.Valid {
+		return int(sOpts.CommittedPts.Int64), nil
+	}
+
+	if err := r.ready(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT totalpoints FROM tiktok_burndown WHERE team=? AND pointdate>=? ORDER BY pointdate ASC LIMIT 1", sOpts.TeamID, sOpts.SprintStart).Scan(&points)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, nil
+	case err != nil:
+		errTrap(r.tiktok, "DB Query Error in `GetCommittedPoints` in `sprintreport.go`", err)
+		return 0, err
+	}
+
+	return points, nil
+}
+
+// RecordSprintReport - keep a sprint's closing report
+func (r *Repo) RecordSprintReport(report SprintReport) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_sprint_reports SET team=?,sprintname=?,closed=?,committed=?,completed=?,rolledcards=?,rolledpts=?,backlogcards=?,backlogpts=?,report=?", report.Team, report.SprintName, report.Closed, report.Committed, report.Completed, report.RolledCards, report.RolledPoints, report.BacklogCards, report.BacklogPoints, report.Report)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `RecordSprintReport` in `sprintreport.go`", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetSprintReports - a team's last `limit` sprint reports, newest first
+func (r *Repo) GetSprintReports(teamID string, limit int) (reports []SprintReport, err error) {
+	var report SprintReport
+
+	if err := r.ready(); err != nil {
+		return reports, err
+	}
+
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT team,sprintname,closed,committed,completed,rolledcards,rolledpts,backlogcards,backlogpts,report FROM tiktok_sprint_reports WHERE team=? ORDER BY closed DESC LIMIT ?", teamID, limit)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetSprintReports` in `sprintreport.go`", err)
+		return reports, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&report.Team, &report.SprintName, &report.Closed, &report.Committed, &report.Completed, &report.RolledCards, &report.RolledPoints, &report.BacklogCards, &report.BacklogPoints, &report.Report); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetSprintReports` in `sprintreport.go`", err)
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
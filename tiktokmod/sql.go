@@ -3,18 +3,17 @@ package tiktokmod
 import (
 	"database/sql"
 	"fmt"
-	"strconv"
 	"time"
-
-	"github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/dialers/mysql"
 )
 
-// Holiday - Struct for Holiday data
+// Holiday - Struct for Holiday data.  Team is blank for company holidays
 type Holiday struct {
 	ID      int
 	Name    string
 	Day     time.Time
 	Message string
+	Team    string
+	HalfDay bool
 }
 
 // UserData - Matrix of user accounts
@@ -35,7 +34,9 @@ type SprintData struct {
 	Duration    int
 	RetroID     string
 	SprintName  string
-	WorkingDays int
+	WorkingDays float64
+	// CommittedPts - points moved into the sprint when it started, NULL for sprints started before it was kept
+	CommittedPts sql.NullInt64
 }
 
 // BugLabel - Bug Label Information
@@ -107,864 +108,676 @@ type Squads []Squad
 // Chapters - array of Chapter
 type Chapters []Chapter
 
-// ConnectDB - establish gsql connection to db
-func ConnectDB(tiktok *TikTokConf, dbName string) (db *sql.DB, status bool, err error) {
-
-	if tiktok.Config.UseGCP {
-		cfg := mysql.Cfg(tiktok.Config.SQLHost, tiktok.Config.DBUser, tiktok.Config.DBPassword)
-		cfg.DBName = dbName
-		cfg.AllowNativePasswords = tiktok.Config.AllowNativePasswords
-		cfg.AllowCleartextPasswords = tiktok.Config.AllowCleartextPasswords
-		cfg.AllowAllFiles = tiktok.Config.AllowAllFiles
-		cfg.ParseTime = tiktok.Config.ParseTime
-
-		db, err = mysql.DialCfg(cfg)
-		if err != nil {
-			errTrap(tiktok, "DB Connection Error: ", err)
-			return db, false, err
-		}
-
-		return db, true, nil
+// PutSprint - Put sprint data into DB
+func (r *Repo) PutSprint(sOpts SprintData) error {
+	if err := r.ready(); err != nil {
+		return err
 	}
 
-	myConn := tiktok.Config.DBUser + ":" + tiktok.Config.DBPassword + "@tcp(" + tiktok.Config.SQLHost + ":" + tiktok.Config.SQLPort + ")/" + dbName
-	myParams := "?allowAllFiles=" + strconv.FormatBool(tiktok.Config.AllowAllFiles)
-	myParams = myParams + "&allowCleartextPasswords=" + strconv.FormatBool(tiktok.Config.AllowCleartextPasswords)
-	myParams = myParams + "&allowNativePasswords=" + strconv.FormatBool(tiktok.Config.AllowNativePasswords)
-	myParams = myParams + "&parseTime=" + strconv.FormatBool(tiktok.Config.ParseTime)
-	connString := myConn + myParams
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-	db, err = sql.Open("mysql", connString)
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_main SET teamid=?,sprintstart=?,duration=?,retroid=?,sprintname=?,workingdays=?,committedpts=?", sOpts.TeamID, sOpts.SprintStart, sOpts.Duration, sOpts.RetroID, sOpts.SprintName, sOpts.WorkingDays, sOpts.CommittedPts)
 	if err != nil {
-		errTrap(tiktok, "DB Connection Error: ", err)
-		return db, false, err
+		errTrap(r.tiktok, "SQL Error in `PutSprint` in `sql.go`", err)
+		return err
 	}
 
-	return db, true, nil
-
+	return nil
 }
 
-// PutDBSprint - Put sprint data into DB
-func PutDBSprint(tiktok *TikTokConf, sOpts SprintData) error {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if status {
-
-		stmt, err := db.Prepare("INSERT tiktok_main SET teamid=?,sprintstart=?,duration=?,retroid=?,sprintname=?,workingdays=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error db.Prepare in `PutDBSprint` ", err)
-			return err
-		}
-
-		_, err = stmt.Exec(sOpts.TeamID, sOpts.SprintStart, sOpts.Duration, sOpts.RetroID, sOpts.SprintName, sOpts.WorkingDays)
-		if err != nil {
-			errTrap(tiktok, "SQL Error stmt.Exec in `PutDBSprint`", err)
-			return err
-		}
-
-		return nil
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+// GetSprint - Get latest sprint data for a team out of DB
+func (r *Repo) GetSprint(teamID string) (sOpts SprintData, err error) {
+	if err := r.ready(); err != nil {
+		return sOpts, err
 	}
-	return err
 
-}
-
-// GetDBSprint - Get sprint data out of DB
-func GetDBSprint(tiktok *TikTokConf, teamID string) (sOpts SprintData, err error) {
-	var attachments Attachment
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-
-	if status {
-
-		err := db.QueryRow("SELECT * FROM tiktok_main where teamid=? order by sprintstart desc limit 1", teamID).Scan(
-			&sOpts.V2ID,
-			&sOpts.TeamID,
-			&sOpts.SprintStart,
-			&sOpts.Duration,
-			&sOpts.RetroID,
-			&sOpts.SprintName,
-			&sOpts.WorkingDays)
-		switch {
-		case err == sql.ErrNoRows:
-			errTrap(tiktok, "No rows returned for db.QueryRow on "+teamID, err)
-		case err != nil:
-			errTrap(tiktok, "db.QueryRow error: ", err)
-		default:
-			return sOpts, nil
-		}
-		return sOpts, err
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+	err = r.db.QueryRowContext(ctx, "SELECT * FROM tiktok_main where teamid=? order by sprintstart desc limit 1", teamID).Scan(
+		&sOpts.V2ID,
+		&sOpts.TeamID,
+		&sOpts.SprintStart,
+		&sOpts.Duration,
+		&sOpts.RetroID,
+		&sOpts.SprintName,
+		&sOpts.WorkingDays,
+		&sOpts.CommittedPts)
+	switch {
+	case err == sql.ErrNoRows:
+		errTrap(r.tiktok, "No rows returned for db.QueryRow on "+teamID, err)
+	case err != nil:
+		errTrap(r.tiktok, "db.QueryRow error: ", err)
 	}
-	if tiktok.Config.LogToSlack {
-		LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-	}
-	return sOpts, err
 
+	return sOpts, err
 }
 
-// GetRetroID - Get all retro board IDs into one slice
-func GetRetroID(tiktok *TikTokConf, teamID string) (retroStruct []RetroStruct, err error) {
-	var attachments Attachment
+// GetRetroIDs - Get all retro board IDs for a team into one slice
+func (r *Repo) GetRetroIDs(teamID string) (retroStruct []RetroStruct, err error) {
 	var tretro RetroStruct
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return retroStruct, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("select teamid,retroid from tiktok_main where teamid=?", teamID)
-		if err != nil {
-			errTrap(tiktok, "DB Query Error in `GetRetroID` in `sql.go`", err)
+	rows, err := r.db.QueryContext(ctx, "select teamid,retroid from tiktok_main where teamid=?", teamID)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetRetroIDs` in `sql.go`", err)
+		return retroStruct, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tretro.TeamID,
+			&tretro.RetroID); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetRetroIDs` in `sql.go`", err)
 			return retroStruct, err
 		}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&tretro.TeamID,
-				&tretro.RetroID); err != nil {
-				errTrap(tiktok, "DB rows.Scan Error in `GetRetroID` in `sql.go`", err)
-				return retroStruct, err
-			}
-
-			retroStruct = append(retroStruct, tretro)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection in `GetRetroID` in `sql.go`, bailing out", tiktok, attachments)
-		}
-		return retroStruct, err
+		retroStruct = append(retroStruct, tretro)
 	}
 
-	return retroStruct, nil
+	return retroStruct, rows.Err()
 }
 
-// GetDBSquads - get all squads and label IDs in db
-func GetDBSquads(tiktok *TikTokConf, boardID string) (allSquads Squads, err error) {
-	var attachments Attachment
+// GetSquads - get all squads and label IDs in db
+func (r *Repo) GetSquads(boardID string) (allSquads Squads, err error) {
 	var tsquad Squad
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return allSquads, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_squads where boardid=?", boardID)
-		if err != nil {
-			errTrap(tiktok, "DB Query Error", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_squads where boardid=?", boardID)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetSquads` in `sql.go`", err)
+		return allSquads, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tsquad.ID,
+			&tsquad.BoardID,
+			&tsquad.Squadname,
+			&tsquad.LabelID); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetSquads` in `sql.go`", err)
 			return allSquads, err
 		}
+		tsquad.SquadPts = 0
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&tsquad.ID,
-				&tsquad.BoardID,
-				&tsquad.Squadname,
-				&tsquad.LabelID); err != nil {
-				errTrap(tiktok, "DB Query Error", err)
-				return allSquads, err
-
-			}
-			tsquad.SquadPts = 0
-
-			allSquads = append(allSquads, tsquad)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return allSquads, err
+		allSquads = append(allSquads, tsquad)
 	}
 
-	return allSquads, nil
+	return allSquads, rows.Err()
 }
 
-// GetDBChapters - get all chapters and label IDs in db
-func GetDBChapters(tiktok *TikTokConf, boardID string) (allChapters Chapters, err error) {
-	var attachments Attachment
+// GetChapters - get all chapters and label IDs in db
+func (r *Repo) GetChapters(boardID string) (allChapters Chapters, err error) {
 	var tchapter Chapter
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return allChapters, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_chapters where boardid=?", boardID)
-		if err != nil {
-			errTrap(tiktok, "DB Query Error in `GetDBChapters` in `sql.go`", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_chapters where boardid=?", boardID)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetChapters` in `sql.go`", err)
+		return allChapters, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tchapter.ID,
+			&tchapter.BoardID,
+			&tchapter.ChapterName,
+			&tchapter.LabelID); err != nil {
+			errTrap(r.tiktok, "rows.Scan DB error in `GetChapters` in `sql.go`", err)
 			return allChapters, err
 		}
+		tchapter.ChapterPoints = 0
+		tchapter.ChapterCount = 0
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&tchapter.ID,
-				&tchapter.BoardID,
-				&tchapter.ChapterName,
-				&tchapter.LabelID); err != nil {
-				errTrap(tiktok, "rows.Scan DB error in `GetDbChapters` in `sql.go`", err)
-				return allChapters, err
-
-			}
-			tchapter.ChapterPoints = 0
-			tchapter.ChapterCount = 0
-
-			allChapters = append(allChapters, tchapter)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed DB connection in `GetDbChapters` in `sql.go`, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB connection in `GetDbChapters` in `sql.go`, bailing out...", tiktok, attachments)
-		}
-		return allChapters, err
+		allChapters = append(allChapters, tchapter)
 	}
 
-	return allChapters, nil
+	return allChapters, rows.Err()
 }
 
 // GetIgnoreLabels - get all label IDs that should be ignored for a board
-func GetIgnoreLabels(tiktok *TikTokConf, boardID string) (ignoreLabels []string, err error) {
-	var attachments Attachment
+func (r *Repo) GetIgnoreLabels(boardID string) (ignoreLabels []string, err error) {
 	var uid int
 	var labelID string
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return ignoreLabels, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_label_ignore where boardid=?", boardID)
-		if err != nil {
-			errTrap(tiktok, "DB query Error", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_label_ignore where boardid=?", boardID)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error in `GetIgnoreLabels` in `sql.go`", err)
+		return ignoreLabels, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&uid,
+			&boardID,
+			&labelID); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error in `GetIgnoreLabels` in `sql.go`", err)
 			return ignoreLabels, err
 		}
 
-		defer rows.Close()
+		ignoreLabels = append(ignoreLabels, labelID)
+	}
 
-		for rows.Next() {
-			if err := rows.Scan(&uid,
-				&boardID,
-				&labelID); err != nil {
-				errTrap(tiktok, "DB Query Error", err)
-				return ignoreLabels, err
+	return ignoreLabels, rows.Err()
+}
 
-			}
+// AddIgnoreLabel - add a label to the ignore table
+func (r *Repo) AddIgnoreLabel(boardID string, labelID string) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
 
-			ignoreLabels = append(ignoreLabels, labelID)
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return ignoreLabels, err
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_label_ignore SET boardid=?,labelid=?", boardID, labelID)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `AddIgnoreLabel` in `sql.go`", err)
+		return err
 	}
 
-	return ignoreLabels, nil
+	return nil
 }
 
-// LabelIgnore - add a label to the ignore table
-func LabelIgnore(opts Config, tiktok *TikTokConf, labelID string) error {
+// GetUser - get a user from DB where myField matches mySearch
+func (r *Repo) GetUser(myField string, mySearch string) (user UserData, err error) {
+	if err := r.ready(); err != nil {
+		return user, err
+	}
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		stmt, err := db.Prepare("INSERT tiktok_label_ignore SET boardid=?,labelid=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error in LabelIgnore", err)
-			return err
-		}
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_users where "+myField+"=?", mySearch)
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error `db.Query` on tiktok_users in `GetUser`", err)
+		return user, err
+	}
+	defer rows.Close()
 
-		_, err = stmt.Exec(opts.General.BoardID, labelID)
-		if err != nil {
-			errTrap(tiktok, "SQL Error in LabelIgnore", err)
-			return err
+	for rows.Next() {
+		if err := rows.Scan(&user.ID,
+			&user.Name,
+			&user.SlackID,
+			&user.Trello,
+			&user.Github,
+			&user.Email); err != nil {
+			errTrap(r.tiktok, "DB Query Error `rows.Next` on tiktok_users in `GetUser`", err)
+			return user, err
 		}
-
-		return nil
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
 	}
-	return err
 
+	return user, rows.Err()
 }
 
-// GetUser - get a user from DB
-func GetUser(tiktok *TikTokConf, myField string, mySearch string) (user UserData, err error) {
-
-	var attachments Attachment
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-
-	if status {
+// IsRegistered - is this slack user already in the user DB
+func (r *Repo) IsRegistered(slackID string) (bool, error) {
+	var tempSID string
 
-		rows, err := db.Query("SELECT * FROM tiktok_users where " + myField + "='" + mySearch + "'")
-		if err != nil {
-			errTrap(tiktok, "DB Query Error `db.Query` on tiktok_users in `GetUser`", err)
-			return user, err
-		}
+	if err := r.ready(); err != nil {
+		return false, err
+	}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&user.ID,
-				&user.Name,
-				&user.SlackID,
-				&user.Trello,
-				&user.Github,
-				&user.Email); err != nil {
-				errTrap(tiktok, "DB Query Error `rows.Next` on tiktok_users in `GetUser`", err)
-				return user, err
-			}
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return user, err
+	err := r.db.QueryRowContext(ctx, "SELECT slackid FROM tiktok_users where slackid=?", slackID).Scan(&tempSID)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		errTrap(r.tiktok, "db.QueryRow error in `IsRegistered` in `sql.go`", err)
+		return false, err
 	}
 
-	return user, nil
-
+	return true, nil
 }
 
-// GetDBUsers - get all users
-func GetDBUsers(tiktok *TikTokConf) (users []UserData, err error) {
-	var attachments Attachment
+// GetUsers - get all users
+func (r *Repo) GetUsers() (users []UserData, err error) {
 	var u UserData
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return users, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_users")
-		if err != nil {
-			errTrap(tiktok, "DB Query Error on tiktok_users in `GetUser`", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_users")
+	if err != nil {
+		errTrap(r.tiktok, "DB Query Error on tiktok_users in `GetUsers`", err)
+		return users, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&u.ID,
+			&u.Name,
+			&u.SlackID,
+			&u.Trello,
+			&u.Github,
+			&u.Email); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan Error on tiktok_users in `GetUsers`", err)
 			return users, err
 		}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&u.ID,
-				&u.Name,
-				&u.SlackID,
-				&u.Trello,
-				&u.Github,
-				&u.Email); err != nil {
-				errTrap(tiktok, "DB Query Error", err)
-				return users, err
-
-			}
-
-			users = append(users, u)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return users, err
+		users = append(users, u)
 	}
 
-	return users, nil
+	return users, rows.Err()
 }
 
-// AddDBUser - Put user data into DB
-func AddDBUser(tiktok *TikTokConf, users UserData) bool {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL Error in AddDBUser", err)
+// AddUser - Put user data into DB
+func (r *Repo) AddUser(users UserData) bool {
+	if err := r.ready(); err != nil {
+		errTrap(r.tiktok, "SQL Error in `AddUser`", err)
 		return false
 	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		stmt, err := db.Prepare("INSERT tiktok_users SET name=?,slackid=?,trello=?,github=?,email=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error in AddDBUser", err)
-			return false
-		}
-
-		_, err = stmt.Exec(users.Name, users.SlackID, users.Trello, users.Github, users.Email)
-		if err != nil {
-			errTrap(tiktok, "SQL Error in AddDBUser", err)
-			return false
-		}
-
-		return true
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_users SET name=?,slackid=?,trello=?,github=?,email=?", users.Name, users.SlackID, users.Trello, users.Github, users.Email)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `AddUser`", err)
+		return false
 	}
-	return false
 
+	return true
 }
 
-// zeroCardDataDB - drop data in carddata table
-func zeroCardDataDB(tiktok *TikTokConf) error {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL error in `PutCardData`", err)
+// ZeroCardData - drop data in carddata table
+func (r *Repo) ZeroCardData() error {
+	if err := r.ready(); err != nil {
 		return err
 	}
 
-	if status {
-		stmt, err := db.Prepare("TRUNCATE TABLE tiktok_cardtracker")
-		if err != nil {
-			errTrap(tiktok, "SQL Error (db.Prepare) in zeroCardData on TRUNCATE", err)
-			return err
-		}
-
-		_, err = stmt.Exec()
-		if err != nil {
-			errTrap(tiktok, "SQL Error (stmt.Exec) in zeroCardData on TRUNCATE", err)
-			return err
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		return nil
-	}
-
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection in `zeroCardData` in `sql.go`, bailing out...")
+	_, err := r.db.ExecContext(ctx, "TRUNCATE TABLE tiktok_cardtracker")
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `ZeroCardData` on TRUNCATE", err)
+		return err
 	}
 
-	return err
-
+	return nil
 }
 
 // PutCardData - put card data to DB instead of CSV
-func PutCardData(tiktok *TikTokConf, allCardData CardReportData, teamID string) error {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL error in `PutCardData`", err)
+func (r *Repo) PutCardData(allCardData CardReportData, teamID string) error {
+	if err := r.ready(); err != nil {
 		return err
 	}
 
-	if status {
-		stmt, err := db.Prepare("INSERT tiktok_cardtracker SET cardid=?,cardtitle=?,points=?,cardurl=?,list=?,startedinworking=?,startedinpr=?,entereddone=?,owners=?,team=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error (db.Prepare) in `PutCardData`", err)
-			return err
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		_, err = stmt.Exec(allCardData.CardID, allCardData.CardTitle, allCardData.Points, allCardData.CardURL, allCardData.List, allCardData.StartedInWorking, allCardData.StartedInPR, allCardData.EnteredDone, allCardData.Owners, teamID)
-		if err != nil {
-			errTrap(tiktok, "SQL Error (stmt.Exec) in `PutCardData`", err)
-			return err
-		}
-
-		return nil
-	}
-
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection in `PutCardData` in `sql.go`, bailing out...")
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_cardtracker SET cardid=?,cardtitle=?,points=?,cardurl=?,list=?,startedinworking=?,startedinpr=?,entereddone=?,owners=?,team=?",
+		allCardData.CardID, allCardData.CardTitle, allCardData.Points, allCardData.CardURL, allCardData.List, allCardData.StartedInWorking, allCardData.StartedInPR, allCardData.EnteredDone, allCardData.Owners, teamID)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `PutCardData`", err)
+		return err
 	}
 
-	return err
-
+	return nil
 }
 
 // PutThemeCount - Update board theme counts for reporting
-func PutThemeCount(tiktok *TikTokConf, allTheme Themes, sOpts SprintData, teamID string) error {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL error in `PutThemeCount`", err)
+func (r *Repo) PutThemeCount(allTheme Themes, sOpts SprintData, teamID string) error {
+	if err := r.ready(); err != nil {
 		return err
 	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		today := time.Now().Local()
-		today.Format("2006-01-02 15:04:05")
+	today := time.Now().Local()
 
-		for _, z := range allTheme {
-			stmt, err := db.Prepare("INSERT tiktok_theme_count SET countdate=?,team=?,sprintname=?,labelname=?,qty=?")
-			if err != nil {
-				errTrap(tiktok, "SQL error in `PutThemeCount`", err)
-				return err
-			}
-
-			_, err = stmt.Exec(today, teamID, sOpts.SprintName, z.Name, z.Pts)
-			if err != nil {
-				errTrap(tiktok, "SQL error in `PutThemeCount`", err)
-				return err
-			}
+	for _, z := range allTheme {
+		_, err := r.db.ExecContext(ctx, "INSERT tiktok_theme_count SET countdate=?,team=?,sprintname=?,labelname=?,qty=?", today, teamID, sOpts.SprintName, z.Name, z.Pts)
+		if err != nil {
+			errTrap(r.tiktok, "SQL error in `PutThemeCount`", err)
+			return err
 		}
-		return nil
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
 	}
 
-	return err
+	return nil
 }
 
 // GetPreviousSprintPoints - Retrieve Previous sprint data from CloudSQL
-func GetPreviousSprintPoints(tiktok *TikTokConf, sprintname string) (totalSprint TotalSprint, err error) {
-
+func (r *Repo) GetPreviousSprintPoints(sprintname string) (totalSprint TotalSprint, err error) {
 	var tempPoints SprintPointsBySquad
-	var attachments Attachment
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return totalSprint, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_sprint_squad_points where LOWER(sprintname)=?", sprintname)
-		if err != nil {
-			errTrap(tiktok, "`GetPreviousSprintPoints` Function error: DB Query Error", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT sprintname,squadname,squadpoints FROM tiktok_sprint_squad_points where LOWER(sprintname)=?", sprintname)
+	if err != nil {
+		errTrap(r.tiktok, "`GetPreviousSprintPoints` Function error: DB Query Error", err)
+		return totalSprint, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tempPoints.SprintName,
+			&tempPoints.SquadName,
+			&tempPoints.SprintPoints); err != nil {
+			errTrap(r.tiktok, "`GetPreviousSprintPoints` Function error: DB rows.Scan Error", err)
 			return totalSprint, err
 		}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&tempPoints.SprintName,
-				&tempPoints.SquadName,
-				&tempPoints.SprintPoints); err != nil {
-				errTrap(tiktok, "`GetPreviousSprintPoints` Function error: DB Query Error", err)
-				return totalSprint, err
-
-			}
-
-			totalSprint = append(totalSprint, tempPoints)
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return totalSprint, err
+		totalSprint = append(totalSprint, tempPoints)
 	}
 
-	return totalSprint, nil
-
+	return totalSprint, rows.Err()
 }
 
-// GetHoliday - Get List of Holidays in SQL DB.  If year is 0, all dates are returned
-func GetHoliday(tiktok *TikTokConf, year string) (theHolidays []Holiday, err error) {
-
+// GetHolidays - Get List of Holidays in SQL DB.  If year is 0, all dates are returned
+func (r *Repo) GetHolidays(year string) (theHolidays []Holiday, err error) {
 	var tempHoliday Holiday
-	var attachments Attachment
 	var rows *sql.Rows
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return theHolidays, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		if year == "0" {
-			rows, err = db.Query("SELECT * FROM tiktok_holidays ORDER BY holiday")
-		} else {
-			rows, err = db.Query("SELECT * FROM tiktok_holidays where YEAR(holiday)=? ORDER BY holiday", year)
-		}
-		if err != nil {
-			errTrap(tiktok, "`GetHoliday` Function error: DB Query Error", err)
+	if year == "0" {
+		rows, err = r.db.QueryContext(ctx, "SELECT holidayid,name,holiday,message,team,halfday FROM tiktok_holidays ORDER BY holiday")
+	} else {
+		rows, err = r.db.QueryContext(ctx, "SELECT holidayid,name,holiday,message,team,halfday FROM tiktok_holidays where YEAR(holiday)=? ORDER BY holiday", year)
+	}
+	if err != nil {
+		errTrap(r.tiktok, "`GetHolidays` Function error: DB Query Error", err)
+		return theHolidays, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tempHoliday.ID,
+			&tempHoliday.Name,
+			&tempHoliday.Day,
+			&tempHoliday.Message,
+			&tempHoliday.Team,
+			&tempHoliday.HalfDay); err != nil {
+			errTrap(r.tiktok, "`GetHolidays` Function error: DB rows.Scan Error", err)
 			return theHolidays, err
 		}
 
-		defer rows.Close()
+		theHolidays = append(theHolidays, tempHoliday)
+	}
 
-		for rows.Next() {
-			if err := rows.Scan(&tempHoliday.ID,
-				&tempHoliday.Name,
-				&tempHoliday.Day,
-				&tempHoliday.Message); err != nil {
-				errTrap(tiktok, "`GetHoliday` Function error: DB Query Error", err)
-				return theHolidays, err
+	return theHolidays, rows.Err()
+}
 
-			}
+// GetTeamHolidays - Get every company holiday plus a team's own holidays
+func (r *Repo) GetTeamHolidays(team string) (theHolidays []Holiday, err error) {
+	var tempHoliday Holiday
 
-			theHolidays = append(theHolidays, tempHoliday)
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
+	if err := r.ready(); err != nil {
 		return theHolidays, err
 	}
 
-	return theHolidays, nil
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT holidayid,name,holiday,message,team,halfday FROM tiktok_holidays where team='' OR team=? ORDER BY holiday", team)
+	if err != nil {
+		errTrap(r.tiktok, "`GetTeamHolidays` Function error: DB Query Error", err)
+		return theHolidays, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&tempHoliday.ID,
+			&tempHoliday.Name,
+			&tempHoliday.Day,
+			&tempHoliday.Message,
+			&tempHoliday.Team,
+			&tempHoliday.HalfDay); err != nil {
+			errTrap(r.tiktok, "`GetTeamHolidays` Function error: DB rows.Scan Error", err)
+			return theHolidays, err
+		}
+
+		theHolidays = append(theHolidays, tempHoliday)
+	}
+
+	return theHolidays, rows.Err()
 }
 
-// IsHoliday - Check for Holidays in SQL DB
-func IsHoliday(tiktok *TikTokConf, checkDate time.Time) (isHoliday bool, holiday Holiday) {
+// IsHoliday - Check for company Holidays in SQL DB.  Team holidays and half days need a team `Calendar`
+func (r *Repo) IsHoliday(checkDate time.Time) (isHoliday bool, holiday Holiday) {
 	var attachments Attachment
 
+	if err := r.ready(); err != nil {
+		if r.tiktok.Config.LogToSlack {
+			LogToSlack("Failed DB Connection in `sql.go` for IsHoliday Func, bailing out - "+err.Error(), r.tiktok, attachments)
+		}
+		return false, holiday
+	}
+
 	// checks for holidays in PST
 	loc, err := time.LoadLocation("America/Tijuana")
 	if err != nil {
-		errTrap(tiktok, "TZ Data Error", err)
+		errTrap(r.tiktok, "TZ Data Error", err)
 		return false, holiday
 	}
 
 	t := checkDate.In(loc)
 	today := t.Format("2006-01-02")
 
-	db, status, errdb := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-
-	if status {
-
-		err := db.QueryRow("SELECT * FROM tiktok_holidays where holiday=? limit 1", today).Scan(
-			&holiday.ID,
-			&holiday.Name,
-			&holiday.Day,
-			&holiday.Message)
-		switch {
-		case err == sql.ErrNoRows:
-			if tiktok.Config.DEBUG {
-				fmt.Println("No rows returned for db.QueryRow on Holiday Check in `sql.go`")
-			}
-			return false, holiday
-		case err != nil:
-			errTrap(tiktok, "db.QueryRow error", err)
-			return false, holiday
-		default:
-			return true, holiday
+	ctx, cancel := r.ctx()
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, "SELECT holidayid,name,holiday,message,team,halfday FROM tiktok_holidays where holiday=? AND team='' limit 1", today).Scan(
+		&holiday.ID,
+		&holiday.Name,
+		&holiday.Day,
+		&holiday.Message,
+		&holiday.Team,
+		&holiday.HalfDay)
+	switch {
+	case err == sql.ErrNoRows:
+		if r.tiktok.Config.DEBUG {
+			fmt.Println("No rows returned for db.QueryRow on Holiday Check in `sql.go`")
 		}
+		return false, holiday
+	case err != nil:
+		errTrap(r.tiktok, "db.QueryRow error", err)
+		return false, holiday
 	}
 
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection to db in sql.go for holiday check, bailing out - " + errdb.Error())
-	}
-	if tiktok.Config.LogToSlack {
-		LogToSlack("Failed DB Connection in `sql.go` for IsHoliday Func, bailing out - "+errdb.Error(), tiktok, attachments)
-	}
-
-	return false, holiday
+	return true, holiday
 }
 
 // RecordSquadSprintData - Record points for sprint per squad
-func RecordSquadSprintData(tiktok *TikTokConf, totalPoints Squads, sprintName string, nonPoints int) bool {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL Error in RecordSquadSprintData", err)
+func (r *Repo) RecordSquadSprintData(totalPoints Squads, sprintName string, nonPoints int) bool {
+	if err := r.ready(); err != nil {
+		errTrap(r.tiktok, "SQL Error in RecordSquadSprintData", err)
 		return false
 	}
 
-	if status {
-
-		stmt, err := db.Prepare("INSERT tiktok_sprint_squad_points SET sprintname=?,squadname=?,squadpoints=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error in `db.Prepare` func `RecordSquadSprintData`", err)
-			return false
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		for _, s := range totalPoints {
-			_, err = stmt.Exec(sprintName, s.Squadname, s.SquadPts)
-			if err != nil {
-				errTrap(tiktok, "SQL Error in `stmt.Exec` func `RecordSquadSprintData`", err)
-				return false
-			}
-		}
-		_, err = stmt.Exec(sprintName, "Non-Squad", nonPoints)
+	for _, s := range totalPoints {
+		_, err := r.db.ExecContext(ctx, "INSERT tiktok_sprint_squad_points SET sprintname=?,squadname=?,squadpoints=?", sprintName, s.Squadname, s.SquadPts)
 		if err != nil {
-			errTrap(tiktok, "SQL Error in `stmt.Exec` for non-squad in func `RecordSquadSprintData`", err)
+			errTrap(r.tiktok, "SQL Error in `db.Exec` func `RecordSquadSprintData`", err)
 			return false
 		}
-
-		return true
 	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_sprint_squad_points SET sprintname=?,squadname=?,squadpoints=?", sprintName, "Non-Squad", nonPoints)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in `db.Exec` for non-squad in func `RecordSquadSprintData`", err)
+		return false
 	}
-	return false
 
+	return true
 }
 
-//DupeTable - Duplicates table inside CloudSQL DB
-func DupeTable(tiktok *TikTokConf, newTableName string, existTableName string) error {
-
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
-	if err != nil {
-		errTrap(tiktok, "SQL Error in RecordSquadSprintData", err)
+// DupeTable - Duplicates table inside CloudSQL DB
+func (r *Repo) DupeTable(newTableName string, existTableName string) error {
+	if err := r.ready(); err != nil {
 		return err
 	}
 
-	if status {
-		stmt, err := db.Prepare("CREATE TABLE " + newTableName + " LIKE " + existTableName)
-		if err != nil {
-			errTrap(tiktok, "SQL Error (db.Prepare) in DupeTable on CREATE TABLE", err)
-			return err
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		_, err = stmt.Exec()
-		if err != nil {
-			errTrap(tiktok, "SQL Error (stmt.Exec) in DupeTable on CREATE TABLE", err)
-			return err
-		}
+	_, err := r.db.ExecContext(ctx, "CREATE TABLE "+newTableName+" LIKE "+existTableName)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in DupeTable on CREATE TABLE", err)
+		return err
+	}
 
-		stmt, err = db.Prepare("INSERT INTO " + newTableName + " SELECT * FROM " + existTableName)
-		if err != nil {
-			errTrap(tiktok, "SQL Error (db.Prepare) in DupeTable on INSERT INTO", err)
-			return err
-		}
+	_, err = r.db.ExecContext(ctx, "INSERT INTO "+newTableName+" SELECT * FROM "+existTableName)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in DupeTable on INSERT INTO", err)
+		return err
+	}
 
-		_, err = stmt.Exec()
-		if err != nil {
-			errTrap(tiktok, "SQL Error (stmt.Exec) in DupeTable on INSERT INTO", err)
-			return err
-		}
+	return nil
+}
 
-		return nil
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+// RecordChapterCount - Record card count for a chapter in a list
+func (r *Repo) RecordChapterCount(chapterName string, listName string, cardCount int, teamName string) bool {
+	if err := r.ready(); err != nil {
+		errTrap(r.tiktok, "SQL Error in RecordChapterCount", err)
+		return false
 	}
-	return err
-}
 
-// RecordChapterCount - Record points for sprint per squad
-func RecordChapterCount(tiktok *TikTokConf, chapterName string, listName string, cardCount int, teamName string) bool {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	timeStamp := time.Now().Local()
+
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_chapter_cards SET timestamp=?,chaptername=?,listname=?,cards=?,team=?", timeStamp, chapterName, listName, cardCount, teamName)
 	if err != nil {
-		errTrap(tiktok, "SQL Error in RecordChapterCount", err)
+		errTrap(r.tiktok, "SQL Error in `db.Exec` func `RecordChapterCount`", err)
 		return false
 	}
 
-	if status {
+	return true
+}
 
-		timeStamp := time.Now().Local()
-		timeStamp.Format("2006-01-02")
+// RecordBurndown - Record todays burndown points for a team
+func (r *Repo) RecordBurndown(teamID string, totalPoints int, rfwpts int, wkgpts int, rfrpts int, dnepts int, numCards int, idealPts int) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
 
-		stmt, err := db.Prepare("INSERT tiktok_chapter_cards SET timestamp=?,chaptername=?,listname=?,cards=?,team=?")
-		if err != nil {
-			errTrap(tiktok, "SQL Error in `db.Prepare` func `RecordChapterCount`", err)
-			return false
-		}
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		_, err = stmt.Exec(timeStamp, chapterName, listName, cardCount, teamName)
-		if err != nil {
-			errTrap(tiktok, "SQL Error in `stmt.Exec` func `RecordChapterCount`", err)
-			return false
-		}
+	today := time.Now().Local()
 
-		return true
-	}
-	if tiktok.Config.DEBUG {
-		fmt.Println("Failed connection, bailing out...")
+	_, err := r.db.ExecContext(ctx, "INSERT tiktok_burndown SET pointdate=?,team=?,totalpoints=?,rfwpts=?,wkgpts=?,uatpts=?,dnepts=?,numcards=?,idealpts=?", today, teamID, totalPoints, rfwpts, wkgpts, rfrpts, dnepts, numCards, idealPts)
+	if err != nil {
+		errTrap(r.tiktok, "SQL Error in tiktok_burndown table insert:", err)
+		return err
 	}
-	return false
 
+	return nil
 }
 
-// GetBugID - get all Bug label IDs for a given board
-func GetBugID(tiktok *TikTokConf, boardID string) (bugs []BugLabel, err error) {
-	var attachments Attachment
+// GetBugIDs - get all Bug label IDs for a given board
+func (r *Repo) GetBugIDs(boardID string) (bugs []BugLabel, err error) {
 	var temp BugLabel
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return bugs, err
+	}
 
-	if status {
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-		rows, err := db.Query("SELECT * FROM tiktok_bug_label where boardid=?", boardID)
-		if err != nil {
-			errTrap(tiktok, "DB query Error in `GetBugID` function in `sql.go`", err)
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_bug_label where boardid=?", boardID)
+	if err != nil {
+		errTrap(r.tiktok, "DB query Error in `GetBugIDs` function in `sql.go`", err)
+		return bugs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&temp.ID,
+			&temp.BoardID,
+			&temp.BugLevel,
+			&temp.LabelID); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan error in `GetBugIDs` function in `sql.go`", err)
 			return bugs, err
 		}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&temp.ID,
-				&temp.BoardID,
-				&temp.BugLevel,
-				&temp.LabelID); err != nil {
-				errTrap(tiktok, "DB rows.Scan error in `GetBugID` function in `sql.go`", err)
-				return bugs, err
-
-			}
-
-			bugs = append(bugs, temp)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return bugs, err
+		bugs = append(bugs, temp)
 	}
 
-	return bugs, nil
+	return bugs, rows.Err()
 }
 
 // GetSquadMembership - Get list of squads a user is part of in a given sprint
-func GetSquadMembership(tiktok *TikTokConf, dbUserID int, sprintName string) (userList []string, err error) {
+func (r *Repo) GetSquadMembership(dbUserID int, sprintName string) (userList []string, err error) {
+	var peeps peeps
 
-	db, status, err := ConnectDB(tiktok, tiktok.Config.SQLDBName)
+	if err := r.ready(); err != nil {
+		return userList, err
+	}
 
-	var peeps peeps
-	var attachments Attachment
+	ctx, cancel := r.ctx()
+	defer cancel()
 
-	if status {
+	rows, err := r.db.QueryContext(ctx, "SELECT * FROM tiktok_squad_peeps where userID=? AND sprint=?", dbUserID, sprintName)
+	if err != nil {
+		errTrap(r.tiktok, "DB query Error in `GetSquadMembership` function in `sql.go`", err)
+		return userList, err
+	}
+	defer rows.Close()
 
-		rows, err := db.Query("SELECT * FROM tiktok_squad_peeps where userID=? AND sprint=?", dbUserID, sprintName)
-		if err != nil {
-			errTrap(tiktok, "DB query Error in `GetSquadMembership` function in `sql.go`", err)
+	for rows.Next() {
+		if err := rows.Scan(&peeps.ID,
+			&peeps.Sprint,
+			&peeps.Squad,
+			&peeps.UserID); err != nil {
+			errTrap(r.tiktok, "DB rows.Scan error in `GetSquadMembership` function in `sql.go`", err)
 			return userList, err
 		}
 
-		defer rows.Close()
-
-		for rows.Next() {
-			if err := rows.Scan(&peeps.ID,
-				&peeps.Sprint,
-				&peeps.Squad,
-				&peeps.UserID); err != nil {
-				errTrap(tiktok, "DB rows.Scan error in `GetSquadMembership` function in `sql.go`", err)
-				return userList, err
-
-			}
-
-			userList = append(userList, peeps.Squad)
-
-		}
-	} else {
-		if tiktok.Config.DEBUG {
-			fmt.Println("Failed connection, bailing out...")
-		}
-		if tiktok.Config.LogToSlack {
-			LogToSlack("Failed DB Connection, bailing out", tiktok, attachments)
-		}
-		return userList, err
+		userList = append(userList, peeps.Squad)
 	}
 
-	return userList, nil
+	return userList, rows.Err()
 }
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // Startup - Startup stuff
@@ -13,25 +14,58 @@ func Startup(tiktokOpts *TikTokConf) (*TikTokConf, bool) {
 
 	tkey := flag.String("tkey", "", "Trello Key")
 	ttoken := flag.String("ttoken", "", "Trello Token")
+	tsecret := flag.String("tsecret", "", "Trello OAuth Secret (verifies trello webhook callbacks)")
 	slackhook := flag.String("slackhook", "", "Slack Webhook")
 	slacktoken := flag.String("slacktoken", "", "Slack Bot Token")
 	slackoauth := flag.String("slackoauth", "", "Slack OAuth User Token")
+	slacksigning := flag.String("slacksigning", "", "Slack Signing Secret (Events API transport)")
+	slackapp := flag.String("slackapp", "", "Slack App Level Token (Socket Mode transport)")
 	ghtoken := flag.String("git", "", "Github Token")
 	dbuser := flag.String("dbuser", "", "CSQL DB User Acct")
 	dbpassword := flag.String("dbpassword", "", "CSQL DB User Password")
 	nocron := flag.Bool("nocron", false, "Start "+tiktok.Config.BotName+" without loading cron jobs")
 	version := flag.Bool("v", false, "Show current version number")
 	osenv := flag.Bool("osenv", false, "All tokens are being passed by OS ENV instead of CLI")
+	fakeboard := flag.String("fakeboard", "", "Serve all Trello calls from this board fixture file instead of api.trello.com")
+	migrate := flag.Bool("migrate", false, "Apply any pending database schema migrations and exit")
+	diffbackup := flag.Bool("diffbackup", false, "Show what changed between the two board backup files given after the flags and exit")
+	restorebackup := flag.String("restorebackup", "", "Recreate the lists and cards in this board backup file on -restoreboard and exit")
+	restoreboard := flag.String("restoreboard", "", "Trello board ID to restore -restorebackup into")
 
 	flag.Parse()
 
+	if *diffbackup {
+		if flag.NArg() != 2 {
+			fmt.Println("Usage: -diffbackup <older backup.json> <newer backup.json>")
+			os.Exit(1)
+		}
+		older, err := LoadBackup(flag.Arg(0))
+		if err == nil {
+			var newer BoardBackup
+			newer, err = LoadBackup(flag.Arg(1))
+			if err == nil {
+				var diff string
+				diff, err = DiffBackups(older, newer)
+				fmt.Print(diff)
+			}
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if *osenv {
 		fmt.Println("Checking OS ENV for parameters")
 		tiktokOpts.Config.Tkey = os.Getenv("tkey")
 		tiktokOpts.Config.Ttoken = os.Getenv("ttoken")
+		tiktokOpts.Config.TrelloSecret = os.Getenv("tsecret")
 		tiktokOpts.Config.SlackHook = os.Getenv("slackhook")
 		tiktokOpts.Config.SlackToken = os.Getenv("slacktoken")
 		tiktokOpts.Config.SlackOAuth = os.Getenv("slackoauth")
+		tiktokOpts.Config.SlackSigningSecret = os.Getenv("slacksigning")
+		tiktokOpts.Config.SlackAppToken = os.Getenv("slackapp")
 		tiktokOpts.Config.DBUser = os.Getenv("dbuser")
 		tiktokOpts.Config.DBPassword = os.Getenv("dbpassword")
 		tiktokOpts.Config.GitToken = os.Getenv("git")
@@ -49,9 +83,12 @@ func Startup(tiktokOpts *TikTokConf) (*TikTokConf, bool) {
 		fmt.Println("Checking CLI for parameters")
 		tiktokOpts.Config.Tkey = *tkey
 		tiktokOpts.Config.Ttoken = *ttoken
+		tiktokOpts.Config.TrelloSecret = *tsecret
 		tiktokOpts.Config.SlackHook = *slackhook
 		tiktokOpts.Config.SlackToken = *slacktoken
 		tiktokOpts.Config.SlackOAuth = *slackoauth
+		tiktokOpts.Config.SlackSigningSecret = *slacksigning
+		tiktokOpts.Config.SlackAppToken = *slackapp
 		tiktokOpts.Config.DBUser = *dbuser
 		tiktokOpts.Config.DBPassword = *dbpassword
 		tiktokOpts.Config.GitToken = *ghtoken
@@ -69,6 +106,68 @@ func Startup(tiktokOpts *TikTokConf) (*TikTokConf, bool) {
 
 	nocrontab := *nocron
 
+	InstrumentHTTP()
+
+	if *restorebackup != "" {
+		if *restoreboard == "" {
+			fmt.Println("-restorebackup needs the trello board ID to restore into with -restoreboard")
+			os.Exit(1)
+		}
+		backup, err := LoadBackup(*restorebackup)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		output, err := RestoreBackup(tiktokOpts, NewTrelloClient(tiktokOpts), backup, *restoreboard)
+		fmt.Print(output)
+		if err != nil {
+			fmt.Println("Restore failed: " + err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	tiktokOpts.Trello = NewTrelloClient(tiktokOpts)
+	if *fakeboard != "" {
+		fake, err := LoadFakeTrello(tiktokOpts, *fakeboard)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(0)
+		}
+		tiktokOpts.Trello = fake
+		fmt.Println("Using fake Trello board fixture " + *fakeboard + ", nothing will be sent to api.trello.com by the sprint/alerting routines")
+	}
+
+	db, err := NewRepo(tiktokOpts)
+	if err != nil {
+		errTrap(tiktokOpts, "Unable to reach the "+tiktokOpts.Config.SQLDBName+" database at startup, DB calls will fail until it comes back", err)
+	}
+	tiktokOpts.DB = db
+
+	if *migrate {
+		applied, err := db.Migrate()
+		for _, m := range applied {
+			fmt.Println("Applied migration " + m)
+		}
+		if err != nil {
+			fmt.Println("Migration failed: " + err.Error())
+			os.Exit(1)
+		}
+		fmt.Println("Database schema is at version " + strconv.Itoa(SchemaVersion()))
+		os.Exit(0)
+	}
+
+	// refuse to run against a schema older than sql.go expects, an unreachable DB is already logged above
+	if err == nil {
+		if err := db.CheckSchema(); err != nil {
+			fmt.Println("Database schema check failed: " + err.Error())
+			os.Exit(1)
+		}
+	}
+
+	StartStatusServer(tiktokOpts)
+	StartTrelloWebhooks(tiktokOpts)
+
 	if tiktokOpts.Config.LogToSlack {
 		LogToSlack("*Hi I'm starting up after being stopped!* - Version `"+tiktokOpts.Config.Version+"`", tiktokOpts, attachments)
 	}
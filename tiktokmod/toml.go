@@ -26,6 +26,11 @@ type TikTokStruct struct {
 	SlackHook               string
 	SlackToken              string
 	SlackOAuth              string
+	SlackTransport          string
+	SlackSigningSecret      string
+	SlackAppToken           string
+	EventsListen            string
+	EventsPath              string
 	Tkey                    string
 	Ttoken                  string
 	GitToken                string
@@ -55,7 +60,19 @@ type TikTokStruct struct {
 	AllowCleartextPasswords bool
 	AllowAllFiles           bool
 	ParseTime               bool
+	DBMaxOpenConns          int
+	DBMaxIdleConns          int
+	DBConnMaxLifetime       int
+	DBQueryTimeout          int
 	GithubOrgName           string
+	StatusPort              int
+	InstanceID              string
+	BoardCacheTTL           int
+	TrelloSecret            string
+	TrelloWebhookURL        string
+	TrelloWebhookListen     string
+	BackupDir               string
+	BackupKeep              int
 }
 
 //GeneralOptions struct for configs
@@ -71,6 +88,11 @@ type GeneralOptions struct {
 	RetroActionDays int
 	IgnoreWeekends  bool
 	HolidaySupport  bool
+	PointsSource    string
+	RequireDueDates bool
+	VelocitySprints int
+	TimeZone        string
+	OnCallLoad      int
 
 	BacklogID         string
 	Upcoming          string
@@ -89,8 +111,15 @@ type GeneralOptions struct {
 	AllowMembersLabel string
 	TrainingLabel     string
 	SilenceCardLabel  string
+	BlockedLabel      string
 	DemoBoardID       string
 
+	SlackHook     string
+	SlackToken    string
+	SlackUsername string
+	SlackEmoji    string
+	SlackIconURL  string
+
 	RetroChannel     string
 	SprintChannel    string
 	ComplaintChannel string
@@ -113,6 +142,8 @@ type Config struct {
 // TikTokConf - Struct of tiktok conf file section
 type TikTokConf struct {
 	Config TikTokStruct
+	Trello TrelloAPI `toml:"-"`
+	DB     *Repo     `toml:"-"`
 }
 
 var conf Config
@@ -194,7 +225,7 @@ func SanityCheck(ConfigLocation string, ms GeneralOptions) (sane bool, output st
 		field.SetString(str)
 		if str == "" {
 			// ignore these fields which can be blank
-			if typ == "RetroCollectionID" || typ == "DemoBoardID" || typ == "StandupAlertChannel" || typ == "StandupLink" || typ == "DemoAlertChannel" || typ == "DemoAlertLink" || typ == "RetroAlertChannel" || typ == "RetroAlertLink" || typ == "WDWAlertChannel" || typ == "WDWAlertLink" {
+			if typ == "RetroCollectionID" || typ == "PointsSource" || typ == "TimeZone" || typ == "BlockedLabel" || typ == "DemoBoardID" || typ == "StandupAlertChannel" || typ == "StandupLink" || typ == "DemoAlertChannel" || typ == "DemoAlertLink" || typ == "RetroAlertChannel" || typ == "RetroAlertLink" || typ == "WDWAlertChannel" || typ == "WDWAlertLink" || strings.HasPrefix(typ, "Slack") {
 				str = ""
 			} else {
 				message = message + "Value " + typ + " can not be blank!\n"
@@ -224,6 +255,12 @@ func LoadConf(tiktok *TikTokConf, team string) (opts Config, err error) {
 
 	copier.Copy(&opts, &slopts)
 
+	// swap "name:" lists and labels for their trello UIDs
+	if err := ResolveNames(tiktok, configLocation, &opts); err != nil {
+		errTrap(tiktok, "Failure resolving list and label names for team file `"+configLocation+"`.", err)
+		return opts, err
+	}
+
 	// Run sanity check on the config file
 	sane, output := SanityCheck(configLocation, opts.General)
 	if !sane {
@@ -252,8 +289,8 @@ func FindToml(tiktok *TikTokConf) (tomls []os.FileInfo, err error) {
 	return tomls, nil
 }
 
-// ListAllTOML - list all the available TOML files in a string
-func ListAllTOML(tiktok *TikTokConf) (message string) {
+// TeamIDs - every team TOML in cfg/, by the name commands and crons.toml refer to them with
+func TeamIDs(tiktok *TikTokConf) (teams []string) {
 
 	tomls, _ := FindToml(tiktok)
 
@@ -263,14 +300,24 @@ func ListAllTOML(tiktok *TikTokConf) (message string) {
 			s := strings.Split(f.Name(), ".")
 
 			if s[len(s)-1] == "toml" {
-				opts, _ := LoadConf(tiktok, s[0])
-				message = message + "<https://trello.com/b/" + opts.General.BoardID + "|" + opts.General.TeamName + " trello board>.  Refer to ID: [" + s[0] + "]\n"
+				teams = append(teams, s[0])
 			}
 
 		}
 
 	}
 
+	return teams
+}
+
+// ListAllTOML - list all the available TOML files in a string
+func ListAllTOML(tiktok *TikTokConf) (message string) {
+
+	for _, team := range TeamIDs(tiktok) {
+		opts, _ := LoadConf(tiktok, team)
+		message = message + "<https://trello.com/b/" + opts.General.BoardID + "|" + opts.General.TeamName + " trello board>.  Refer to ID: [" + team + "]\n"
+	}
+
 	return message
 
 }
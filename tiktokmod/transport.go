@@ -0,0 +1,150 @@
+package tiktokmod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nlopes/slack"
+)
+
+// Messenger - how Responder and the command handlers talk back to slack.  *slack.RTM already satisfies it
+type Messenger interface {
+	SendMessage(msg *slack.OutgoingMessage)
+	NewOutgoingMessage(text string, channelID string) *slack.OutgoingMessage
+}
+
+// MessageHandler - called once per message mentioning the bot or DM'd to it, never concurrently
+type MessageHandler func(ev *slack.MessageEvent, out Messenger)
+
+// Transport - where slack messages come from.  RTM, the HTTP Events API or Socket Mode
+type Transport interface {
+	Run(handle MessageHandler) error
+}
+
+// NewTransport - pick the transport configured by SlackTransport in tiktok.toml, RTM if it's blank
+func NewTransport(tiktok *TikTokConf, api *slack.Client) (Transport, error) {
+	switch strings.ToLower(tiktok.Config.SlackTransport) {
+	case "", "rtm":
+		return &RTMTransport{tiktok: tiktok, api: api}, nil
+	case "events":
+		if tiktok.Config.SlackSigningSecret == "" {
+			return nil, errors.New("SlackTransport events requires a slack signing secret")
+		}
+		return NewEventsTransport(tiktok, api), nil
+	case "socket":
+		if tiktok.Config.SlackAppToken == "" {
+			return nil, errors.New("SlackTransport socket requires a slack app level token")
+		}
+		return NewSocketTransport(tiktok, api), nil
+	}
+
+	return nil, errors.New("unknown SlackTransport `" + tiktok.Config.SlackTransport + "`, use rtm, events or socket")
+}
+
+// WebMessenger - Messenger for transports with no websocket to write to, posts through the Web API instead
+type WebMessenger struct {
+	tiktok *TikTokConf
+	api    *slack.Client
+}
+
+// NewOutgoingMessage - build a message for SendMessage
+func (w *WebMessenger) NewOutgoingMessage(text string, channelID string) *slack.OutgoingMessage {
+	return &slack.OutgoingMessage{Type: "message", Channel: channelID, Text: text}
+}
+
+// SendMessage - post a message with chat.postMessage as the bot user
+func (w *WebMessenger) SendMessage(msg *slack.OutgoingMessage) {
+	params := slack.NewPostMessageParameters()
+	params.AsUser = true
+
+	_, _, err := w.api.PostMessage(msg.Channel, msg.Text, params)
+	if err != nil {
+		errTrap(w.tiktok, "Slack PostMessage error in `SendMessage` in `transport.go`", err)
+	}
+}
+
+// RTMTransport - the original real time messaging websocket
+type RTMTransport struct {
+	tiktok *TikTokConf
+	api    *slack.Client
+}
+
+// Run - listen on RTM until the connection dies for good
+func (t *RTMTransport) Run(handle MessageHandler) error {
+	var attachments Attachment
+
+	tiktok := t.tiktok
+
+	rtm := t.api.NewRTM()
+	go rtm.ManageConnection()
+
+	for msg := range rtm.IncomingEvents {
+		switch ev := msg.Data.(type) {
+		case *slack.HelloEvent:
+
+		case *slack.ConnectedEvent:
+			tiktok.Config.BotID = ev.Info.User.ID
+			tiktok.Config.BotName = strings.ToUpper(ev.Info.User.Name)
+			tiktok.Config.TeamID = ev.Info.Team.ID
+			tiktok.Config.TeamName = ev.Info.Team.Name
+			SetSlackConnected(true)
+
+			//update this "C7XVAJVRS " to a channel ID that matches what he joined (how do i grab that from the connect)
+			rtm.SendMessage(rtm.NewOutgoingMessage("Hello! I rebooted, if you care.  :unicorn_face:", "C7XVAJVRS"))
+
+		case *slack.MessageEvent:
+			if tiktok.Config.DEBUG {
+				fmt.Printf("Message: %v\n", ev)
+			}
+
+			handle(ev, rtm)
+
+		case *slack.DisconnectedEvent:
+			SetSlackConnected(false)
+
+		case *slack.LatencyReport:
+			if tiktok.Config.DEBUG {
+				fmt.Printf("Current latency: %v\n", ev.Value)
+			}
+
+		case *slack.RTMError:
+			if tiktok.Config.DEBUG {
+				fmt.Printf("Error: %s\n", ev.Error())
+			}
+			if tiktok.Config.LogToSlack {
+				LogToSlack("`ERROR`: RTMError, See Console DEBUG", tiktok, attachments)
+			}
+
+		case *slack.InvalidAuthEvent:
+			if tiktok.Config.DEBUG {
+				fmt.Printf("Invalid credentials")
+			}
+			if tiktok.Config.LogToSlack {
+				LogToSlack("`ERROR`: Invalid Slack API Credentials", tiktok, attachments)
+			}
+			return errors.New("invalid slack API credentials")
+
+		default:
+
+		}
+	}
+
+	SetSlackConnected(false)
+	return errors.New("RTM connection closed")
+}
+
+// whoAmI - fill in the bot and team identity the RTM connected event would have given us
+func whoAmI(tiktok *TikTokConf, api *slack.Client) error {
+	auth, err := api.AuthTest()
+	if err != nil {
+		return err
+	}
+
+	tiktok.Config.BotID = auth.UserID
+	tiktok.Config.BotName = strings.ToUpper(auth.User)
+	tiktok.Config.TeamID = auth.TeamID
+	tiktok.Config.TeamName = auth.Team
+
+	return nil
+}
@@ -1,10 +1,8 @@
 package tiktokmod
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
-	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -157,66 +155,98 @@ type BoardData struct {
 		Pink   string `json:"pink"`
 		Black  string `json:"black"`
 	} `json:"labelNames"`
-	Cards []struct {
-		ID                    string        `json:"id"`
-		CheckItemStates       interface{}   `json:"checkItemStates"`
-		Closed                bool          `json:"closed"`
-		DateLastActivity      time.Time     `json:"dateLastActivity"`
-		Desc                  string        `json:"desc"`
-		DescData              interface{}   `json:"descData"`
-		IDBoard               string        `json:"idBoard"`
-		IDList                string        `json:"idList"`
-		IDMembersVoted        []interface{} `json:"idMembersVoted"`
-		IDShort               int           `json:"idShort"`
-		IDAttachmentCover     interface{}   `json:"idAttachmentCover"`
-		IDLabels              []interface{} `json:"idLabels"`
-		ManualCoverAttachment bool          `json:"manualCoverAttachment"`
-		Name                  string        `json:"name"`
-		Pos                   int           `json:"pos"`
-		ShortLink             string        `json:"shortLink"`
-		Badges                struct {
-			Votes             int `json:"votes"`
-			AttachmentsByType struct {
-				Trello struct {
-					Board int `json:"board"`
-					Card  int `json:"card"`
-				} `json:"trello"`
-			} `json:"attachmentsByType"`
-			ViewingMemberVoted bool        `json:"viewingMemberVoted"`
-			Subscribed         bool        `json:"subscribed"`
-			Fogbugz            string      `json:"fogbugz"`
-			CheckItems         int         `json:"checkItems"`
-			CheckItemsChecked  int         `json:"checkItemsChecked"`
-			Comments           int         `json:"comments"`
-			Attachments        int         `json:"attachments"`
-			Description        bool        `json:"description"`
-			Due                interface{} `json:"due"`
-			DueComplete        bool        `json:"dueComplete"`
-		} `json:"badges"`
-		DueComplete  bool          `json:"dueComplete"`
-		Due          interface{}   `json:"due"`
-		IDChecklists []interface{} `json:"idChecklists"`
-		IDMembers    []string      `json:"idMembers"`
-		Labels       []struct {
-			ID      string `json:"id"`
-			IDBoard string `json:"idBoard"`
-			Name    string `json:"name"`
-			Color   string `json:"color"`
-		} `json:"labels"`
-		ShortURL         string `json:"shortUrl"`
-		Subscribed       bool   `json:"subscribed"`
-		URL              string `json:"url"`
-		CustomFieldItems []struct {
-			ID    string `json:"id"`
-			Value struct {
-				Text   string `json:"text"`
-				Number string `json:"number"`
-			} `json:"value"`
-			IDCustomField string `json:"idCustomField"`
-			IDModel       string `json:"idModel"`
-			ModelType     string `json:"modelType"`
-		} `json:"customFieldItems"`
-	} `json:"cards"`
+	Cards        []BoardCard      `json:"cards"`
+	Labels       Themes           `json:"labels"`
+	Members      []Member         `json:"members"`
+	CustomFields CustomCollection `json:"customFields"`
+	Checklists   []Checklist      `json:"checklists"`
+}
+
+// BoardCard - struct for a single card inside of BoardData
+type BoardCard struct {
+	ID                    string        `json:"id"`
+	CheckItemStates       interface{}   `json:"checkItemStates"`
+	Closed                bool          `json:"closed"`
+	DateLastActivity      time.Time     `json:"dateLastActivity"`
+	Desc                  string        `json:"desc"`
+	DescData              interface{}   `json:"descData"`
+	IDBoard               string        `json:"idBoard"`
+	IDList                string        `json:"idList"`
+	IDMembersVoted        []interface{} `json:"idMembersVoted"`
+	IDShort               int           `json:"idShort"`
+	IDAttachmentCover     interface{}   `json:"idAttachmentCover"`
+	IDLabels              []interface{} `json:"idLabels"`
+	ManualCoverAttachment bool          `json:"manualCoverAttachment"`
+	Name                  string        `json:"name"`
+	Pos                   int           `json:"pos"`
+	ShortLink             string        `json:"shortLink"`
+	Badges                struct {
+		Votes             int `json:"votes"`
+		AttachmentsByType struct {
+			Trello struct {
+				Board int `json:"board"`
+				Card  int `json:"card"`
+			} `json:"trello"`
+		} `json:"attachmentsByType"`
+		ViewingMemberVoted bool        `json:"viewingMemberVoted"`
+		Subscribed         bool        `json:"subscribed"`
+		Fogbugz            string      `json:"fogbugz"`
+		CheckItems         int         `json:"checkItems"`
+		CheckItemsChecked  int         `json:"checkItemsChecked"`
+		Comments           int         `json:"comments"`
+		Attachments        int         `json:"attachments"`
+		Description        bool        `json:"description"`
+		Due                interface{} `json:"due"`
+		DueComplete        bool        `json:"dueComplete"`
+	} `json:"badges"`
+	DueComplete      bool              `json:"dueComplete"`
+	Due              interface{}       `json:"due"`
+	IDChecklists     []interface{}     `json:"idChecklists"`
+	IDMembers        []string          `json:"idMembers"`
+	Labels           []CardLabel       `json:"labels"`
+	ShortURL         string            `json:"shortUrl"`
+	Subscribed       bool              `json:"subscribed"`
+	URL              string            `json:"url"`
+	CustomFieldItems []CustomFieldItem `json:"customFieldItems"`
+	PluginData       PluginCollection  `json:"pluginData"`
+	Checklists       []Checklist       `json:"checklists"`
+	Attachments      []CardAttachment  `json:"attachments"`
+}
+
+// Checklist - a checklist on a card and its items
+type Checklist struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	IDCard     string      `json:"idCard"`
+	CheckItems []CheckItem `json:"checkItems"`
+}
+
+// CheckItem - one item on a checklist, State is "complete" or "incomplete"
+type CheckItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// CardLabel - struct for a label attached to a card
+type CardLabel struct {
+	ID      string `json:"id"`
+	IDBoard string `json:"idBoard"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+}
+
+// CustomFieldItem - struct for a custom field value attached to a card
+type CustomFieldItem struct {
+	ID    string `json:"id"`
+	Value struct {
+		Text   string `json:"text"`
+		Number string `json:"number"`
+	} `json:"value"`
+	IDCustomField string `json:"idCustomField"`
+	IDModel       string `json:"idModel"`
+	ModelType     string `json:"modelType"`
 }
 
 // CardDescHistory - struct to contain description history of any given trello card
@@ -485,26 +515,20 @@ type Themes []Theme
 // AddBoardMember - Add a trello member to a board
 func AddBoardMember(tiktok *TikTokConf, boardID string, memberID string) error {
 	url := "https://api.trello.com/1/boards/" + boardID + "/members/" + memberID + "?type=normal&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+	defer boards.invalidateBoard(boardID)
 
-	req, err := http.NewRequest("PUT", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `AddBoardMember` in `trello.go`", err)
-		return err
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "PUT", url, nil, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `AddBoardMember` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `AddBoardMember` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return err
 }
 
 // CreateList - adlio doesn't have this function so here it is
 func CreateList(boardID string, listName string, tiktok *TikTokConf) error {
 	url := "https://api.trello.com/1/lists/"
+	defer boards.invalidateBoard(boardID)
 	var jsonStr = []byte(`{
 		"name":"` + listName + `",
 		"idBoard":"` + boardID + `",
@@ -512,27 +536,18 @@ func CreateList(boardID string, listName string, tiktok *TikTokConf) error {
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `CreateList` in `trello.go`", err)
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "POST", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `CreateList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `CreateList` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
 	return err
 }
 
 // CreateCard - custom card creation
 func CreateCard(cardName string, listID string, tiktok *TikTokConf) error {
 	url := "https://api.trello.com/1/cards"
+	defer boards.invalidateAll()
 	var jsonStr = []byte(`{
 		"name":"` + cardName + `",
 		"idList":"` + listID + `",
@@ -540,20 +555,11 @@ func CreateCard(cardName string, listID string, tiktok *TikTokConf) error {
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `CreateCard` in `trello.go`", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "POST", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `CreateList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `CreateCard` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
 	return err
 }
 
@@ -581,23 +587,11 @@ func CreateBoard(boardName string, orgName string, tiktok *TikTokConf) (trellrep
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
+	err = trelloDo(tiktok, "POST", url, jsonStr, &trellrep)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `CreateBoard` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `CreateBoard` in `trello.go`", err)
 		return trellrep, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `CreateBoard` in `trello.go`", err)
-		return trellrep, err
-	}
-
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&trellrep)
 
 	return trellrep, err
 
@@ -607,19 +601,11 @@ func CreateBoard(boardName string, orgName string, tiktok *TikTokConf) (trellrep
 func AssignCollection(boardID string, collectionID string, tiktok *TikTokConf) string {
 	url := "https://api.trello.com/1/boards/" + boardID + "/idTags?value=" + collectionID + "&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("POST", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `AssignCollection` in `trello.go`", err)
-		return "Error see logs"
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "POST", url, nil, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `AssignCollection` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `AssignCollection` in `trello.go`", err)
 		return "Error see logs"
 	}
-	defer resp.Body.Close()
-
 	return "Board was assigned to Collection " + collectionID
 }
 
@@ -627,71 +613,181 @@ func AssignCollection(boardID string, collectionID string, tiktok *TikTokConf) s
 func GetPowerUpField(cardID string, tiktok *TikTokConf) (pluginCard PluginCollection, err error) {
 	url := "https://api.trello.com/1/cards/" + cardID + "/plugindata?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
+	err = trelloDo(tiktok, "GET", url, nil, &pluginCard)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetPowerUpField` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetPowerUpField` in `trello.go`", err)
 		return pluginCard, err
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+
+	return pluginCard, err
+}
+
+// PluginPoints - points from the points power-up in a cards plugin data, pointed is false if the power-up isn't on the card
+func PluginPoints(tiktok *TikTokConf, pluginCard PluginCollection) (points int, pointed bool) {
+	for _, p := range pluginCard {
+		if p.IDPlugin == tiktok.Config.PointsPowerUpID {
+			var plugins PointsHistory
+
+			json.Unmarshal([]byte(p.Value), &plugins)
+			points = plugins.Points
+			pointed = true
+		}
+	}
+
+	return points, pointed
+}
+
+// PointsSource values for a team TOML, where a board keeps its story point estimates
+const (
+	PointsPowerUp     = "powerup"
+	PointsCustomField = "customfield"
+	PointsTitle       = "title"
+)
+
+// titlePoints - "(5) Some card" or "Some card (5)"
+var titlePoints = regexp.MustCompile(`\((\d+)\)`)
+
+// FieldPoints - points in the board's CfpointsID (Burndown) custom field, pointed is false if the field is empty
+func FieldPoints(opts Config, card BoardCard) (points int, pointed bool) {
+	for _, c := range card.CustomFieldItems {
+		if c.IDCustomField == opts.General.CfpointsID {
+			value := c.Value.Number
+			if value == "" {
+				value = c.Value.Text
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil {
+				return int(f), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// TitlePoints - points written in brackets in a card title, pointed is false if there aren't any
+func TitlePoints(name string) (points int, pointed bool) {
+	m := titlePoints.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	points, _ = strconv.Atoi(m[1])
+
+	return points, true
+}
+
+// PointsFromField - true when the board's estimates live in the burndown custom field, so we must never overwrite or
+// clear it
+func PointsFromField(opts Config) bool {
+	return strings.ToLower(opts.General.PointsSource) == PointsCustomField
+}
+
+// EstimatedPoints - points on a card from the team's PointsSource (default the points power-up), pointed is false if
+// the card hasn't been estimated
+func EstimatedPoints(tiktok *TikTokConf, opts Config, card BoardCard) (points int, pointed bool) {
+	switch strings.ToLower(opts.General.PointsSource) {
+	case PointsCustomField:
+		return FieldPoints(opts, card)
+	case PointsTitle:
+		return TitlePoints(card.Name)
+	default:
+		return PluginPoints(tiktok, card.PluginData)
+	}
+}
+
+// CardPoints - points on a card from the board RetrieveAll loaded, 0 if it has none
+func CardPoints(tiktok *TikTokConf, opts Config, card BoardCard) (points int) {
+	points, _ = EstimatedPoints(tiktok, opts, card)
+	return points
+}
+
+// CardDue - a card's due date, false if it doesn't have one
+func CardDue(card BoardCard) (due time.Time, ok bool) {
+	d, isString := card.Due.(string)
+	if !isString || d == "" {
+		return due, false
+	}
+
+	due, err := time.Parse(time.RFC3339, d)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetPowerUpField` in `trello.go`", err)
-		return pluginCard, err
+		return due, false
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&pluginCard)
 
-	return pluginCard, err
+	return due, true
+}
+
+// ChecklistProgress - checked and total items over all of a card's checklists, and the names of the ones still open.
+// Falls back to the card badges if its checklists weren't loaded
+func ChecklistProgress(card BoardCard) (done int, total int, open []string) {
+	if len(card.Checklists) == 0 {
+		return card.Badges.CheckItemsChecked, card.Badges.CheckItems, nil
+	}
+
+	for _, cl := range card.Checklists {
+		for _, item := range cl.CheckItems {
+			total++
+			if item.State == "complete" {
+				done++
+			} else {
+				open = append(open, item.Name)
+			}
+		}
+	}
+
+	return done, total, open
+}
+
+// ChecklistNote - " `3/5 checked`" to tack on the end of a card line, blank if the card has no checklists
+func ChecklistNote(card BoardCard) string {
+	done, total, _ := ChecklistProgress(card)
+	if total == 0 {
+		return ""
+	}
+
+	return " `" + strconv.Itoa(done) + "/" + strconv.Itoa(total) + " checked`"
+}
+
+// BoardMember - name, avatar and username of a member from the board RetrieveAll loaded.  Falls back to asking trello
+// for members who have since left the board
+func BoardMember(trello TrelloAPI, board BoardData, memberID string) (fullname string, avatarhash string, userName string) {
+	for _, m := range board.Members {
+		if m.ID == memberID {
+			return m.FullName, m.AvatarHash, m.Username
+		}
+	}
+
+	return trello.GetMemberInfo(memberID)
 }
 
 // adlia/trello doesn't support label removal, so this function does that thing
 func removeLabel(cardID string, labelID string, tiktok *TikTokConf) (err error) {
 	url := "https://api.trello.com/1/cards/" + cardID + "/idLabels/" + labelID
+	defer boards.invalidateCard(cardID)
 
 	var jsonStr = []byte(`{"key": "` + tiktok.Config.Tkey + `", "token": "` + tiktok.Config.Ttoken + `"}`)
-	req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `removeLabel` in `trello.go`", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "DELETE", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `removeLabel` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `removeLabel` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return err
 }
 
 // PutCustomField - Write data to custom card fields power-up
 func PutCustomField(cardID string, customID string, tiktok *TikTokConf, someValueType string, somevalue string) (err error) {
 	url := "https://api.trello.com/1/card/" + cardID + "/customField/" + customID + "/item"
+	defer boards.invalidateCard(cardID)
 
 	var jsonStr = []byte(`{
 		"key": "` + tiktok.Config.Tkey + `", 
 		"token": "` + tiktok.Config.Ttoken + `", 
 		"value": { "` + someValueType + `": "` + somevalue + `" }
 		}`)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `PutCustomField` in `trello.go`", err)
-		return err
-	}
-	req.Header.Set("X-Custom-Header", "")
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "PUT", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `PutCustomField` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `PutCustomField` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return err
 }
 
@@ -699,25 +795,58 @@ func PutCustomField(cardID string, customID string, tiktok *TikTokConf, someValu
 func RemoveHead(tiktok *TikTokConf, cardID string, memberID string) error {
 
 	url := "https://api.trello.com/1/cards/" + cardID + "/idMembers/" + memberID
+	defer boards.invalidateCard(cardID)
 
 	var jsonStr = []byte(`{"key": "` + tiktok.Config.Tkey + `", "token": "` + tiktok.Config.Ttoken + `"}`)
-	req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonStr))
+	err := trelloDo(tiktok, "DELETE", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `RemoveHead` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `RemoveHead` in `trello.go`", err)
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return err
+
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// AddLabel - Put a label on a trello card
+func AddLabel(tiktok *TikTokConf, cardID string, labelID string) error {
+	url := "https://api.trello.com/1/cards/" + cardID + "/idLabels"
+	defer boards.invalidateCard(cardID)
+
+	var jsonStr = []byte(`{"value": "` + labelID + `", "key": "` + tiktok.Config.Tkey + `", "token": "` + tiktok.Config.Ttoken + `"}`)
+	err := trelloDo(tiktok, "POST", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `RemoveHead` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `AddLabel` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	return err
+// AddHead - Add member to trello card
+func AddHead(tiktok *TikTokConf, cardID string, memberID string) error {
+	url := "https://api.trello.com/1/cards/" + cardID + "/idMembers"
+	defer boards.invalidateCard(cardID)
 
+	var jsonStr = []byte(`{"value": "` + memberID + `", "key": "` + tiktok.Config.Tkey + `", "token": "` + tiktok.Config.Ttoken + `"}`)
+	err := trelloDo(tiktok, "POST", url, jsonStr, nil)
+	if err != nil {
+		errTrap(tiktok, "Error calling trello in `AddHead` in `trello.go`", err)
+		return err
+	}
+	return nil
+}
+
+// CloseBoard - Archive (close) a trello board
+func CloseBoard(tiktok *TikTokConf, boardID string) error {
+	url := "https://api.trello.com/1/boards/" + boardID
+	defer boards.invalidateBoard(boardID)
+
+	var jsonStr = []byte(`{"closed": true, "key": "` + tiktok.Config.Tkey + `", "token": "` + tiktok.Config.Ttoken + `"}`)
+	err := trelloDo(tiktok, "PUT", url, jsonStr, nil)
+	if err != nil {
+		errTrap(tiktok, "Error calling trello in `CloseBoard` in `trello.go`", err)
+		return err
+	}
+	return nil
 }
 
 // GetCardListHistory - return list history of a card
@@ -725,19 +854,11 @@ func GetCardListHistory(cardID string, tiktok *TikTokConf) (cardListHistory Card
 
 	url := "https://api.trello.com/1/cards/" + cardID + "/actions?filter=updateCard:idList&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetTimePutList` in `trello.go`", err)
-		return cardListHistory
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "GET", url, nil, &cardListHistory)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetTimePutList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetCardListHistory` in `trello.go`", err)
 		return cardListHistory
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&cardListHistory)
 
 	return cardListHistory
 }
@@ -756,38 +877,6 @@ func SkipColumn(tiktok *TikTokConf, skippedColumn string, cardID string) (skippe
 	return false
 }
 
-// GetTimePutList - Get datetime card was last put in a given list
-func GetTimePutList(listID string, cardID string, opts Config, tiktok *TikTokConf) (found bool, cardListTime time.Time) {
-
-	var cardListHistory CardListHistory
-
-	url := "https://api.trello.com/1/cards/" + cardID + "/actions?filter=updateCard:idList&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetTimePutList` in `trello.go`", err)
-		return false, cardListTime
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetTimePutList` in `trello.go`", err)
-		return false, cardListTime
-	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&cardListHistory)
-
-	for h := range cardListHistory {
-		if cardListHistory[h].Data.ListAfter.ID == listID {
-			cardListTime = cardListHistory[h].Date
-
-			return true, cardListTime
-		}
-	}
-
-	return false, cardListTime
-}
-
 // DupeTrelloBoard - Duplicate an entire trello board and assign it to the Dupe Collection
 func DupeTrelloBoard(boardID string, newName string, trelloOrg string, tiktok *TikTokConf) (output string, err error) {
 
@@ -805,26 +894,10 @@ func DupeTrelloBoard(boardID string, newName string, trelloOrg string, tiktok *T
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `DupeTrelloBoard` in `trello.go`", err)
-		return "Error in http.NewRequest in `DupeTrelloBoard` in `trello.go`", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `DupeTrelloBoard` in `trello.go`", err)
-		return "Error in client.Do in `DupeTrelloBoard` in `trello.go`", err
-	}
-
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&newBoard)
+	err = trelloDo(tiktok, "POST", url, jsonStr, &newBoard)
 	if err != nil {
-		errTrap(tiktok, "Error in json.NewDecoder in `DupeTrelloBoard` in `trello.go`", err)
-		return "Error in json.NewDecoder in `DupeTrelloBoard` in `trello.go`", err
+		errTrap(tiktok, "Error calling trello in `DupeTrelloBoard` in `trello.go`", err)
+		return "Error calling trello in `DupeTrelloBoard` in `trello.go`", err
 	}
 
 	message := "Board duplicated to <" + newBoard.ShortURL + "|" + newName + "> ID#: `" + newBoard.ID + "`\n"
@@ -861,44 +934,62 @@ func RetrieveAll(tiktok *TikTokConf, boardID string, whichCards string) (allTheT
 		whichCards = "all"
 	}
 
-	url := "https://api.trello.com/1/boards/" + boardID + "/?card_customFieldItems=true&cards=" + whichCards + "&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "ERROR in RetrieveAll in `nested.go` *GET*", err)
-		return allTheThings, err
+	ttl := boardCacheTTL(tiktok)
+	if ttl > 0 {
+		if cached, ok := boards.get(boardID, whichCards, ttl); ok {
+			boardCacheLookups.Inc("hit")
+			return cached, nil
+		}
+		boardCacheLookups.Inc("miss")
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// anything we change on the board while this is in flight makes the answer too old to cache
+	gen := boards.generation(boardID)
+
+	// pull plugin data, custom fields, attachments, labels, members and checklists with the cards so nobody has to go back for them card by card
+	url := "https://api.trello.com/1/boards/" + boardID + "/?cards=" + whichCards + "&card_customFieldItems=true&card_pluginData=true&card_attachments=true&card_attachment_fields=name,url,isUpload&customFields=true&labels=all&labels_limit=1000&members=all&member_fields=fullName,username,avatarHash&checklists=all&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+
+	err = trelloDo(tiktok, "GET", url, nil, &allTheThings)
 	if err != nil {
-		errTrap(tiktok, "ERROR in RetrieveAll in `nested.go` client.Do(req)", err)
+		errTrap(tiktok, "Error calling trello in `RetrieveAll` in `trello.go`", err)
 		return allTheThings, err
 	}
-	defer resp.Body.Close()
 
-	json.NewDecoder(resp.Body).Decode(&allTheThings)
+	// checklists come back for the whole board, hang them on their cards the way GetCard returns them
+	for c := range allTheThings.Cards {
+		for _, cl := range allTheThings.Checklists {
+			if cl.IDCard == allTheThings.Cards[c].ID {
+				allTheThings.Cards[c].Checklists = append(allTheThings.Cards[c].Checklists, cl)
+			}
+		}
+	}
+	boards.put(boardID, whichCards, allTheThings, gen)
 
 	return allTheThings, nil
 }
 
+// GetCard - a single card with its custom fields, plugin data, checklists and attachments, the same shape RetrieveAll returns cards in
+func GetCard(tiktok *TikTokConf, cardID string) (card BoardCard, err error) {
+	url := "https://api.trello.com/1/cards/" + cardID + "?customFieldItems=true&pluginData=true&checklists=all&attachments=true&attachment_fields=name,url,isUpload&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+
+	err = trelloDo(tiktok, "GET", url, nil, &card)
+	if err != nil {
+		errTrap(tiktok, "Error calling trello in `GetCard` in `trello.go`", err)
+		return card, err
+	}
+
+	return card, nil
+}
+
 // GetLabel - Get labels on a board
 func GetLabel(tiktok *TikTokConf, boardID string) (allThemes Themes, err error) {
 	url := "https://api.trello.com/1/board/" + boardID + "/labels?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
+	err = trelloDo(tiktok, "GET", url, nil, &allThemes)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetLabel` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetLabel` in `trello.go`", err)
 		return allThemes, err
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetLabel` in `trello.go`", err)
-		return allThemes, err
-	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&allThemes)
 
 	return allThemes, nil
 
@@ -908,19 +999,11 @@ func GetLabel(tiktok *TikTokConf, boardID string) (allThemes Themes, err error)
 func GetDescHistory(tiktok *TikTokConf, cardID string) (descHistory CardDescHistory, err error) {
 	url := "https://trello.com/1/cards/" + cardID + "/actions?filter=updateCard:desc&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetDescHistory` in `trello.go`", err)
-		return descHistory, err
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "GET", url, nil, &descHistory)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetDescHistory` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetDescHistory` in `trello.go`", err)
 		return descHistory, err
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&descHistory)
 
 	return descHistory, err
 }
@@ -930,19 +1013,11 @@ func GetAttachments(tiktok *TikTokConf, cardID string) (cAttach []CardAttachment
 
 	url := "https://trello.com/1/cards/" + cardID + "/attachments?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
+	err = trelloDo(tiktok, "GET", url, nil, &cAttach)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetAttachments` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetAttachments` in `trello.go`", err)
 		return cAttach, err
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetAttachments` in `trello.go`", err)
-		return cAttach, err
-	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&cAttach)
 
 	return cAttach, nil
 }
@@ -960,19 +1035,11 @@ func GetWBoards(tiktok *TikTokConf) (retroArray []RetroStruct, err error) {
 
 	url := "https://api.trello.com/1/organizations/" + tiktok.Config.TrelloOrgID + "/boards?filter=open&fields=id%2Cname&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetWBoards` in `trello.go`", err)
-		return retroArray, err
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "GET", url, nil, &holdID)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetWBoards` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetWBoards` in `trello.go`", err)
 		return retroArray, err
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&holdID)
 
 	for _, h := range holdID {
 		if strings.Contains(h.Name, "{W}") {
@@ -992,19 +1059,11 @@ func GetMemberInfo(headID string, tiktok *TikTokConf) (fullname string, avatarha
 
 	url := "https://api.trello.com/1/member/" + headID + "/?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetMemberInfo` in `trello.go`", err)
-		fmt.Println(err)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "GET", url, nil, &memberData)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetMemberInfo` in `trello.go`", err)
-		fmt.Println(err)
+		errTrap(tiktok, "Error calling trello in `GetMemberInfo` in `trello.go`", err)
+		return "", "", ""
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&memberData)
 
 	return memberData.FullName, memberData.AvatarHash, memberData.Username
 }
@@ -1012,27 +1071,18 @@ func GetMemberInfo(headID string, tiktok *TikTokConf) (fullname string, avatarha
 // CommentCard - add a comment to a card
 func CommentCard(cardID string, comment string, tiktok *TikTokConf) error {
 	url := "https://api.trello.com/1/cards/" + cardID + "/actions/comments"
+	defer boards.invalidateCard(cardID)
 	var jsonStr = []byte(`{
 		"text":"` + comment + `",
 		"key":"` + tiktok.Config.Tkey + `",
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
+	err := trelloDo(tiktok, "POST", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `CommentCard` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `CommentCard` in `trello.go`", err)
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `CommentCard` in `trello.go`", err)
-		return err
-	}
-	defer resp.Body.Close()
-
 	return nil
 }
 
@@ -1041,19 +1091,11 @@ func GetCardComments(cardID string, tiktok *TikTokConf) (cardComments CardCommen
 
 	url := "https://api.trello.com/1/cards/" + cardID + "/actions?filter=commentCard&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
+	err = trelloDo(tiktok, "GET", url, nil, &cardComments)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetCardComments` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetCardComments` in `trello.go`", err)
 		return cardComments, err
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetCardComments` in `trello.go`", err)
-		return cardComments, err
-	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&cardComments)
 
 	return cardComments, nil
 
@@ -1064,21 +1106,11 @@ func GetLists(tiktok *TikTokConf, boardID string) (listData ListData, err error)
 
 	url := "https://api.trello.com/1/boards/" + boardID + "/lists?key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "ERROR in GetListID in `trello.go` *GET*", err)
-		return listData, err
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "GET", url, nil, &listData)
 	if err != nil {
-		errTrap(tiktok, "ERROR in GetListID in `trello.go` client.Do(req)", err)
+		errTrap(tiktok, "Error calling trello in `GetLists` in `trello.go`", err)
 		return listData, err
 	}
-	defer resp.Body.Close()
-
-	json.NewDecoder(resp.Body).Decode(&listData)
 
 	return listData, nil
 }
@@ -1086,54 +1118,49 @@ func GetLists(tiktok *TikTokConf, boardID string) (listData ListData, err error)
 // CardPosition - change card position
 func CardPosition(tiktok *TikTokConf, cardID string, position string) error {
 	url := "https://api.trello.com/1/cards/" + cardID
+	defer boards.invalidateCard(cardID)
 	var jsonStr = []byte(`{
 		"pos":"` + position + `",
 		"key":"` + tiktok.Config.Tkey + `",
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `CardPosition` in `trello.go`", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "PUT", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `CardPosition` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `CardPosition` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return nil
 }
 
 // MoveCardList - Move a card to a different list
 func MoveCardList(tiktok *TikTokConf, cardID string, newList string) error {
 	url := "https://api.trello.com/1/cards/" + cardID
+	defer boards.invalidateCard(cardID)
 	var jsonStr = []byte(`{
 		"idList":"` + newList + `",
 		"key":"` + tiktok.Config.Tkey + `",
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonStr))
+	err := trelloDo(tiktok, "PUT", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `MoveCardList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `MoveCardList` in `trello.go`", err)
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// ArchiveCard - Archive (close) a card
+func ArchiveCard(tiktok *TikTokConf, cardID string) error {
+	url := "https://api.trello.com/1/cards/" + cardID + "?closed=true&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
+	defer boards.invalidateCard(cardID)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "PUT", url, nil, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `MoveCardList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `ArchiveCard` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return nil
 }
 
@@ -1141,27 +1168,18 @@ func MoveCardList(tiktok *TikTokConf, cardID string, newList string) error {
 // newPos == "top", "bottom" or positive float
 func ReOrderCardInList(tiktok *TikTokConf, cardID string, newPos string) error {
 	url := "https://api.trello.com/1/cards/" + cardID
+	defer boards.invalidateCard(cardID)
 	var jsonStr = []byte(`{
 		"pos":"` + newPos + `", 
 		"key":"` + tiktok.Config.Tkey + `",
 		"token":"` + tiktok.Config.Ttoken + `"
 		}`)
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonStr))
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `ReOrderCardInList` in `trello.go`", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err := trelloDo(tiktok, "PUT", url, jsonStr, nil)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `ReOrderCardInList` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `ReOrderCardInList` in `trello.go`", err)
 		return err
 	}
-	defer resp.Body.Close()
-
 	return nil
 }
 
@@ -1169,19 +1187,11 @@ func ReOrderCardInList(tiktok *TikTokConf, cardID string, newPos string) error {
 func GetCardAction(tiktok *TikTokConf, cardID string, limit int) (actions CardAction, err error) {
 	url := "https://trello.com/1/cards/" + cardID + "/actions?filter=all&limit=" + strconv.Itoa(limit) + "&key=" + tiktok.Config.Tkey + "&token=" + tiktok.Config.Ttoken
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errTrap(tiktok, "Error in http.NewRequest in `GetDescHistory` in `trello.go`", err)
-		return actions, err
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	err = trelloDo(tiktok, "GET", url, nil, &actions)
 	if err != nil {
-		errTrap(tiktok, "Error in client.Do in `GetDescHistory` in `trello.go`", err)
+		errTrap(tiktok, "Error calling trello in `GetCardAction` in `trello.go`", err)
 		return actions, err
 	}
-	defer resp.Body.Close()
-	json.NewDecoder(resp.Body).Decode(&actions)
 
 	return actions, err
 }
@@ -0,0 +1,177 @@
+package tiktokmod
+
+import (
+	"time"
+)
+
+// TrelloAPI - every trello call the sprint/alerting/cleanup routines make.  TrelloClient talks to api.trello.com,
+// FakeTrello serves a board fixture from memory so those routines can run without touching a real board
+type TrelloAPI interface {
+	RetrieveAll(boardID string, whichCards string) (BoardData, error)
+	GetCard(cardID string) (BoardCard, error)
+	GetLists(boardID string) (ListData, error)
+	GetLabel(boardID string) (Themes, error)
+	GetPowerUpField(cardID string) (PluginCollection, error)
+	GetCardListHistory(cardID string) CardListHistory
+	GetCardAction(cardID string, limit int) (CardAction, error)
+	GetCardComments(cardID string) (CardComment, error)
+	GetAttachments(cardID string) ([]CardAttachment, error)
+	GetMemberInfo(memberID string) (fullname string, avatarhash string, userName string)
+	MoveCardList(cardID string, newList string) error
+	ReOrderCardInList(cardID string, newPos string) error
+	ArchiveCard(cardID string) error
+	AddLabel(cardID string, labelID string) error
+	RemoveLabel(cardID string, labelID string) error
+	AddHead(cardID string, memberID string) error
+	RemoveHead(cardID string, memberID string) error
+	PutCustomField(cardID string, customID string, someValueType string, somevalue string) error
+	CommentCard(cardID string, comment string) error
+	CreateBoard(boardName string, orgName string) (Boards, error)
+	CloseBoard(boardID string) error
+	CreateList(boardID string, listName string) error
+	AssignCollection(boardID string, collectionID string) string
+	AddBoardMember(boardID string, memberID string) error
+}
+
+// TrelloClient - TrelloAPI implementation against the live trello API
+type TrelloClient struct {
+	tiktok *TikTokConf
+}
+
+// NewTrelloClient - create a live TrelloAPI using the keys in the tiktok config
+func NewTrelloClient(tiktok *TikTokConf) *TrelloClient {
+	return &TrelloClient{tiktok: tiktok}
+}
+
+// RetrieveAll - see RetrieveAll in `trello.go`
+func (t *TrelloClient) RetrieveAll(boardID string, whichCards string) (BoardData, error) {
+	return RetrieveAll(t.tiktok, boardID, whichCards)
+}
+
+// GetCard - see GetCard in `trello.go`
+func (t *TrelloClient) GetCard(cardID string) (BoardCard, error) {
+	return GetCard(t.tiktok, cardID)
+}
+
+// GetLists - see GetLists in `trello.go`
+func (t *TrelloClient) GetLists(boardID string) (ListData, error) {
+	return GetLists(t.tiktok, boardID)
+}
+
+// GetLabel - see GetLabel in `trello.go`
+func (t *TrelloClient) GetLabel(boardID string) (Themes, error) {
+	return GetLabel(t.tiktok, boardID)
+}
+
+// GetPowerUpField - see GetPowerUpField in `trello.go`
+func (t *TrelloClient) GetPowerUpField(cardID string) (PluginCollection, error) {
+	return GetPowerUpField(cardID, t.tiktok)
+}
+
+// GetCardListHistory - see GetCardListHistory in `trello.go`
+func (t *TrelloClient) GetCardListHistory(cardID string) CardListHistory {
+	return GetCardListHistory(cardID, t.tiktok)
+}
+
+// GetCardAction - see GetCardAction in `trello.go`
+func (t *TrelloClient) GetCardAction(cardID string, limit int) (CardAction, error) {
+	return GetCardAction(t.tiktok, cardID, limit)
+}
+
+// GetCardComments - see GetCardComments in `trello.go`
+func (t *TrelloClient) GetCardComments(cardID string) (CardComment, error) {
+	return GetCardComments(cardID, t.tiktok)
+}
+
+// GetAttachments - see GetAttachments in `trello.go`
+func (t *TrelloClient) GetAttachments(cardID string) ([]CardAttachment, error) {
+	return GetAttachments(t.tiktok, cardID)
+}
+
+// GetMemberInfo - see GetMemberInfo in `trello.go`
+func (t *TrelloClient) GetMemberInfo(memberID string) (string, string, string) {
+	return GetMemberInfo(memberID, t.tiktok)
+}
+
+// MoveCardList - see MoveCardList in `trello.go`
+func (t *TrelloClient) MoveCardList(cardID string, newList string) error {
+	return MoveCardList(t.tiktok, cardID, newList)
+}
+
+// ReOrderCardInList - see ReOrderCardInList in `trello.go`
+func (t *TrelloClient) ReOrderCardInList(cardID string, newPos string) error {
+	return ReOrderCardInList(t.tiktok, cardID, newPos)
+}
+
+// ArchiveCard - see ArchiveCard in `trello.go`
+func (t *TrelloClient) ArchiveCard(cardID string) error {
+	return ArchiveCard(t.tiktok, cardID)
+}
+
+// AddLabel - see AddLabel in `trello.go`
+func (t *TrelloClient) AddLabel(cardID string, labelID string) error {
+	return AddLabel(t.tiktok, cardID, labelID)
+}
+
+// RemoveLabel - see removeLabel in `trello.go`
+func (t *TrelloClient) RemoveLabel(cardID string, labelID string) error {
+	return removeLabel(cardID, labelID, t.tiktok)
+}
+
+// AddHead - see AddHead in `trello.go`
+func (t *TrelloClient) AddHead(cardID string, memberID string) error {
+	return AddHead(t.tiktok, cardID, memberID)
+}
+
+// RemoveHead - see RemoveHead in `trello.go`
+func (t *TrelloClient) RemoveHead(cardID string, memberID string) error {
+	return RemoveHead(t.tiktok, cardID, memberID)
+}
+
+// PutCustomField - see PutCustomField in `trello.go`
+func (t *TrelloClient) PutCustomField(cardID string, customID string, someValueType string, somevalue string) error {
+	return PutCustomField(cardID, customID, t.tiktok, someValueType, somevalue)
+}
+
+// CommentCard - see CommentCard in `trello.go`
+func (t *TrelloClient) CommentCard(cardID string, comment string) error {
+	return CommentCard(cardID, comment, t.tiktok)
+}
+
+// CreateBoard - see CreateBoard in `trello.go`
+func (t *TrelloClient) CreateBoard(boardName string, orgName string) (Boards, error) {
+	return CreateBoard(boardName, orgName, t.tiktok)
+}
+
+// CloseBoard - see CloseBoard in `trello.go`
+func (t *TrelloClient) CloseBoard(boardID string) error {
+	return CloseBoard(t.tiktok, boardID)
+}
+
+// CreateList - see CreateList in `trello.go`
+func (t *TrelloClient) CreateList(boardID string, listName string) error {
+	return CreateList(boardID, listName, t.tiktok)
+}
+
+// AssignCollection - see AssignCollection in `trello.go`
+func (t *TrelloClient) AssignCollection(boardID string, collectionID string) string {
+	return AssignCollection(boardID, collectionID, t.tiktok)
+}
+
+// AddBoardMember - see AddBoardMember in `trello.go`
+func (t *TrelloClient) AddBoardMember(boardID string, memberID string) error {
+	return AddBoardMember(t.tiktok, boardID, memberID)
+}
+
+// TimePutList - Get datetime card was last put in a given list
+func TimePutList(trello TrelloAPI, listID string, cardID string) (found bool, cardListTime time.Time) {
+	cardListHistory := trello.GetCardListHistory(cardID)
+
+	for h := range cardListHistory {
+		if cardListHistory[h].Data.ListAfter.ID == listID {
+			return true, cardListHistory[h].Date
+		}
+	}
+
+	return false, cardListTime
+}
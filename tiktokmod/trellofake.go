@@ -0,0 +1,587 @@
+package tiktokmod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeBoardFixture - layout of a board fixture file for FakeTrello.  Everything but boards is optional, maps are keyed by card ID
+type FakeBoardFixture struct {
+	Boards      []BoardData                 `json:"boards"`
+	Lists       ListData                    `json:"lists"`
+	Labels      Themes                      `json:"labels"`
+	PluginData  map[string]PluginCollection `json:"pluginData"`
+	History     map[string]CardListHistory  `json:"history"`
+	Actions     map[string]CardAction       `json:"actions"`
+	Comments    map[string]CardComment      `json:"comments"`
+	Attachments map[string][]CardAttachment `json:"attachments"`
+	Members     map[string]Member           `json:"members"`
+	Checklists  map[string][]Checklist      `json:"checklists"`
+}
+
+// FakeTrello - in-memory TrelloAPI backed by a board fixture.  Writes change the fixture in place and are logged to Calls
+type FakeTrello struct {
+	mu          sync.Mutex
+	idCount     int
+	botUsername string
+	Fixture     FakeBoardFixture
+	Calls       []string
+}
+
+// NewFakeTrello - create a FakeTrello from an already loaded fixture, comments are made as the bots trello user
+func NewFakeTrello(tiktok *TikTokConf, fixture FakeBoardFixture) *FakeTrello {
+	if fixture.PluginData == nil {
+		fixture.PluginData = make(map[string]PluginCollection)
+	}
+	if fixture.History == nil {
+		fixture.History = make(map[string]CardListHistory)
+	}
+	if fixture.Actions == nil {
+		fixture.Actions = make(map[string]CardAction)
+	}
+	if fixture.Comments == nil {
+		fixture.Comments = make(map[string]CardComment)
+	}
+	if fixture.Attachments == nil {
+		fixture.Attachments = make(map[string][]CardAttachment)
+	}
+	if fixture.Members == nil {
+		fixture.Members = make(map[string]Member)
+	}
+
+	return &FakeTrello{botUsername: tiktok.Config.BotTrelloID, Fixture: fixture}
+}
+
+// LoadFakeTrello - create a FakeTrello from a JSON board fixture file
+func LoadFakeTrello(tiktok *TikTokConf, fixtureFile string) (*FakeTrello, error) {
+	var fixture FakeBoardFixture
+
+	f, err := os.Open(fixtureFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&fixture)
+	if err != nil {
+		return nil, errors.New("board fixture " + fixtureFile + " is invalid: " + err.Error())
+	}
+
+	return NewFakeTrello(tiktok, fixture), nil
+}
+
+func (f *FakeTrello) record(call string, args ...string) {
+	f.Calls = append(f.Calls, call+" "+strings.Join(args, " "))
+}
+
+// newID - trello style 24 char hex ID with the creation time up front so GetCreateDate still works
+func (f *FakeTrello) newID() string {
+	f.idCount++
+	return fmt.Sprintf("%08x%016x", time.Now().Unix(), f.idCount)
+}
+
+func (f *FakeTrello) card(cardID string) (*BoardCard, error) {
+	for b := range f.Fixture.Boards {
+		for c := range f.Fixture.Boards[b].Cards {
+			if f.Fixture.Boards[b].Cards[c].ID == cardID {
+				return &f.Fixture.Boards[b].Cards[c], nil
+			}
+		}
+	}
+
+	return nil, errors.New("card " + cardID + " not found in fake trello fixture")
+}
+
+// RetrieveAll - return a copy of the fixture board, cards filtered the same way the trello API does and plugin data,
+// labels, members, checklists and attachments filled in from the rest of the fixture
+func (f *FakeTrello) RetrieveAll(boardID string, whichCards string) (allTheThings BoardData, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, b := range f.Fixture.Boards {
+		if b.ID != boardID {
+			continue
+		}
+
+		allTheThings = b
+		allTheThings.Cards = nil
+		for _, c := range b.Cards {
+			switch whichCards {
+			case "none":
+				continue
+			case "closed":
+				if !c.Closed {
+					continue
+				}
+			case "open", "visible":
+				if c.Closed {
+					continue
+				}
+			}
+			// the live API brings plugin data back with each card
+			if c.PluginData == nil {
+				c.PluginData = f.Fixture.PluginData[c.ID]
+			}
+			if c.Checklists == nil {
+				c.Checklists = f.Fixture.Checklists[c.ID]
+				allTheThings.Checklists = append(allTheThings.Checklists, c.Checklists...)
+			}
+			if c.Attachments == nil {
+				c.Attachments = f.Fixture.Attachments[c.ID]
+			}
+			allTheThings.Cards = append(allTheThings.Cards, c)
+		}
+
+		allTheThings.Labels = nil
+		for _, l := range f.Fixture.Labels {
+			if l.IDBoard == boardID {
+				allTheThings.Labels = append(allTheThings.Labels, l)
+			}
+		}
+		if allTheThings.Members == nil {
+			for id, m := range f.Fixture.Members {
+				m.ID = id
+				allTheThings.Members = append(allTheThings.Members, m)
+			}
+		}
+
+		return allTheThings, nil
+	}
+
+	return allTheThings, errors.New("board " + boardID + " not found in fake trello fixture")
+}
+
+// GetCard - a copy of a fixture card with its plugin data, checklists and attachments filled in
+func (f *FakeTrello) GetCard(cardID string) (BoardCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.card(cardID)
+	if err != nil {
+		return BoardCard{}, err
+	}
+
+	card := *c
+	if card.PluginData == nil {
+		card.PluginData = f.Fixture.PluginData[cardID]
+	}
+	if card.Checklists == nil {
+		card.Checklists = f.Fixture.Checklists[cardID]
+	}
+	if card.Attachments == nil {
+		card.Attachments = f.Fixture.Attachments[cardID]
+	}
+
+	return card, nil
+}
+
+// GetLists - lists in the fixture for a board
+func (f *FakeTrello) GetLists(boardID string) (listData ListData, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, l := range f.Fixture.Lists {
+		if l.IDBoard == boardID {
+			listData = append(listData, l)
+		}
+	}
+
+	return listData, nil
+}
+
+// GetLabel - labels in the fixture for a board
+func (f *FakeTrello) GetLabel(boardID string) (allThemes Themes, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, l := range f.Fixture.Labels {
+		if l.IDBoard == boardID {
+			allThemes = append(allThemes, l)
+		}
+	}
+
+	return allThemes, nil
+}
+
+// GetPowerUpField - plugin data in the fixture for a card
+func (f *FakeTrello) GetPowerUpField(cardID string) (PluginCollection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.Fixture.PluginData[cardID], nil
+}
+
+// GetCardListHistory - list moves in the fixture for a card, newest first
+func (f *FakeTrello) GetCardListHistory(cardID string) CardListHistory {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.Fixture.History[cardID]
+}
+
+// GetCardAction - actions in the fixture for a card, newest first
+func (f *FakeTrello) GetCardAction(cardID string, limit int) (CardAction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	actions := f.Fixture.Actions[cardID]
+	if limit > 0 && len(actions) > limit {
+		actions = actions[:limit]
+	}
+
+	return actions, nil
+}
+
+// GetCardComments - comments in the fixture for a card, newest first
+func (f *FakeTrello) GetCardComments(cardID string) (CardComment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.Fixture.Comments[cardID], nil
+}
+
+// GetAttachments - attachments in the fixture for a card
+func (f *FakeTrello) GetAttachments(cardID string) ([]CardAttachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.Fixture.Attachments[cardID], nil
+}
+
+// GetMemberInfo - member in the fixture
+func (f *FakeTrello) GetMemberInfo(memberID string) (fullname string, avatarhash string, userName string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m := f.Fixture.Members[memberID]
+
+	return m.FullName, m.AvatarHash, m.Username
+}
+
+// MoveCardList - move a card and add the move to its list history
+func (f *FakeTrello) MoveCardList(cardID string, newList string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("MoveCardList", cardID, newList)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	h := make(CardListHistory, 1)
+	h[0].ID = f.newID()
+	h[0].Type = "updateCard"
+	h[0].Date = time.Now()
+	h[0].Data.ListBefore.ID = c.IDList
+	h[0].Data.ListAfter.ID = newList
+	h[0].Data.Card.ID = cardID
+	h[0].Data.Card.Name = c.Name
+	h[0].Data.Card.IDList = newList
+	h[0].Data.Old.IDList = c.IDList
+	h[0].MemberCreator.Username = f.botUsername
+	f.Fixture.History[cardID] = append(h, f.Fixture.History[cardID]...)
+
+	c.IDList = newList
+
+	return nil
+}
+
+// ReOrderCardInList - newPos == "top", "bottom" or a position number
+func (f *FakeTrello) ReOrderCardInList(cardID string, newPos string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("ReOrderCardInList", cardID, newPos)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	top := c.Pos
+	bottom := c.Pos
+	for _, b := range f.Fixture.Boards {
+		for _, o := range b.Cards {
+			if o.IDList == c.IDList {
+				if o.Pos < top {
+					top = o.Pos
+				}
+				if o.Pos > bottom {
+					bottom = o.Pos
+				}
+			}
+		}
+	}
+
+	switch newPos {
+	case "top":
+		c.Pos = top - 1
+	case "bottom":
+		c.Pos = bottom + 1
+	default:
+		pos, err := strconv.ParseFloat(newPos, 64)
+		if err != nil {
+			return errors.New("invalid card position " + newPos)
+		}
+		c.Pos = int(pos)
+	}
+
+	return nil
+}
+
+// ArchiveCard - mark a card closed
+func (f *FakeTrello) ArchiveCard(cardID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("ArchiveCard", cardID)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+	c.Closed = true
+
+	return nil
+}
+
+// AddLabel - put one of the fixture's labels on a card
+func (f *FakeTrello) AddLabel(cardID string, labelID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("AddLabel", cardID, labelID)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range c.Labels {
+		if l.ID == labelID {
+			return nil
+		}
+	}
+
+	label := CardLabel{ID: labelID, IDBoard: c.IDBoard}
+	for _, t := range f.Fixture.Labels {
+		if t.ID == labelID {
+			label.Name = t.Name
+			label.Color = t.Color
+		}
+	}
+	c.Labels = append(c.Labels, label)
+	c.IDLabels = append(c.IDLabels, labelID)
+
+	return nil
+}
+
+// RemoveLabel - take a label off a card
+func (f *FakeTrello) RemoveLabel(cardID string, labelID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("RemoveLabel", cardID, labelID)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	var labels []CardLabel
+	for _, l := range c.Labels {
+		if l.ID != labelID {
+			labels = append(labels, l)
+		}
+	}
+	c.Labels = labels
+
+	var idLabels []interface{}
+	for _, l := range c.IDLabels {
+		if l != labelID {
+			idLabels = append(idLabels, l)
+		}
+	}
+	c.IDLabels = idLabels
+
+	return nil
+}
+
+// AddHead - put a member on a card
+func (f *FakeTrello) AddHead(cardID string, memberID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("AddHead", cardID, memberID)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range c.IDMembers {
+		if m == memberID {
+			return nil
+		}
+	}
+	c.IDMembers = append(c.IDMembers, memberID)
+
+	return nil
+}
+
+// RemoveHead - take a member off a card
+func (f *FakeTrello) RemoveHead(cardID string, memberID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("RemoveHead", cardID, memberID)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	var members []string
+	for _, m := range c.IDMembers {
+		if m != memberID {
+			members = append(members, m)
+		}
+	}
+	c.IDMembers = members
+
+	return nil
+}
+
+// PutCustomField - set a custom field on a card, a blank value clears it like trello does
+func (f *FakeTrello) PutCustomField(cardID string, customID string, someValueType string, somevalue string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("PutCustomField", cardID, customID, someValueType, somevalue)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	var items []CustomFieldItem
+	for _, i := range c.CustomFieldItems {
+		if i.IDCustomField != customID {
+			items = append(items, i)
+		}
+	}
+
+	if strings.TrimSpace(somevalue) != "" {
+		var item CustomFieldItem
+		item.ID = f.newID()
+		item.IDCustomField = customID
+		item.IDModel = cardID
+		item.ModelType = "card"
+		if someValueType == "number" {
+			item.Value.Number = somevalue
+		} else {
+			item.Value.Text = somevalue
+		}
+		items = append(items, item)
+	}
+	c.CustomFieldItems = items
+
+	return nil
+}
+
+// CommentCard - add a comment to a card as the bot user
+func (f *FakeTrello) CommentCard(cardID string, comment string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("CommentCard", cardID, comment)
+	c, err := f.card(cardID)
+	if err != nil {
+		return err
+	}
+
+	cc := make(CardComment, 1)
+	cc[0].ID = f.newID()
+	cc[0].Type = "commentCard"
+	cc[0].Date = time.Now()
+	cc[0].Data.Text = comment
+	cc[0].Data.Card.ID = cardID
+	cc[0].Data.Card.Name = c.Name
+	cc[0].MemberCreator.Username = f.botUsername
+	f.Fixture.Comments[cardID] = append(cc, f.Fixture.Comments[cardID]...)
+	c.Badges.Comments++
+
+	return nil
+}
+
+// CreateBoard - add an empty board to the fixture
+func (f *FakeTrello) CreateBoard(boardName string, orgName string) (trellrep Boards, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("CreateBoard", boardName, orgName)
+
+	var b BoardData
+	b.ID = f.newID()
+	b.Name = boardName
+	b.IDOrganization = orgName
+	f.Fixture.Boards = append(f.Fixture.Boards, b)
+
+	trellrep.ID = b.ID
+	trellrep.Name = b.Name
+	trellrep.IDOrganization = b.IDOrganization
+
+	return trellrep, nil
+}
+
+// CloseBoard - mark a fixture board closed
+func (f *FakeTrello) CloseBoard(boardID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("CloseBoard", boardID)
+	for b := range f.Fixture.Boards {
+		if f.Fixture.Boards[b].ID == boardID {
+			f.Fixture.Boards[b].Closed = true
+			return nil
+		}
+	}
+
+	return errors.New("board " + boardID + " not found in fake trello fixture")
+}
+
+// CreateList - add a list to a fixture board
+func (f *FakeTrello) CreateList(boardID string, listName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("CreateList", boardID, listName)
+
+	l := make(ListData, 1)
+	l[0].ID = f.newID()
+	l[0].Name = listName
+	l[0].IDBoard = boardID
+	f.Fixture.Lists = append(f.Fixture.Lists, l...)
+
+	return nil
+}
+
+// AssignCollection - only recorded, collections aren't part of the fixture
+func (f *FakeTrello) AssignCollection(boardID string, collectionID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("AssignCollection", boardID, collectionID)
+
+	return "Board was assigned to Collection " + collectionID
+}
+
+// AddBoardMember - only recorded, board membership isn't part of the fixture
+func (f *FakeTrello) AddBoardMember(boardID string, memberID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record("AddBoardMember", boardID, memberID)
+
+	return nil
+}
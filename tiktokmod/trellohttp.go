@@ -0,0 +1,174 @@
+package tiktokmod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// trelloTimeout - longest a single trello request may take, board pulls with every card can be slow
+	trelloTimeout = 60 * time.Second
+	// trelloRetries - attempts after the first for 429s, 5xx's and network errors
+	trelloRetries = 4
+)
+
+// ErrTrelloAuth - trello rejected our key/token (401 or 403)
+var ErrTrelloAuth = errors.New("trello rejected the API key or token")
+
+// ErrTrelloNotFound - the board, card or list doesn't exist or we can't see it (404)
+var ErrTrelloNotFound = errors.New("trello object not found")
+
+// ErrTrelloRateLimited - trello kept answering 429 after every retry
+var ErrTrelloRateLimited = errors.New("trello rate limit exceeded")
+
+// TrelloError - a non 2xx answer from trello.  errors.Is matches it against ErrTrelloAuth, ErrTrelloNotFound and
+// ErrTrelloRateLimited
+type TrelloError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *TrelloError) Error() string {
+	return "trello " + e.Method + " " + e.Path + " returned " + strconv.Itoa(e.StatusCode) + ": " + e.Body
+}
+
+// Is - let callers check the kind of failure without caring about the exact status
+func (e *TrelloError) Is(target error) bool {
+	switch target {
+	case ErrTrelloAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrTrelloNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrTrelloRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// trelloHTTP - the one http client every trello call goes through
+var trelloHTTP = &http.Client{Timeout: trelloTimeout}
+
+// trelloThrottle - when trello says we've used up the current rate limit window, every caller waits it out
+type trelloThrottle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+var throttle trelloThrottle
+
+func (t *trelloThrottle) wait() {
+	t.mu.Lock()
+	until := t.until
+	t.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+func (t *trelloThrottle) pause(d time.Duration) {
+	t.mu.Lock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+	t.mu.Unlock()
+}
+
+// observe - read trello's rate limit headers and hold off the next call if the token or key window is spent
+func (t *trelloThrottle) observe(h http.Header) {
+	for _, scope := range []string{"token", "key"} {
+		remaining, err := strconv.Atoi(h.Get("X-Rate-Limit-Api-" + scope + "-Remaining"))
+		if err != nil || remaining > 1 {
+			continue
+		}
+		interval, err := strconv.Atoi(h.Get("X-Rate-Limit-Api-" + scope + "-Interval-Ms"))
+		if err != nil {
+			interval = 10000
+		}
+		t.pause(time.Duration(interval) * time.Millisecond)
+	}
+}
+
+// retryDelay - backoff before the next attempt, Retry-After wins when trello sends one
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+
+	backoff := time.Duration(1<<uint(attempt)) * time.Second
+	return backoff + time.Duration(rand.Int63n(int64(backoff/2)))
+}
+
+// trelloDo - make a trello call, retrying rate limits and server errors, and decode the answer into out (if not nil).
+// Anything but a 2xx comes back as a *TrelloError
+func trelloDo(tiktok *TikTokConf, method string, url string, body []byte, out interface{}) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= trelloRetries; attempt++ {
+		throttle.wait()
+
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := trelloHTTP.Do(req)
+		if err != nil {
+			// a POST that timed out may have landed, don't risk doing it twice
+			if method == http.MethodPost {
+				return err
+			}
+			lastErr = err
+			time.Sleep(retryDelay(nil, attempt))
+			continue
+		}
+
+		throttle.observe(resp.Header)
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			return json.NewDecoder(resp.Body).Decode(out)
+		}
+
+		// just enough of the body to say what went wrong
+		snippet, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		lastErr = &TrelloError{Method: method, Path: req.URL.Path, StatusCode: resp.StatusCode, Body: string(snippet)}
+
+		retry := resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && method != http.MethodPost)
+		if !retry || attempt == trelloRetries {
+			break
+		}
+
+		if tiktok.Config.DEBUG {
+			fmt.Println("Trello " + method + " " + req.URL.Path + " returned " + strconv.Itoa(resp.StatusCode) + ", retrying")
+		}
+		delay := retryDelay(resp, attempt)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			throttle.pause(delay)
+		} else {
+			time.Sleep(delay)
+		}
+	}
+
+	return lastErr
+}
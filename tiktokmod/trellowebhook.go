@@ -0,0 +1,445 @@
+package tiktokmod
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBadTrelloSignature - a webhook callback that wasn't signed with our trello secret
+var ErrBadTrelloSignature = errors.New("trello webhook signature does not match")
+
+// ErrTrelloQueueFull - the card checks are too far behind to take another webhook event, it was dropped
+var ErrTrelloQueueFull = errors.New("trello webhook queue is full")
+
+// Kinds of CardEvent
+const (
+	CardMoved   = "moved"
+	CardLabels  = "labels"
+	CardMembers = "members"
+)
+
+// CardEvent - a change someone made to a card on one of our boards, from a trello webhook callback
+type CardEvent struct {
+	ActionID   string
+	Kind       string
+	BoardID    string
+	CardID     string
+	CardName   string
+	ListBefore string
+	ListAfter  string
+	Subject    string
+	Added      bool
+	By         string
+}
+
+// trelloCallback - the parts of a trello webhook callback we use
+type trelloCallback struct {
+	Action struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Board struct {
+				ID string `json:"id"`
+			} `json:"board"`
+			Card struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"card"`
+			ListBefore struct {
+				ID string `json:"id"`
+			} `json:"listBefore"`
+			ListAfter struct {
+				ID string `json:"id"`
+			} `json:"listAfter"`
+			Label struct {
+				ID string `json:"id"`
+			} `json:"label"`
+			IDMember string `json:"idMember"`
+		} `json:"data"`
+		MemberCreator struct {
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"action"`
+}
+
+// ParseTrelloCallback - turn a webhook callback into a CardEvent.  False for actions we don't react to
+func ParseTrelloCallback(body []byte) (ev CardEvent, ok bool, err error) {
+	var cb trelloCallback
+
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return ev, false, err
+	}
+
+	a := cb.Action
+	ev = CardEvent{
+		ActionID: a.ID,
+		BoardID:  a.Data.Board.ID,
+		CardID:   a.Data.Card.ID,
+		CardName: a.Data.Card.Name,
+		By:       a.MemberCreator.Username,
+	}
+
+	switch a.Type {
+	case "updateCard":
+		// updateCard covers every field, only list moves have a before and after list
+		if a.Data.ListBefore.ID == "" || a.Data.ListAfter.ID == "" {
+			return ev, false, nil
+		}
+		ev.Kind = CardMoved
+		ev.ListBefore = a.Data.ListBefore.ID
+		ev.ListAfter = a.Data.ListAfter.ID
+	case "addLabelToCard", "removeLabelFromCard":
+		ev.Kind = CardLabels
+		ev.Subject = a.Data.Label.ID
+		ev.Added = a.Type == "addLabelToCard"
+	case "addMemberToCard", "removeMemberFromCard":
+		ev.Kind = CardMembers
+		ev.Subject = a.Data.IDMember
+		ev.Added = a.Type == "addMemberToCard"
+	default:
+		return ev, false, nil
+	}
+
+	return ev, ev.CardID != "", nil
+}
+
+// TrelloWebhookSignature - the X-Trello-Webhook header trello sends for a callback body to callbackURL
+func TrelloWebhookSignature(secret string, body []byte, callbackURL string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	mac.Write([]byte(callbackURL))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTrelloCallback - check a webhook callback was signed by trello with our secret
+func VerifyTrelloCallback(secret string, callbackURL string, header http.Header, body []byte) error {
+	if !hmac.Equal([]byte(header.Get("X-Trello-Webhook")), []byte(TrelloWebhookSignature(secret, body, callbackURL))) {
+		return ErrBadTrelloSignature
+	}
+	return nil
+}
+
+// TrelloWebhooks - receives trello callbacks for every team board and runs the card checks on each change as it happens
+type TrelloWebhooks struct {
+	tiktok   *TikTokConf
+	mu       sync.Mutex
+	teams    map[string][]string
+	seen     map[string]time.Time
+	incoming chan CardEvent
+}
+
+// NewTrelloWebhooks - webhook receiver for the boards in teams (board ID to team TOML names)
+func NewTrelloWebhooks(tiktok *TikTokConf, teams map[string][]string) *TrelloWebhooks {
+	return &TrelloWebhooks{tiktok: tiktok, teams: teams, seen: make(map[string]time.Time), incoming: make(chan CardEvent, 100)}
+}
+
+// ServeHTTP - answer trello's HEAD check, verify and queue callbacks.  A callback that doesn't fit in the queue gets a
+// 503 and is forgotten, so trello's retry of it is queued
+func (w *TrelloWebhooks) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	// trello checks the callback URL answers before it will create the webhook
+	if r.Method == http.MethodHead || r.Method == http.MethodGet {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, r.Body, 1<<20))
+	if err != nil {
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := VerifyTrelloCallback(w.tiktok.Config.TrelloSecret, w.tiktok.Config.TrelloWebhookURL, r.Header, body); err != nil {
+		if w.tiktok.Config.DEBUG {
+			fmt.Println("Rejected trello webhook callback: " + err.Error())
+		}
+		http.Error(rw, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ev, ok, err := ParseTrelloCallback(body)
+	if err != nil {
+		errTrap(w.tiktok, "Unable to decode trello webhook callback in `ServeHTTP` in `trellowebhook.go`", err)
+	}
+	if !ok {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// someone changed the board, whatever we have cached for it is out of date
+	boards.invalidateBoard(ev.BoardID)
+
+	// ignore the changes we make ourselves so we don't react to our own fixes
+	if ev.By == w.tiktok.Config.BotTrelloID {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.mu.Lock()
+	if _, dupe := w.seen[ev.ActionID]; dupe {
+		w.mu.Unlock()
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	for id, at := range w.seen {
+		if time.Since(at) > time.Hour {
+			delete(w.seen, id)
+		}
+	}
+	w.seen[ev.ActionID] = time.Now()
+	w.mu.Unlock()
+
+	select {
+	case w.incoming <- ev:
+		rw.WriteHeader(http.StatusOK)
+	default:
+		w.mu.Lock()
+		delete(w.seen, ev.ActionID)
+		w.mu.Unlock()
+
+		errTrap(w.tiktok, "Dropped trello webhook `"+ev.Kind+"` on card "+ev.CardID+" for trello to retry in `ServeHTTP` in `trellowebhook.go`", ErrTrelloQueueFull)
+		http.Error(rw, ErrTrelloQueueFull.Error(), http.StatusServiceUnavailable)
+	}
+}
+
+// Serve - react to queued events one at a time, forever
+func (w *TrelloWebhooks) Serve() {
+	for ev := range w.incoming {
+		trelloHookEvents.Inc(ev.Kind)
+
+		w.mu.Lock()
+		teams := w.teams[ev.BoardID]
+		w.mu.Unlock()
+
+		for _, team := range teams {
+			opts, err := LoadConf(w.tiktok, team)
+			if err != nil {
+				continue
+			}
+			ReactToCardEvent(w.tiktok, w.tiktok.Trello, opts, team, ev)
+		}
+	}
+}
+
+// ReactToCardEvent - run the cron card checks that apply to this change on just the changed card
+func ReactToCardEvent(tiktok *TikTokConf, trello TrelloAPI, opts Config, team string, ev CardEvent) {
+	var attachments Attachment
+
+	aTt, err := trello.GetCard(ev.CardID)
+	if err != nil || aTt.Closed || CardHushed(opts, aTt) {
+		return
+	}
+
+	if tiktok.Config.LogToSlack && tiktok.Config.DEBUG {
+		LogToSlack("Trello webhook `"+ev.Kind+"` by "+ev.By+" on <"+aTt.ShortURL+"|"+aTt.Name+"> in the `"+opts.General.TeamName+"` board", tiktok, attachments)
+	}
+
+	inSprint := aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.Working || aTt.IDList == opts.General.ReadyForReview
+
+	if ev.Kind == CardMoved && ev.ListAfter == opts.General.Done {
+		users, err := tiktok.DB.GetUsers()
+		if err != nil {
+			errTrap(tiktok, "Error getting user data from `GetDBUsers` in `ReactToCardEvent` in `trellowebhook.go`", err)
+			return
+		}
+		board := BoardData{ID: ev.BoardID}
+
+		// skipped review - moved into Done from anywhere but Ready for Review
+		if ev.ListBefore != opts.General.ReadyForReview {
+			line, _ := CheckSkippedReview(tiktok, trello, opts, users, board, aTt)
+			if line != "" {
+				attachments.Color = "#ff0000"
+				attachments.Text = line
+				TeamWrangler(tiktok, opts, SkippedReviewHeader, opts.General.ComplaintChannel, attachments)
+			}
+		}
+
+		// unfinished checklists - moved into Done with items still open
+		line, _ := CheckDoneChecklist(tiktok, trello, opts, users, board, aTt)
+		if line != "" {
+			attachments.Color = "#ff0000"
+			attachments.Text = line
+			TeamWrangler(tiktok, opts, DoneChecklistHeader, opts.General.ComplaintChannel, attachments)
+		}
+
+		// blockers - let the owners of cards this one was blocking know straight away
+		if all, err := trello.RetrieveAll(ev.BoardID, "visible"); err == nil {
+			NotifyUnblocked(tiktok, trello, opts, all, BoardDependencies(tiktok, trello, opts, all))
+		}
+	}
+
+	// owners - nobody on a card before it's being worked, somebody on it once it is
+	if aTt.IDList == opts.General.ReadyForWork || aTt.IDList == opts.General.NextsprintID {
+		if (ev.Kind == CardMembers && ev.Added) || ev.Kind == CardMoved || ev.Kind == CardLabels {
+			if RemoveHeads(tiktok, trello, aTt) {
+				attachments.Color = "#ff0000"
+				attachments.Text = "This card should not be assigned yet!\n<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
+				TeamWrangler(tiktok, opts, "<!here> NOTICE!  I have *removed* people from this card", opts.General.ComplaintChannel, attachments)
+			}
+		}
+	}
+	if aTt.IDList == opts.General.Working && len(aTt.IDMembers) == 0 {
+		if (ev.Kind == CardMembers && !ev.Added) || (ev.Kind == CardMoved && ev.ListAfter == opts.General.Working) {
+			attachments.Color = "#ff0000"
+			attachments.Text = "I'm sad! This card is in the working column but has nobody assigned to it!\n<" + aTt.ShortURL + "|" + aTt.Name + ">\n"
+			TeamWrangler(tiktok, opts, "<!here> Warning Un-Assigned Work!!", opts.General.ComplaintChannel, attachments)
+		}
+	}
+
+	// points - keep the burndown field in step and flag changes on cards already in the sprint
+	if ev.Kind == CardMoved && inSprint {
+		sOpts, err := tiktok.DB.GetSprint(team)
+		if err != nil {
+			errTrap(tiktok, "DB error in `GetSprint` in `ReactToCardEvent` in `trellowebhook.go`", err)
+			return
+		}
+		if apMessage := SyncCardPoints(tiktok, trello, opts, sOpts, aTt); apMessage != "" {
+			attachments.Color = "#ff0000"
+			attachments.Text = apMessage
+			TeamWrangler(tiktok, opts, "<!here> Points have been changed on these cards that are in the *current sprint*.", opts.General.ComplaintChannel, attachments)
+		}
+	}
+}
+
+// trelloWebhook - one webhook registered against our token
+type trelloWebhook struct {
+	ID          string `json:"id"`
+	IDModel     string `json:"idModel"`
+	CallbackURL string `json:"callbackURL"`
+	Active      bool   `json:"active"`
+}
+
+// WebhookTeams - board ID to team TOML names, what the receiver routes callbacks with
+func WebhookTeams(tiktok *TikTokConf) map[string][]string {
+	teams := make(map[string][]string)
+	for _, team := range TeamIDs(tiktok) {
+		opts, err := LoadConf(tiktok, team)
+		if err != nil || opts.General.BoardID == "" {
+			continue
+		}
+		teams[opts.General.BoardID] = append(teams[opts.General.BoardID], team)
+	}
+
+	return teams
+}
+
+// RegisterTrelloWebhooks - make sure every board in teams has a webhook pointing at TrelloWebhookURL.  Boards that
+// fail are logged and skipped, the error says how many
+func RegisterTrelloWebhooks(tiktok *TikTokConf, teams map[string][]string) error {
+	var existing []trelloWebhook
+
+	err := trelloDo(tiktok, "GET", "https://api.trello.com/1/tokens/"+tiktok.Config.Ttoken+"/webhooks?key="+tiktok.Config.Tkey+"&token="+tiktok.Config.Ttoken, nil, &existing)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for boardID, names := range teams {
+		registered := false
+		for _, h := range existing {
+			if h.IDModel == boardID && h.CallbackURL == tiktok.Config.TrelloWebhookURL && h.Active {
+				registered = true
+			}
+		}
+		if registered {
+			continue
+		}
+
+		q := url.Values{}
+		q.Set("callbackURL", tiktok.Config.TrelloWebhookURL)
+		q.Set("idModel", boardID)
+		q.Set("description", tiktok.Config.BotName+" "+names[0])
+		q.Set("key", tiktok.Config.Tkey)
+		q.Set("token", tiktok.Config.Ttoken)
+
+		err := trelloDo(tiktok, "POST", "https://api.trello.com/1/webhooks?"+q.Encode(), nil, nil)
+		if err != nil {
+			errTrap(tiktok, "Error registering trello webhook for board `"+names[0]+"` in `RegisterTrelloWebhooks` in `trellowebhook.go`", err)
+			failed++
+			continue
+		}
+		if tiktok.Config.DEBUG {
+			fmt.Println("Registered trello webhook for board " + boardID)
+		}
+	}
+
+	if failed > 0 {
+		return errors.New(strconv.Itoa(failed) + " of " + strconv.Itoa(len(teams)) + " boards could not be registered")
+	}
+
+	return nil
+}
+
+// StartTrelloWebhooks - listen on TrelloWebhookListen and register webhooks for every board.  Does nothing unless
+// TrelloWebhookURL is set in tiktok.toml
+func StartTrelloWebhooks(tiktok *TikTokConf) {
+	var attachments Attachment
+
+	if tiktok.Config.TrelloWebhookURL == "" {
+		return
+	}
+	if tiktok.Config.TrelloSecret == "" {
+		fmt.Println("TrelloWebhookURL is set but no trello secret was given with -tsecret, not starting trello webhooks")
+		if tiktok.Config.LogToSlack {
+			LogToSlack("TrelloWebhookURL is set but no trello secret was given with `-tsecret`, not starting trello webhooks", tiktok, attachments)
+		}
+		return
+	}
+
+	callback, err := url.Parse(tiktok.Config.TrelloWebhookURL)
+	if err != nil {
+		errTrap(tiktok, "Invalid TrelloWebhookURL in `StartTrelloWebhooks` in `trellowebhook.go`", err)
+		return
+	}
+
+	listen := tiktok.Config.TrelloWebhookListen
+	if listen == "" {
+		listen = ":3001"
+	}
+
+	// listen before registering, trello checks the URL answers while it creates the webhook
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		errTrap(tiktok, "Unable to listen for trello webhooks on "+listen+" in `StartTrelloWebhooks` in `trellowebhook.go`", err)
+		return
+	}
+
+	// route callbacks from the start, only the trello side of registering is left to the background
+	teams := WebhookTeams(tiktok)
+	hooks := NewTrelloWebhooks(tiktok, teams)
+	path := callback.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, hooks)
+
+	go func() {
+		err := http.Serve(ln, mux)
+		errTrap(tiktok, "Trello webhook server on "+listen+" stopped in `StartTrelloWebhooks` in `trellowebhook.go`", err)
+	}()
+	go hooks.Serve()
+
+	go func() {
+		if err := RegisterTrelloWebhooks(tiktok, teams); err != nil {
+			errTrap(tiktok, "Unable to register trello webhooks in `StartTrelloWebhooks` in `trellowebhook.go`, boards without one only get the cron checks", err)
+		}
+	}()
+
+	fmt.Println("Listening for trello webhooks on " + listen + path)
+}
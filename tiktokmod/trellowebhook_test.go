@@ -0,0 +1,160 @@
+package tiktokmod
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testTrelloSecret = "b3f1c0ffee5ecre7b3f1c0ffee5ecre7"
+const testTrelloCallback = "https://tiktok.example.com/trello"
+
+// trelloCallbackRequest - a webhook callback for body signed as trello would with secret for callbackURL
+func trelloCallbackRequest(secret string, callbackURL string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/trello", strings.NewReader(body))
+	req.Header.Set("X-Trello-Webhook", TrelloWebhookSignature(secret, []byte(body), callbackURL))
+
+	return req
+}
+
+// labelAction - an addLabelToCard callback for rollCard
+func labelAction(actionID string) string {
+	return `{"action":{"id":"` + actionID + `","type":"addLabelToCard","data":{"board":{"id":"5c8a0e1f2b3c4d5e6f708091"},"card":{"id":"` + rollCard + `","name":"Roll me over"},"label":{"id":"5c8a0e1f2b3c4d5e6f70b001"}},"memberCreator":{"username":"exampleperson"}}}`
+}
+
+// a full queue mustn't take down the handler, the callback gets a 503 so trello retries it
+func TestTrelloWebhooksQueueFull(t *testing.T) {
+	tt := newTestTeam(t)
+	tt.tiktok.Config.LogToSlack = true
+	tt.tiktok.Config.TrelloSecret = testTrelloSecret
+	tt.tiktok.Config.TrelloWebhookURL = testTrelloCallback
+	hooks := NewTrelloWebhooks(tt.tiktok, nil)
+
+	for i := 0; i < cap(hooks.incoming); i++ {
+		rec := httptest.NewRecorder()
+		hooks.ServeHTTP(rec, trelloCallbackRequest(testTrelloSecret, testTrelloCallback, labelAction("action"+strconv.Itoa(i))))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("callback %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	hooks.ServeHTTP(rec, trelloCallbackRequest(testTrelloSecret, testTrelloCallback, labelAction("full")))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+
+	// trello's retry once there's room is queued, not taken for a duplicate
+	<-hooks.incoming
+	rec = httptest.NewRecorder()
+	hooks.ServeHTTP(rec, trelloCallbackRequest(testTrelloSecret, testTrelloCallback, labelAction("full")))
+	if rec.Code != http.StatusOK || len(hooks.incoming) != cap(hooks.incoming) {
+		t.Errorf("retry: status = %d with %d queued, want 200 and a full queue", rec.Code, len(hooks.incoming))
+	}
+}
+
+func TestVerifyTrelloCallback(t *testing.T) {
+	body := labelAction("action1")
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		wantErr error
+	}{
+		{name: "good signature", req: trelloCallbackRequest(testTrelloSecret, testTrelloCallback, body)},
+		{name: "wrong secret", req: trelloCallbackRequest("not-the-secret", testTrelloCallback, body), wantErr: ErrBadTrelloSignature},
+		{name: "wrong callback URL", req: trelloCallbackRequest(testTrelloSecret, "https://elsewhere.example.com/trello", body), wantErr: ErrBadTrelloSignature},
+		{name: "no signature", req: httptest.NewRequest(http.MethodPost, "/trello", strings.NewReader(body)), wantErr: ErrBadTrelloSignature},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := VerifyTrelloCallback(testTrelloSecret, testTrelloCallback, tc.req.Header, []byte(body)); err != tc.wantErr {
+				t.Errorf("VerifyTrelloCallback() = %v, want %v", err, tc.wantErr)
+			}
+
+			// the receiver turns a bad signature away before looking at the body
+			tiktok := &TikTokConf{}
+			tiktok.Config.TrelloSecret = testTrelloSecret
+			tiktok.Config.TrelloWebhookURL = testTrelloCallback
+			hooks := NewTrelloWebhooks(tiktok, nil)
+			rec := httptest.NewRecorder()
+			hooks.ServeHTTP(rec, tc.req)
+			wantStatus, wantQueued := http.StatusOK, 1
+			if tc.wantErr != nil {
+				wantStatus, wantQueued = http.StatusUnauthorized, 0
+			}
+			if rec.Code != wantStatus || len(hooks.incoming) != wantQueued {
+				t.Errorf("status = %d with %d queued, want %d with %d queued", rec.Code, len(hooks.incoming), wantStatus, wantQueued)
+			}
+		})
+	}
+}
+
+func TestParseTrelloCallback(t *testing.T) {
+	card := `"board":{"id":"board1"},"card":{"id":"card1","name":"A card"}`
+	action := func(kind string, data string) string {
+		return `{"action":{"id":"action1","type":"` + kind + `","data":{` + card + data + `},"memberCreator":{"username":"exampleperson"}}}`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantOK  bool
+		wantErr bool
+		want    CardEvent
+	}{
+		{
+			name:   "card moved",
+			body:   action("updateCard", `,"listBefore":{"id":"list1"},"listAfter":{"id":"list2"}`),
+			wantOK: true,
+			want:   CardEvent{ActionID: "action1", Kind: CardMoved, BoardID: "board1", CardID: "card1", CardName: "A card", ListBefore: "list1", ListAfter: "list2", By: "exampleperson"},
+		},
+		{name: "updateCard without listBefore", body: action("updateCard", `,"listAfter":{"id":"list2"}`)},
+		{name: "updateCard to another field", body: action("updateCard", `,"old":{"name":"Old name"}`)},
+		{
+			name:   "label added",
+			body:   action("addLabelToCard", `,"label":{"id":"label1"}`),
+			wantOK: true,
+			want:   CardEvent{ActionID: "action1", Kind: CardLabels, BoardID: "board1", CardID: "card1", CardName: "A card", Subject: "label1", Added: true, By: "exampleperson"},
+		},
+		{
+			name:   "label removed",
+			body:   action("removeLabelFromCard", `,"label":{"id":"label1"}`),
+			wantOK: true,
+			want:   CardEvent{ActionID: "action1", Kind: CardLabels, BoardID: "board1", CardID: "card1", CardName: "A card", Subject: "label1", By: "exampleperson"},
+		},
+		{
+			name:   "member added",
+			body:   action("addMemberToCard", `,"idMember":"member1"`),
+			wantOK: true,
+			want:   CardEvent{ActionID: "action1", Kind: CardMembers, BoardID: "board1", CardID: "card1", CardName: "A card", Subject: "member1", Added: true, By: "exampleperson"},
+		},
+		{
+			name:   "member removed",
+			body:   action("removeMemberFromCard", `,"idMember":"member1"`),
+			wantOK: true,
+			want:   CardEvent{ActionID: "action1", Kind: CardMembers, BoardID: "board1", CardID: "card1", CardName: "A card", Subject: "member1", By: "exampleperson"},
+		},
+		{name: "comment", body: action("commentCard", `,"text":"hi"`)},
+		{name: "board action without a card", body: `{"action":{"id":"action1","type":"addLabelToCard","data":{"board":{"id":"board1"},"label":{"id":"label1"}}}}`},
+		{name: "not json", body: `{"action":`, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ev, ok, err := ParseTrelloCallback([]byte(tc.body))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseTrelloCallback() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if ok != tc.wantOK {
+				t.Fatalf("ParseTrelloCallback() ok = %v, want %v", ok, tc.wantOK)
+			}
+			if ok && ev != tc.want {
+				t.Errorf("ParseTrelloCallback() = %+v, want %+v", ev, tc.want)
+			}
+		})
+	}
+}